@@ -0,0 +1,310 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/ical"
+	"github.com/danmurf/time-tracker/internal/pkg/timeexpr"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxAbsenceDays is the most days which can be added or removed at once, to catch mistyped dates.
+const maxAbsenceDays = 366
+
+var (
+	// absenceHalf and absenceNote are given with --half and --note.
+	absenceHalf bool
+	absenceNote string
+	// absenceImportKind is the kind of absence given with --kind when importing.
+	absenceImportKind string
+)
+
+// absenceCmd represents the absence command
+var absenceCmd = &cobra.Command{
+	Use:   "absence",
+	Short: "Manage vacation, sick days and public holidays",
+	Long: `Add the days you weren't working, so that goals, overtime and timesheets don't count them as missing hours.
+An absence is one of vacation, sick or holiday, for a whole day or half a day. For example:
+
+time-tracker absence add vacation 2026-08-03 2026-08-14
+time-tracker absence add sick today --half
+time-tracker absence import holidays.ics
+time-tracker absence list
+
+There is at most one absence on each day, so adding one replaces any other on the same day. Absences are recorded as
+events, so they are synced along with the time tracked.`,
+}
+
+var absenceAddCmd = &cobra.Command{
+	Use:   "add <kind> <date> [<last-date>]",
+	Short: "Add an absence on a day, or on each day of a range",
+	Long: `Add an absence of a kind (vacation, sick or holiday) on a date, or on every day from the date to the last date.
+Dates may be written as 2026-08-03, today, tomorrow, friday and so on. For example:
+
+time-tracker absence add vacation 2026-08-03 2026-08-14
+time-tracker absence add holiday 2026-12-24 --half --note "Christmas Eve"`,
+	Args: usageArgs(cobra.RangeArgs(2, 3)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dates, err := parseDateRange(args[1:])
+		if err != nil {
+			return err
+		}
+		var absences []app.Absence
+		for _, date := range dates {
+			absences = append(absences, app.Absence{Date: date, Kind: app.AbsenceKind(args[0]), Half: absenceHalf, Note: absenceNote})
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := addAbsences(cmd, eventStorage, absences); err != nil {
+			return err
+		}
+		return output(cmd, newAbsenceListView(absences, "added"))
+	},
+}
+
+var absenceRemoveCmd = &cobra.Command{
+	Use:   "remove <date> [<last-date>]",
+	Short: "Remove the absence on a day, or on each day of a range",
+	Args:  usageArgs(cobra.RangeArgs(1, 2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dates, err := parseDateRange(args)
+		if err != nil {
+			return err
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		absences := newAbsences(eventStorage)
+		existing, err := absences.Absences(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("listing absences: %w", err)
+		}
+		var removed []app.Absence
+		var removedDates []time.Time
+		for _, date := range dates {
+			if absence, ok := existing.On(date); ok {
+				removed = append(removed, absence)
+				removedDates = append(removedDates, date)
+			}
+		}
+		if err := absences.RemoveAbsences(cmd.Context(), removedDates...); err != nil {
+			return fmt.Errorf("removing absences: %w", err)
+		}
+
+		return output(cmd, newAbsenceListView(removed, "removed"))
+	},
+}
+
+var absenceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every absence",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		absences, err := newAbsences(eventStorage).Absences(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("listing absences: %w", err)
+		}
+
+		return output(cmd, newAbsenceListView(absences, ""))
+	},
+}
+
+var absenceImportCmd = &cobra.Command{
+	Use:   "import <file.ics>",
+	Short: "Add the days of each event in an iCalendar file as absences",
+	Long: `Add the days of each event in an iCalendar (.ics) file as absences, noting the name of each event. This is
+meant for the lists of public holidays which are published for most countries and regions. For example:
+
+time-tracker absence import holidays.ics
+time-tracker absence import company-shutdown.ics --kind vacation`,
+	Args: usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("opening calendar: %w", err)
+		}
+		defer f.Close()
+		events, err := ical.ReadEvents(f)
+		if err != nil {
+			return fmt.Errorf("%s isn't a calendar that can be read: %s: %w", args[0], err, errInvalidUsage)
+		}
+		var absences []app.Absence
+		for _, event := range events {
+			for _, day := range event.Days() {
+				absences = append(absences, app.Absence{Date: day, Kind: app.AbsenceKind(absenceImportKind), Note: event.Summary})
+			}
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := addAbsences(cmd, eventStorage, absences); err != nil {
+			return err
+		}
+		return output(cmd, newAbsenceListView(absences, "imported"))
+	},
+}
+
+// parseDateRange resolves the dates of a date and an optional last date, which must not be before it.
+func parseDateRange(args []string) ([]time.Time, error) {
+	firstDay, err := settings.FirstWeekday()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err, errInvalidConfig)
+	}
+	now := time.Now().In(location)
+	var bounds []time.Time
+	for _, arg := range args {
+		t, err := timeexpr.Parse(arg, now, firstDay)
+		if err != nil {
+			return nil, fmt.Errorf("date [%s]: %s: %w", arg, err, errInvalidUsage)
+		}
+		bounds = append(bounds, app.Date(t))
+	}
+
+	first, last := bounds[0], bounds[len(bounds)-1]
+	switch {
+	case last.Before(first):
+		return nil, fmt.Errorf("last date [%s] must not be before [%s]: %w", args[1], args[0], errInvalidUsage)
+	case last.Sub(first) >= maxAbsenceDays*24*time.Hour:
+		return nil, fmt.Errorf("at most %d days can be given at once: %w", maxAbsenceDays, errInvalidUsage)
+	}
+	var dates []time.Time
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
+
+// addAbsences adds the absences, turning invalid ones into a usage error.
+func addAbsences(cmd *cobra.Command, eventStorage eventStorage, absences []app.Absence) error {
+	err := newAbsences(eventStorage).AddAbsences(cmd.Context(), absences...)
+	switch {
+	case errors.Is(err, app.ErrInvalidAbsence):
+		return fmt.Errorf("%s: %w", err, errInvalidUsage)
+	case err != nil:
+		return fmt.Errorf("adding absences: %w", err)
+	}
+	return nil
+}
+
+// newAbsences returns the absences kept in the event store.
+func newAbsences(eventStorage eventStorage) tasks.Absences {
+	return tasks.NewAbsences(eventStorage, eventStorage)
+}
+
+// absenceView is a day, or half a day, which wasn't worked.
+type absenceView struct {
+	Date string `json:"date" yaml:"date"`
+	Kind string `json:"kind" yaml:"kind"`
+	Half bool   `json:"half" yaml:"half"`
+	Note string `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+func newAbsenceView(a app.Absence) absenceView {
+	return absenceView{Date: a.Date.Format("2006-01-02"), Kind: string(a.Kind), Half: a.Half, Note: a.Note}
+}
+
+// label describes the absence, e.g. "vacation (half day)".
+func (v absenceView) label() string {
+	if v.Half {
+		return v.Kind + " (half day)"
+	}
+	return v.Kind
+}
+
+// absenceListView is the output of adding, removing or listing absences.
+type absenceListView struct {
+	Absences []absenceView `json:"absences" yaml:"absences"`
+	// verb is what was done with the absences, or empty if they were listed.
+	verb string
+}
+
+func newAbsenceListView(absences []app.Absence, verb string) absenceListView {
+	v := absenceListView{Absences: []absenceView{}, verb: verb}
+	for _, a := range absences {
+		v.Absences = append(v.Absences, newAbsenceView(a))
+	}
+	return v
+}
+
+func (v absenceListView) Text() string {
+	if len(v.Absences) == 0 {
+		if v.verb != "" {
+			return "📭 no absences were " + v.verb + "."
+		}
+		return "📭 no absences have been added. Run `time-tracker absence add vacation <date>` to add one."
+	}
+	var rows [][]string
+	for _, a := range v.Absences {
+		rows = append(rows, []string{a.Date, a.label(), a.Note})
+	}
+	table := formatTable([]string{"Date", "Absence", "Note"}, rows)
+	if v.verb == "" {
+		return table
+	}
+	noun := "absences"
+	if len(v.Absences) == 1 {
+		noun = "absence"
+	}
+	return fmt.Sprintf("🌴 %s %d %s:\n\n%s", v.verb, len(v.Absences), noun, table)
+}
+
+func (v absenceListView) Header() []string {
+	return []string{"date", "kind", "half", "note"}
+}
+
+func (v absenceListView) Rows() [][]string {
+	var rows [][]string
+	for _, a := range v.Absences {
+		rows = append(rows, []string{a.Date, a.Kind, strconv.FormatBool(a.Half), a.Note})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(absenceCmd)
+	absenceCmd.AddCommand(absenceAddCmd, absenceRemoveCmd, absenceListCmd, absenceImportCmd)
+	absenceAddCmd.Flags().BoolVar(&absenceHalf, "half", false, "the absence is for half of each day")
+	absenceAddCmd.Flags().StringVar(&absenceNote, "note", "", "what the absence is for")
+	absenceImportCmd.Flags().StringVar(&absenceImportKind, "kind", string(app.Holiday), "the kind of absence: vacation, sick or holiday")
+}
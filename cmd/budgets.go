@@ -0,0 +1,151 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/spf13/cobra"
+	"strconv"
+	"time"
+)
+
+// budgetsCmd represents the budgets command
+var budgetsCmd = &cobra.Command{
+	Use:   "budgets",
+	Short: "Compare the time spent on tasks with their estimates and budgets",
+	Long: `List every estimate and budget set with time-tracker estimate, with the time spent so far, including any task
+in progress, and the time left. Overrun budgets are marked.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		statuses, err := newBudgets(eventStorage).Statuses(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("checking budgets: %w", err)
+		}
+
+		v := budgetListView{Budgets: []budgetView{}}
+		for _, s := range statuses {
+			v.Budgets = append(v.Budgets, newBudgetView(s))
+		}
+		return output(cmd, v)
+	},
+}
+
+// budgetView is the time spent against an estimate for a task or a budget for a task name prefix.
+type budgetView struct {
+	Task             string  `json:"task" yaml:"task"`
+	Kind             string  `json:"kind" yaml:"kind"`
+	BudgetSeconds    float64 `json:"budget_seconds" yaml:"budget_seconds"`
+	SpentSeconds     float64 `json:"spent_seconds" yaml:"spent_seconds"`
+	RemainingSeconds float64 `json:"remaining_seconds" yaml:"remaining_seconds"`
+	Sessions         int     `json:"sessions" yaml:"sessions"`
+	Over             bool    `json:"over" yaml:"over"`
+	budget           time.Duration
+	spent            time.Duration
+	remaining        time.Duration
+	text             string
+}
+
+func newBudgetView(s app.BudgetStatus) budgetView {
+	return budgetView{
+		Task:             s.Budget.Task,
+		Kind:             budgetKind(s.Budget),
+		BudgetSeconds:    s.Budget.Duration.Seconds(),
+		SpentSeconds:     s.Spent.Seconds(),
+		RemainingSeconds: s.Remaining().Seconds(),
+		Sessions:         s.Sessions,
+		Over:             s.Over(),
+		budget:           s.Budget.Duration,
+		spent:            s.Spent,
+		remaining:        s.Remaining(),
+	}
+}
+
+func (v budgetView) Text() string {
+	if v.text != "" {
+		return v.text
+	}
+	if v.Over {
+		return fmt.Sprintf("%s over the %s %s for %s.", formatDuration(-v.remaining), formatDuration(v.budget), v.Kind, v.Task)
+	}
+	return fmt.Sprintf("%s left of the %s %s for %s.", formatDuration(v.remaining), formatDuration(v.budget), v.Kind, v.Task)
+}
+
+func (v budgetView) Header() []string {
+	return []string{"task", "kind", "budget_seconds", "budget", "spent_seconds", "spent", "remaining_seconds", "remaining", "sessions", "over"}
+}
+
+func (v budgetView) Rows() [][]string {
+	return [][]string{{
+		v.Task,
+		v.Kind,
+		formatSeconds(v.BudgetSeconds),
+		formatDuration(v.budget),
+		formatSeconds(v.SpentSeconds),
+		formatDuration(v.spent),
+		formatSeconds(v.RemainingSeconds),
+		formatDuration(v.remaining),
+		strconv.Itoa(v.Sessions),
+		strconv.FormatBool(v.Over),
+	}}
+}
+
+// budgetListView is the output of comparing every budget with the time spent.
+type budgetListView struct {
+	Budgets []budgetView `json:"budgets" yaml:"budgets"`
+}
+
+func (v budgetListView) Text() string {
+	if len(v.Budgets) == 0 {
+		return "📭 no budgets have been set. Run `time-tracker estimate <task> <duration>` to set one."
+	}
+	var rows [][]string
+	for _, b := range v.Budgets {
+		used := fmt.Sprintf("%.0f%%", 100*b.SpentSeconds/b.BudgetSeconds)
+		if b.Over {
+			used += " ⚠️  over"
+		}
+		rows = append(rows, []string{b.Task, b.Kind, formatDuration(b.budget), formatDuration(b.spent), formatDuration(b.remaining), used})
+	}
+	return formatTable([]string{"For", "Kind", "Budget", "Spent", "Remaining", "Used"}, rows)
+}
+
+func (v budgetListView) Header() []string {
+	return budgetView{}.Header()
+}
+
+func (v budgetListView) Rows() [][]string {
+	var rows [][]string
+	for _, b := range v.Budgets {
+		rows = append(rows, b.Rows()...)
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(budgetsCmd)
+}
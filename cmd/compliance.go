@@ -0,0 +1,228 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"strings"
+	"time"
+)
+
+// complianceCmd represents the compliance command
+var complianceCmd = &cobra.Command{
+	Use:   "compliance",
+	Short: "Check the time worked in a day, week or month against working-time rules",
+	Long: `Check each day worked against working-time rules, such as those of the EU Working Time Directive, listing the
+rules broken on each day. Any task in progress counts. For example:
+
+time-tracker compliance --week       # this week
+time-tracker compliance --month -1   # last month
+
+By default, a 30 minute break is needed after 6 hours of work, at most 10 hours may be worked in a day and there must
+be at least 11 hours of rest between days. Set the rules in the config file, where a limit of 0 isn't checked:
+
+compliance:
+  breaks: [{after: 6h, minimum: 30m}, {after: 9h, minimum: 45m}]
+  max_daily: 10h
+  min_rest: 11h
+
+Breaks are the time between sessions, and sessions count towards the day they started on.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		period, err := reportPeriod(cmd, time.Now().In(location))
+		if err != nil {
+			return err
+		}
+		rules, err := settings.WorkingTimeRules()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		checker := tasks.NewComplianceChecker(tasks.NewSessionCollector(eventStorage), rules)
+		compliance, err := checker.Compliance(cmd.Context(), period)
+		if err != nil {
+			return fmt.Errorf("checking compliance: %w", err)
+		}
+
+		return output(cmd, newComplianceView(compliance))
+	},
+}
+
+// complianceView is the output of checking the days worked against the working-time rules.
+type complianceView struct {
+	From       time.Time     `json:"from" yaml:"from"`
+	To         time.Time     `json:"to" yaml:"to"`
+	Days       []workDayView `json:"days" yaml:"days"`
+	Violations int           `json:"violations" yaml:"violations"`
+}
+
+type workDayView struct {
+	Date          string          `json:"date" yaml:"date"`
+	Started       time.Time       `json:"started" yaml:"started"`
+	Finished      time.Time       `json:"finished" yaml:"finished"`
+	WorkedSeconds float64         `json:"worked_seconds" yaml:"worked_seconds"`
+	BreakSeconds  float64         `json:"break_seconds" yaml:"break_seconds"`
+	RestSeconds   *float64        `json:"rest_seconds,omitempty" yaml:"rest_seconds,omitempty"`
+	Violations    []violationView `json:"violations" yaml:"violations"`
+	worked        time.Duration
+	breaks        time.Duration
+	rest          time.Duration
+}
+
+type violationView struct {
+	Rule          string  `json:"rule" yaml:"rule"`
+	ActualSeconds float64 `json:"actual_seconds" yaml:"actual_seconds"`
+	LimitSeconds  float64 `json:"limit_seconds" yaml:"limit_seconds"`
+	Description   string  `json:"description" yaml:"description"`
+}
+
+func newComplianceView(c app.Compliance) complianceView {
+	v := complianceView{
+		From:       c.Period.From.In(location),
+		To:         c.Period.To.In(location),
+		Days:       []workDayView{},
+		Violations: c.Violations(),
+	}
+	for _, d := range c.Days {
+		day := workDayView{
+			Date:          formatDate(d.Day.From),
+			Started:       d.Started.In(location),
+			Finished:      d.Finished.In(location),
+			WorkedSeconds: d.Worked.Seconds(),
+			BreakSeconds:  d.Breaks.Seconds(),
+			Violations:    []violationView{},
+			worked:        d.Worked,
+			breaks:        d.Breaks,
+			rest:          d.Rest,
+		}
+		if d.Rest > 0 {
+			rest := d.Rest.Seconds()
+			day.RestSeconds = &rest
+		}
+		for _, violation := range d.Violations {
+			day.Violations = append(day.Violations, violationView{
+				Rule:          string(violation.Kind),
+				ActualSeconds: violation.Actual.Seconds(),
+				LimitSeconds:  violation.Limit.Seconds(),
+				Description:   describeViolation(violation),
+			})
+		}
+		v.Days = append(v.Days, day)
+	}
+	return v
+}
+
+// describeViolation describes a broken working-time rule, e.g. "00:20:00 of breaks, 00:30:00 needed after 06:00:00".
+func describeViolation(v app.Violation) string {
+	switch v.Kind {
+	case app.ViolationBreak:
+		return fmt.Sprintf("%s of breaks, %s needed after %s", formatDuration(v.Actual), formatDuration(v.Limit), formatDuration(v.After))
+	case app.ViolationMaxDaily:
+		return fmt.Sprintf("%s worked, %s allowed", formatDuration(v.Actual), formatDuration(v.Limit))
+	case app.ViolationRest:
+		return fmt.Sprintf("%s of rest, %s needed", formatDuration(v.Actual), formatDuration(v.Limit))
+	default:
+		return string(v.Kind)
+	}
+}
+
+// descriptions joins the descriptions of the day's violations.
+func (v workDayView) descriptions() string {
+	var descriptions []string
+	for _, violation := range v.Violations {
+		descriptions = append(descriptions, violation.Description)
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// formatRest formats the rest before the day, which is empty if it wasn't measured.
+func (v workDayView) formatRest() string {
+	if v.RestSeconds == nil {
+		return ""
+	}
+	return formatDuration(v.rest)
+}
+
+func (v complianceView) Text() string {
+	period := formatPeriod(v.From, v.To)
+	if len(v.Days) == 0 {
+		return fmt.Sprintf("📭 no work to check in %s.", period)
+	}
+
+	var rows [][]string
+	for _, d := range v.Days {
+		violations := "✅"
+		if len(d.Violations) > 0 {
+			violations = "⚠️  " + d.descriptions()
+		}
+		rows = append(rows, []string{d.Date, d.Started.Format("15:04"), d.Finished.Format("15:04"), formatDuration(d.worked), formatDuration(d.breaks), d.formatRest(), violations})
+	}
+	summary := fmt.Sprintf("✅ no working-time rules were broken in %s.", period)
+	if v.Violations > 0 {
+		noun := "rules were"
+		if v.Violations == 1 {
+			noun = "rule was"
+		}
+		summary = fmt.Sprintf("⚠️  %d working-time %s broken in %s.", v.Violations, noun, period)
+	}
+	return fmt.Sprintf("%s\n%s", formatTable([]string{"Day", "Start", "Finish", "Worked", "Breaks", "Rest", "Violations"}, rows), summary)
+}
+
+func (v complianceView) Header() []string {
+	return []string{"date", "started", "finished", "worked_seconds", "worked", "break_seconds", "breaks", "rest_seconds", "rest", "violations"}
+}
+
+func (v complianceView) Rows() [][]string {
+	var rows [][]string
+	for _, d := range v.Days {
+		rest := ""
+		if d.RestSeconds != nil {
+			rest = formatSeconds(*d.RestSeconds)
+		}
+		rows = append(rows, []string{
+			d.Date,
+			formatTime(d.Started),
+			formatTime(d.Finished),
+			formatSeconds(d.WorkedSeconds),
+			formatDuration(d.worked),
+			formatSeconds(d.BreakSeconds),
+			formatDuration(d.breaks),
+			rest,
+			d.formatRest(),
+			d.descriptions(),
+		})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(complianceCmd)
+	addPeriodFlags(complianceCmd)
+}
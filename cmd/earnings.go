@@ -0,0 +1,173 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"strconv"
+	"time"
+)
+
+// earningsCmd represents the earnings command
+var earningsCmd = &cobra.Command{
+	Use:   "earnings",
+	Short: "Work out what the time spent on tasks in a day, week or month is worth",
+	Long: `Multiply the time spent in each completed session by the hourly rate in effect when it started, with a subtotal
+for each currency. Time on tasks without a rate is shown as unrated. For example:
+
+time-tracker earnings --month       # this month
+time-tracker earnings --month -1    # last month
+time-tracker earnings --from 2026-10-01 --to 2026-10-15
+
+Set rates with time-tracker rate set. Earnings are worked out from the exact durations, not rounded ones.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		period, err := reportPeriod(cmd, time.Now().In(location))
+		if err != nil {
+			return err
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		calculator := tasks.NewEarningsCalculator(tasks.NewSessionCollector(eventStorage), tasks.NewRates(eventStorage, eventStorage))
+		earnings, err := calculator.Earnings(cmd.Context(), period)
+		if err != nil {
+			return fmt.Errorf("calculating earnings: %w", err)
+		}
+
+		return output(cmd, newEarningsView(earnings))
+	},
+}
+
+// earningsView is the output of working out what the time in a period is worth.
+type earningsView struct {
+	From           time.Time           `json:"from" yaml:"from"`
+	To             time.Time           `json:"to" yaml:"to"`
+	Lines          []earningsLineView  `json:"lines" yaml:"lines"`
+	Currencies     []currencyTotalView `json:"currencies" yaml:"currencies"`
+	UnratedSeconds float64             `json:"unrated_seconds" yaml:"unrated_seconds"`
+	unrated        time.Duration
+}
+
+type earningsLineView struct {
+	Task            string    `json:"task" yaml:"task"`
+	Rate            *rateView `json:"rate,omitempty" yaml:"rate,omitempty"`
+	Sessions        int       `json:"sessions" yaml:"sessions"`
+	DurationSeconds float64   `json:"duration_seconds" yaml:"duration_seconds"`
+	Amount          float64   `json:"amount" yaml:"amount"`
+	duration        time.Duration
+}
+
+type currencyTotalView struct {
+	Currency        string  `json:"currency" yaml:"currency"`
+	DurationSeconds float64 `json:"duration_seconds" yaml:"duration_seconds"`
+	Amount          float64 `json:"amount" yaml:"amount"`
+	duration        time.Duration
+}
+
+func newEarningsView(e app.Earnings) earningsView {
+	v := earningsView{
+		From:           e.Period.From.In(location),
+		To:             e.Period.To.In(location),
+		Lines:          []earningsLineView{},
+		Currencies:     []currencyTotalView{},
+		UnratedSeconds: e.Unrated.Seconds(),
+		unrated:        e.Unrated,
+	}
+	for _, l := range e.Lines {
+		line := earningsLineView{
+			Task:            l.TaskName,
+			Sessions:        l.Sessions,
+			DurationSeconds: l.Duration.Seconds(),
+			Amount:          l.Amount,
+			duration:        l.Duration,
+		}
+		if l.Rate.Currency != "" {
+			rate := newRateView(l.Rate)
+			line.Rate = &rate
+		}
+		v.Lines = append(v.Lines, line)
+	}
+	for _, c := range e.Currencies {
+		v.Currencies = append(v.Currencies, currencyTotalView{
+			Currency:        c.Currency,
+			DurationSeconds: c.Duration.Seconds(),
+			Amount:          c.Amount,
+			duration:        c.Duration,
+		})
+	}
+	return v
+}
+
+func (v earningsView) Text() string {
+	period := formatPeriod(v.From, v.To)
+	if len(v.Lines) == 0 {
+		return fmt.Sprintf("📭 no completed sessions %s.", period)
+	}
+
+	var rows [][]string
+	for _, l := range v.Lines {
+		rate, amount := "unrated", "-"
+		if l.Rate != nil {
+			rate, amount = l.Rate.amount(), formatAmount(l.Amount, l.Rate.Currency)
+		}
+		rows = append(rows, []string{l.Task, rate, strconv.Itoa(l.Sessions), formatDuration(l.duration), amount})
+	}
+	for _, c := range v.Currencies {
+		rows = append(rows, []string{"Total " + c.Currency, "", "", formatDuration(c.duration), formatAmount(c.Amount, c.Currency)})
+	}
+	if v.unrated > 0 {
+		rows = append(rows, []string{"Unrated", "", "", formatDuration(v.unrated), "-"})
+	}
+
+	return fmt.Sprintf("💰 %s\n\n%s", period, formatTable([]string{"Task", "Rate", "Sessions", "Duration", "Amount"}, rows))
+}
+
+func (v earningsView) Header() []string {
+	return []string{"row", "task", "rate_task", "rate_tag", "rate", "currency", "sessions", "duration_seconds", "duration", "amount"}
+}
+
+func (v earningsView) Rows() [][]string {
+	var rows [][]string
+	for _, l := range v.Lines {
+		row := []string{"line", l.Task, "", "", "", "", strconv.Itoa(l.Sessions), formatSeconds(l.DurationSeconds), formatDuration(l.duration), ""}
+		if l.Rate != nil {
+			row[2], row[3], row[4], row[5], row[9] = l.Rate.Task, l.Rate.Tag, formatMoney(l.Rate.Amount), l.Rate.Currency, formatMoney(l.Amount)
+		}
+		rows = append(rows, row)
+	}
+	for _, c := range v.Currencies {
+		rows = append(rows, []string{"total", "", "", "", "", c.Currency, "", formatSeconds(c.DurationSeconds), formatDuration(c.duration), formatMoney(c.Amount)})
+	}
+	return append(rows, []string{"unrated", "", "", "", "", "", "", formatSeconds(v.UnratedSeconds), formatDuration(v.unrated), ""})
+}
+
+func init() {
+	rootCmd.AddCommand(earningsCmd)
+	addPeriodFlags(earningsCmd)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// estimateCmd represents the estimate command
+var estimateCmd = &cobra.Command{
+	Use:     "estimate <task>|<prefix>* <duration>",
+	Aliases: []string{"budget"},
+	Short:   "Set the time budgeted for a task or a task name prefix",
+	Long: `Set an estimate for a task, or a budget for every task with a name prefix such as acme-*, e.g. for a fixed-price
+project. For example:
+
+time-tracker estimate acme-website-header 8h
+time-tracker budget "acme-website-*" 37h30m
+time-tracker estimate acme-website-header 0    # remove the estimate
+
+Starting and finishing a task shows the time left in its budgets, and warns once one is overrun. Give --strict to
+start or finish to fail instead. Run time-tracker budgets to compare every budget with the time spent.`,
+	Args: usageArgs(cobra.ExactArgs(2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		duration, err := time.ParseDuration(args[1])
+		if args[1] == "0" {
+			duration, err = 0, nil
+		}
+		if err != nil {
+			return fmt.Errorf("duration [%s] must be a duration such as 8h or 37h30m: %w", args[1], errInvalidUsage)
+		}
+		budget := app.Budget{Task: args[0], Duration: duration}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		budgets := newBudgets(eventStorage)
+		err = budgets.SetBudget(cmd.Context(), budget)
+		switch {
+		case errors.Is(err, app.ErrInvalidBudget):
+			return fmt.Errorf("%s: %w", err, errInvalidUsage)
+		case err != nil:
+			return fmt.Errorf("setting budget: %w", err)
+		}
+
+		statuses, err := budgets.Statuses(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("checking budgets: %w", err)
+		}
+		v := newBudgetView(app.BudgetStatus{Budget: budget})
+		for _, s := range statuses {
+			if s.Budget.Task == budget.Task {
+				v = newBudgetView(s)
+			}
+		}
+		v.text = "⏳ " + v.Text()
+		if duration == 0 {
+			v.text = fmt.Sprintf("⏳ %s no longer has a budget.", budget.Task)
+		}
+		return output(cmd, v)
+	},
+}
+
+// newBudgets returns the budgets kept in the event store.
+func newBudgets(eventStorage eventStorage) tasks.Budgets {
+	return tasks.NewBudgets(eventStorage, eventStorage, tasks.NewSessionCollector(eventStorage))
+}
+
+// checkBudgets returns the budgets that the time on a task counts towards.
+func checkBudgets(cmd *cobra.Command, eventStorage eventStorage, taskName string) ([]app.BudgetStatus, error) {
+	statuses, err := newBudgets(eventStorage).StatusesFor(cmd.Context(), taskName)
+	if err != nil {
+		return nil, fmt.Errorf("checking budgets: %w", err)
+	}
+	return statuses, nil
+}
+
+// overBudget returns the first budget which has been overrun, or which has no time left if the task is starting, for
+// --strict. The second return value is false if there isn't one.
+func overBudget(statuses []app.BudgetStatus, starting bool) (app.BudgetStatus, bool) {
+	for _, s := range statuses {
+		if s.Over() || (starting && s.Remaining() <= 0) {
+			return s, true
+		}
+	}
+	return app.BudgetStatus{}, false
+}
+
+// budgetKind is what a budget is called: an estimate for a task, or a budget for a prefix.
+func budgetKind(budget app.Budget) string {
+	if budget.IsPrefix() {
+		return "budget"
+	}
+	return "estimate"
+}
+
+func init() {
+	rootCmd.AddCommand(estimateCmd)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/ndjson"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// exportSince and exportUntil are the times given with --since and --until.
+var exportSince, exportUntil string
+
+// exportEventsCmd represents the export-events command
+var exportEventsCmd = &cobra.Command{
+	Use:   "export-events [file]",
+	Short: "Export every event as newline delimited JSON",
+	Long: `Export every recorded event, oldest first, as newline delimited JSON. This is a backup format which
+doesn't depend on the database, and can be restored with import-events. For example:
+
+time-tracker export-events backup.ndjson
+time-tracker export-events > backup.ndjson
+time-tracker export-events --since "last month" --until "this month" > september.ndjson
+
+When exporting to stdout, the events are always written as newline delimited JSON, whatever the output format.`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		var since, until time.Time
+		var err error
+		if exportSince != "" {
+			if since, err = parseTimeFlag("since", exportSince, now); err != nil {
+				return err
+			}
+		}
+		if exportUntil != "" {
+			if until, err = parseTimeFlag("until", exportUntil, now); err != nil {
+				return err
+			}
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		all, err := eventStorage.FetchAll(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("fetching events: %w", err)
+		}
+		var events []app.Event
+		for i := len(all) - 1; i >= 0; i-- {
+			e := all[i]
+			if (exportSince != "" && e.CreatedAt.Before(since)) || (exportUntil != "" && !e.CreatedAt.Before(until)) {
+				continue
+			}
+			events = append(events, e)
+		}
+
+		var out io.Writer = cmd.OutOrStdout()
+		if len(args) == 1 {
+			f, err := os.Create(args[0])
+			if err != nil {
+				return fmt.Errorf("creating export file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err = ndjson.WriteEvents(out, events); err != nil {
+			return fmt.Errorf("exporting events: %w", err)
+		}
+
+		if len(args) == 0 {
+			return nil
+		}
+		return output(cmd, exportView{Exported: len(events), File: args[0]})
+	},
+}
+
+// exportView is the output of exporting events to a file.
+type exportView struct {
+	Exported int    `json:"exported" yaml:"exported"`
+	File     string `json:"file" yaml:"file"`
+}
+
+func (v exportView) Text() string {
+	return fmt.Sprintf("📦 %d events exported to %s.", v.Exported, v.File)
+}
+
+func (v exportView) Header() []string {
+	return []string{"exported", "file"}
+}
+
+func (v exportView) Rows() [][]string {
+	return [][]string{{strconv.Itoa(v.Exported), v.File}}
+}
+
+func init() {
+	rootCmd.AddCommand(exportEventsCmd)
+	exportEventsCmd.Flags().StringVar(&exportSince, "since", "", "only export events from this time, "+timeFlagHelp)
+	exportEventsCmd.Flags().StringVar(&exportUntil, "until", "", "only export events before this time")
+}
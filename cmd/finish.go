@@ -22,73 +22,88 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/danmurf/time-tracker/internal/app"
-	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
 	"github.com/danmurf/time-tracker/internal/tasks"
-	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	// finishAt is the time given with --at.
+	finishAt string
+	// finishStrict is whether --strict was given, to fail if the task has overrun a budget.
+	finishStrict bool
+)
+
 // finishCmd represents the finish command
 var finishCmd = &cobra.Command{
 	Use:   "finish",
 	Short: "finish working on a task",
 	Long: `Record that you have finished working on a specific task, for example:
 
-time-tracker finish task1`,
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) != 1 {
-			cmd.PrintErrln("command usage is `time-tracker start <task-name>`")
-			return
-		}
+time-tracker finish task1
 
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("finding user home directory: %w", err))
-			return
-		}
+If you forgot to finish it at the time, give the time you finished with --at, for example:
 
-		dbPath := fmt.Sprintf("%s/%s", homeDir, ".time-tracker")
-		if err = os.MkdirAll(dbPath, os.ModePerm); err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating time tracker directory [%s]: %w", dbPath, err))
-			return
+time-tracker finish task1 --at 17:30
+time-tracker finish task1 --at "yesterday 17:30"
+
+The time left in any estimate or budget for the task is shown, with a warning once it's overrun. Give --strict to
+fail instead, though the task is still finished.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("command usage is `time-tracker finish <task-name>`: %w", errInvalidUsage)
 		}
 
-		dbFilePath := fmt.Sprintf("%s/%s", dbPath, "time-tracker.db")
-		db, err := sql.Open("sqlite3", dbFilePath)
+		at, err := parseAtFlag(finishAt)
 		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating database: %w", err))
-			return
+			return err
 		}
 
-		eventStorage, err := eventstore.NewSQLEventStore(cmd.Context(), db)
+		eventStorage, err := openEventStorage(cmd)
 		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating event store: %w", err))
-			return
+			return err
 		}
 
-		finisher := tasks.NewFinisher(eventStorage, eventStorage)
+		finisher := tasks.NewFinisher(eventStorage, eventStorage).WithClock(func() time.Time { return at })
 		taskName := args[0]
 		err = finisher.Finish(cmd.Context(), taskName)
 		switch {
-		case !errors.Is(err, app.ErrTaskNotStarted) && err != nil:
-			cmd.PrintErrln(fmt.Errorf("💥 finishing task: %w", err))
-			os.Exit(1)
 		case errors.Is(err, app.ErrTaskNotStarted):
-			cmd.PrintErrln(fmt.Sprintf("👀 %s not in progress", taskName))
-			os.Exit(1)
+			return describe(err, fmt.Sprintf("👀 %s not in progress", taskName))
+		case errors.Is(err, app.ErrEventOutOfOrder):
+			return describe(err, fmt.Sprintf("👀 %s can't finish before it started", taskName))
+		case err != nil:
+			return fmt.Errorf("finishing task: %w", err)
+		}
+
+		finished, err := eventStorage.LatestByName(cmd.Context(), taskName)
+		if err != nil {
+			return fmt.Errorf("finding finished event: %w", err)
+		}
+
+		budgets, err := checkBudgets(cmd, eventStorage, taskName)
+		if err != nil {
+			return err
+		}
+		if over, ok := overBudget(budgets, false); ok && finishStrict {
+			return describe(
+				fmt.Errorf("%s [%s]: %w", budgetKind(over.Budget), over.Budget.Task, app.ErrOverBudget),
+				fmt.Sprintf("🚫 %s finished, but it's %s", taskName, newBudgetView(over).Text()),
+			)
 		}
 
-		cmd.Printf("⏱  %s finished.\n", taskName)
+		return output(cmd, newTaskEventView(finished, fmt.Sprintf("⏱  %s finished.", taskName)).withBudgets(budgets))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(finishCmd)
+	finishCmd.Flags().StringVar(&finishAt, "at", "", "when the task was finished, if not now, "+timeFlagHelp)
+	finishCmd.Flags().BoolVar(&finishStrict, "strict", false, "fail if an estimate or budget for the task has been overrun")
 
 	// Here you will define your flags and configuration settings.
 
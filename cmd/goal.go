@@ -0,0 +1,150 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// goalDaily and goalWeekly are the durations given with --daily and --weekly.
+var goalDaily, goalWeekly string
+
+// goalCmd represents the goal command
+var goalCmd = &cobra.Command{
+	Use:   "goal",
+	Short: "Manage the hours you aim to track each day and week",
+	Long: `Set goals for the time tracked each day and each week, and follow the progress towards them with
+time-tracker progress. For example:
+
+time-tracker goal set --daily 7h30m --weekly 37h30m
+time-tracker goal set --weekly 0    # remove the weekly goal
+
+An absence added with time-tracker absence excuses the daily goal on its day, and its day's share of the weekly goal.
+When a contract is set in the config file, a day's share is its part of the contracted hours, so a day off in a
+four-day week excuses a quarter of the weekly goal. Without a contract, the weekly goal is shared evenly between
+Monday and Friday, so a weekday off excuses a fifth of it and a weekend day off excuses none.
+
+Goals are recorded as events, so they are synced along with the time tracked against them.`,
+}
+
+var goalSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set the daily and weekly goals",
+	Long:  `Set the daily goal with --daily, the weekly goal with --weekly, or both. A goal which isn't given is kept.`,
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("daily") && !cmd.Flags().Changed("weekly") {
+			return fmt.Errorf("--daily or --weekly must be given: %w", errInvalidUsage)
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+		// Weeks and the days worked don't matter when setting goals.
+		goalSetter := newGoals(eventStorage, app.Contract{}, time.Monday)
+		goals, err := goalSetter.Goals(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("finding goals: %w", err)
+		}
+		for _, flag := range []struct {
+			name  string
+			value string
+			goal  *time.Duration
+		}{{"daily", goalDaily, &goals.Daily}, {"weekly", goalWeekly, &goals.Weekly}} {
+			if !cmd.Flags().Changed(flag.name) {
+				continue
+			}
+			if *flag.goal, err = parseGoal(flag.value); err != nil {
+				return fmt.Errorf("--%s [%s] must be a duration such as 7h30m: %w", flag.name, flag.value, errInvalidUsage)
+			}
+		}
+
+		err = goalSetter.SetGoals(cmd.Context(), goals)
+		switch {
+		case errors.Is(err, app.ErrInvalidGoal):
+			return fmt.Errorf("%s: %w", err, errInvalidUsage)
+		case err != nil:
+			return fmt.Errorf("setting goals: %w", err)
+		}
+
+		return output(cmd, newGoalsView(goals))
+	},
+}
+
+// parseGoal parses a goal such as 7h30m, or 0 for no goal.
+func parseGoal(s string) (time.Duration, error) {
+	if s == "0" {
+		return 0, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// newGoals returns the goals kept in the event store, with the days worked set by the contract and weeks starting on
+// firstDay.
+func newGoals(eventStorage eventStorage, contract app.Contract, firstDay time.Weekday) tasks.Goals {
+	return tasks.NewGoals(eventStorage, eventStorage, tasks.NewSessionCollector(eventStorage), newAbsences(eventStorage), contract, firstDay)
+}
+
+// goalsView is the output of setting the daily and weekly goals.
+type goalsView struct {
+	DailySeconds  float64 `json:"daily_seconds" yaml:"daily_seconds"`
+	WeeklySeconds float64 `json:"weekly_seconds" yaml:"weekly_seconds"`
+	daily         time.Duration
+	weekly        time.Duration
+}
+
+func newGoalsView(g app.Goals) goalsView {
+	return goalsView{DailySeconds: g.Daily.Seconds(), WeeklySeconds: g.Weekly.Seconds(), daily: g.Daily, weekly: g.Weekly}
+}
+
+func (v goalsView) Text() string {
+	return fmt.Sprintf("🎯 daily goal: %s, weekly goal: %s.", formatGoal(v.daily), formatGoal(v.weekly))
+}
+
+func (v goalsView) Header() []string {
+	return []string{"daily_seconds", "daily", "weekly_seconds", "weekly"}
+}
+
+func (v goalsView) Rows() [][]string {
+	return [][]string{{formatSeconds(v.DailySeconds), formatDuration(v.daily), formatSeconds(v.WeeklySeconds), formatDuration(v.weekly)}}
+}
+
+// formatGoal formats a goal, which is "none" if it isn't set.
+func formatGoal(d time.Duration) string {
+	if d == 0 {
+		return "none"
+	}
+	return formatDuration(d)
+}
+
+func init() {
+	rootCmd.AddCommand(goalCmd)
+	goalCmd.AddCommand(goalSetCmd)
+	goalSetCmd.Flags().StringVar(&goalDaily, "daily", "", "the time to track each day, e.g. 7h30m, or 0 for none")
+	goalSetCmd.Flags().StringVar(&goalWeekly, "weekly", "", "the time to track each week, e.g. 37h30m, or 0 for none")
+}
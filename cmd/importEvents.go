@@ -0,0 +1,138 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/ndjson"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// importDryRun reports what would be imported, without importing anything.
+var importDryRun bool
+
+// importEventsCmd represents the import-events command
+var importEventsCmd = &cobra.Command{
+	Use:   "import-events [file]",
+	Short: "Import events exported by export-events",
+	Long: `Import events from newline delimited JSON written by export-events, for example to restore a backup or
+move to a new machine. Events which have already been recorded are skipped, so importing the same file twice is
+safe. For example:
+
+time-tracker import-events --dry-run backup.ndjson
+time-tracker import-events backup.ndjson
+time-tracker import-events < backup.ndjson`,
+	Args: usageArgs(cobra.MaximumNArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var in io.Reader = cmd.InOrStdin()
+		if len(args) == 1 {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("opening import file: %w", err)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		events, err := ndjson.ReadEvents(in)
+		if err != nil {
+			return fmt.Errorf("reading events: %w", err)
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		result, err := tasks.NewImporter(eventStorage, eventStorage).Import(cmd.Context(), events, importDryRun)
+		if err != nil {
+			return fmt.Errorf("importing events: %w", err)
+		}
+
+		return output(cmd, newImportView(importDryRun, result))
+	},
+}
+
+// importView is the output of importing events.
+type importView struct {
+	DryRun     bool       `json:"dry_run" yaml:"dry_run"`
+	Imported   int        `json:"imported" yaml:"imported"`
+	Duplicates int        `json:"duplicates" yaml:"duplicates"`
+	Earliest   *time.Time `json:"earliest" yaml:"earliest"`
+	Latest     *time.Time `json:"latest" yaml:"latest"`
+	// DuplicateInvoiceNumbers are shared by imported invoices and invoices already recorded.
+	DuplicateInvoiceNumbers []string `json:"duplicate_invoice_numbers" yaml:"duplicate_invoice_numbers"`
+}
+
+func newImportView(dryRun bool, result app.ImportResult) importView {
+	v := importView{
+		DryRun:                  dryRun,
+		Imported:                result.Imported,
+		Duplicates:              result.Duplicates,
+		DuplicateInvoiceNumbers: append([]string{}, result.DuplicateInvoiceNumbers...),
+	}
+	if result.Imported > 0 {
+		earliest, latest := result.Earliest.In(location), result.Latest.In(location)
+		v.Earliest, v.Latest = &earliest, &latest
+	}
+	return v
+}
+
+func (v importView) Text() string {
+	verb := "imported"
+	if v.DryRun {
+		verb = "would be imported"
+	}
+	text := fmt.Sprintf("📦 %d events %s, %d already recorded.", v.Imported, verb, v.Duplicates)
+	if v.Earliest != nil && v.Latest != nil {
+		text += fmt.Sprintf("\n   They range from %s to %s.", *v.Earliest, *v.Latest)
+	}
+	if len(v.DuplicateInvoiceNumbers) > 0 {
+		text += "\n" + duplicateInvoicesWarning(v.DuplicateInvoiceNumbers)
+	}
+	return text
+}
+
+func (v importView) Header() []string {
+	return []string{"dry_run", "imported", "duplicates", "earliest", "latest", "duplicate_invoice_numbers"}
+}
+
+func (v importView) Rows() [][]string {
+	earliest, latest := "", ""
+	if v.Earliest != nil && v.Latest != nil {
+		earliest, latest = formatTime(*v.Earliest), formatTime(*v.Latest)
+	}
+	return [][]string{{strconv.FormatBool(v.DryRun), strconv.Itoa(v.Imported), strconv.Itoa(v.Duplicates), earliest, latest, strings.Join(v.DuplicateInvoiceNumbers, " ")}}
+}
+
+func init() {
+	rootCmd.AddCommand(importEventsCmd)
+
+	importEventsCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "report what would be imported without importing anything")
+}
@@ -0,0 +1,466 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/ubl"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+var (
+	// invoiceTag is the tag given with --tag, to invoice every task with the tag.
+	invoiceTag string
+	// invoiceClient is the client given with --client, as shown on the invoice.
+	invoiceClient string
+	// invoiceDryRun is whether --dry-run was given, to draft an invoice without recording it.
+	invoiceDryRun bool
+)
+
+// invoiceCmd represents the invoice command
+var invoiceCmd = &cobra.Command{
+	Use:   "invoice",
+	Short: "Invoice clients for the time spent on their tasks",
+	Long: `Create numbered invoices for the time spent on a client's tasks, chosen by a task name prefix such as acme-* or by
+a tag, with a line for each task. Time is charged at the rates set with time-tracker rate set, after rounding it
+with the rounding rules in the config file. For example:
+
+time-tracker invoice create "acme-*" --client "Acme Ltd" --month -1 --dry-run
+time-tracker invoice create --tag globex --month -1
+time-tracker invoice show 2026-0001 -o html > 2026-0001.html
+time-tracker invoice show 2026-0001 -o markdown
+time-tracker invoice export 2026-0001 2026-0001.xml
+time-tracker invoice list
+
+Invoices and the sessions they include are recorded as events, so the same time is never invoiced twice. Invoices
+are numbered after the highest number issued that year, so issue them from a single event store: invoices issued in
+two stores before they are synced can share a number, which sync and import report.`,
+}
+
+var invoiceCreateCmd = &cobra.Command{
+	Use:   "create [<task>|<prefix>*]",
+	Short: "Invoice the time in a period which hasn't been invoiced yet",
+	Long: `Invoice the completed sessions which started in a day, week or month, or between --from and --to, on tasks with a
+name or prefix, or with a tag given with --tag, which haven't been invoiced yet. All of the time must have a rate,
+in the same currency.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if invoiceTag != "" {
+			return usageArgs(cobra.NoArgs)(cmd, args)
+		}
+		return usageArgs(cobra.ExactArgs(1))(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		period, err := reportPeriod(cmd, time.Now().In(location))
+		if err != nil {
+			return err
+		}
+		rounding, err := settings.RoundingRules()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+
+		request := app.InvoiceRequest{Client: invoiceClient, Tag: invoiceTag, Period: period, DryRun: invoiceDryRun}
+		if invoiceTag == "" {
+			request.Task = args[0]
+		}
+		if request.Client == "" {
+			request.Client = strings.TrimRight(strings.TrimSuffix(request.Task, "*"), "-_/.:") + request.Tag
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		invoice, err := newInvoices(eventStorage, rounding).Invoice(cmd.Context(), request)
+		switch {
+		case errors.Is(err, app.ErrNothingToInvoice):
+			return describe(err, fmt.Sprintf("📭 no time to invoice for %s %s, that hasn't been invoiced already.", request.Client, formatPeriod(period.From, period.To)))
+		case errors.Is(err, app.ErrCannotInvoice):
+			return describe(err, fmt.Sprintf("💸 %s", err))
+		case err != nil:
+			return fmt.Errorf("invoicing: %w", err)
+		}
+
+		return output(cmd, newInvoiceView(invoice, invoiceDryRun))
+	},
+}
+
+var invoiceShowCmd = &cobra.Command{
+	Use:   "show <number>",
+	Short: "Show an invoice as it was issued",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		invoice, err := findInvoice(cmd, eventStorage, args[0])
+		if err != nil {
+			return err
+		}
+		return output(cmd, newInvoiceView(invoice, false))
+	},
+}
+
+var invoiceExportCmd = &cobra.Command{
+	Use:   "export <number> [file]",
+	Short: "Export an invoice as a UBL 2.1 e-invoice",
+	Long: `Export an invoice as a UBL 2.1 XML e-invoice following EN 16931, with a line for each task in hours at its rate.
+Who the invoice is from and to, and the VAT charged, are set under invoicing in the config file, with the client
+keyed by the client name on the invoice.
+
+When exporting to stdout, the e-invoice is always written as XML, whatever the output format.`,
+	Args: usageArgs(cobra.RangeArgs(1, 2)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		invoice, err := findInvoice(cmd, eventStorage, args[0])
+		if err != nil {
+			return err
+		}
+		details, err := settings.EInvoice(invoice.Client)
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+		invoice.IssuedAt = invoice.IssuedAt.In(location)
+		invoice.Period.From = invoice.Period.From.In(location)
+		invoice.Period.To = invoice.Period.To.In(location)
+
+		var out io.Writer = cmd.OutOrStdout()
+		if len(args) == 2 {
+			f, err := os.Create(args[1])
+			if err != nil {
+				return fmt.Errorf("creating e-invoice file: %w", err)
+			}
+			defer f.Close()
+			out = f
+		}
+
+		if err = ubl.Write(out, invoice, details); err != nil {
+			return fmt.Errorf("exporting invoice: %w", err)
+		}
+
+		if len(args) == 1 {
+			return nil
+		}
+		return output(cmd, invoiceExportView{Number: invoice.Number, File: args[1]})
+	},
+}
+
+var invoiceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List invoices",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		invoices, err := newInvoices(eventStorage, nil).Invoices(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("listing invoices: %w", err)
+		}
+
+		v := invoiceListView{Invoices: []invoiceView{}}
+		for _, invoice := range invoices {
+			v.Invoices = append(v.Invoices, newInvoiceView(invoice, false))
+		}
+		return output(cmd, v)
+	},
+}
+
+// findInvoice returns the invoice with a number, describing the error if there isn't one.
+func findInvoice(cmd *cobra.Command, eventStorage eventStorage, number string) (app.Invoice, error) {
+	invoices, err := newInvoices(eventStorage, nil).Invoices(cmd.Context())
+	if err != nil {
+		return app.Invoice{}, fmt.Errorf("listing invoices: %w", err)
+	}
+	for _, invoice := range invoices {
+		if invoice.Number == number {
+			return invoice, nil
+		}
+	}
+
+	return app.Invoice{}, describe(fmt.Errorf("invoice [%s]: %w", number, app.ErrEventNotFound), fmt.Sprintf(
+		"👀 there is no invoice %s. Run `time-tracker invoice list` to see every invoice.", number,
+	))
+}
+
+// newInvoices returns the invoices kept in the event store.
+func newInvoices(eventStorage eventStorage, rounding app.RoundingRules) tasks.Invoices {
+	return tasks.NewInvoices(eventStorage, eventStorage, tasks.NewSessionCollector(eventStorage), tasks.NewRates(eventStorage, eventStorage), rounding)
+}
+
+// invoiceExportView is the output of exporting an invoice to a file as an e-invoice.
+type invoiceExportView struct {
+	Number string `json:"number" yaml:"number"`
+	File   string `json:"file" yaml:"file"`
+}
+
+func (v invoiceExportView) Text() string {
+	return fmt.Sprintf("📦 invoice %s exported to %s.", v.Number, v.File)
+}
+
+func (v invoiceExportView) Header() []string {
+	return []string{"number", "file"}
+}
+
+func (v invoiceExportView) Rows() [][]string {
+	return [][]string{{v.Number, v.File}}
+}
+
+// invoiceView is the output of invoicing a client. It's a document, so in Markdown and HTML it's the invoice itself,
+// rather than a table.
+type invoiceView struct {
+	Number   string            `json:"number" yaml:"number"`
+	Client   string            `json:"client" yaml:"client"`
+	Task     string            `json:"task,omitempty" yaml:"task,omitempty"`
+	Tag      string            `json:"tag,omitempty" yaml:"tag,omitempty"`
+	From     time.Time         `json:"from" yaml:"from"`
+	To       time.Time         `json:"to" yaml:"to"`
+	IssuedAt time.Time         `json:"issued_at" yaml:"issued_at"`
+	Currency string            `json:"currency" yaml:"currency"`
+	Lines    []invoiceLineView `json:"lines" yaml:"lines"`
+	Hours    float64           `json:"hours" yaml:"hours"`
+	Total    float64           `json:"total" yaml:"total"`
+	Sessions []string          `json:"sessions" yaml:"sessions"`
+	// DryRun is whether the invoice is a draft which hasn't been recorded.
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
+}
+
+type invoiceLineView struct {
+	Task            string  `json:"task" yaml:"task"`
+	Sessions        int     `json:"sessions" yaml:"sessions"`
+	DurationSeconds float64 `json:"duration_seconds" yaml:"duration_seconds"`
+	RoundedSeconds  float64 `json:"rounded_seconds" yaml:"rounded_seconds"`
+	Hours           float64 `json:"hours" yaml:"hours"`
+	Rate            float64 `json:"rate" yaml:"rate"`
+	Amount          float64 `json:"amount" yaml:"amount"`
+}
+
+func newInvoiceView(i app.Invoice, dryRun bool) invoiceView {
+	v := invoiceView{
+		Number:   i.Number,
+		Client:   i.Client,
+		Task:     i.Task,
+		Tag:      i.Tag,
+		From:     i.Period.From.In(location),
+		To:       i.Period.To.In(location),
+		IssuedAt: i.IssuedAt.In(location),
+		Currency: i.Currency,
+		Lines:    []invoiceLineView{},
+		Hours:    i.Quantity(),
+		Total:    i.Total,
+		Sessions: []string{},
+		DryRun:   dryRun,
+	}
+	for _, l := range i.Lines {
+		v.Lines = append(v.Lines, invoiceLineView{
+			Task:            l.TaskName,
+			Sessions:        l.Sessions,
+			DurationSeconds: l.Duration.Seconds(),
+			RoundedSeconds:  l.Rounded.Seconds(),
+			Hours:           l.Quantity,
+			Rate:            l.Rate.Amount,
+			Amount:          l.Amount,
+		})
+	}
+	for _, id := range i.Sessions {
+		v.Sessions = append(v.Sessions, id.String())
+	}
+	return v
+}
+
+func (v invoiceView) Text() string {
+	var rows [][]string
+	sessions := 0
+	for _, l := range v.Lines {
+		rows = append(rows, []string{l.Task, strconv.Itoa(l.Sessions), formatMoney(l.Hours), formatAmount(l.Rate, v.Currency) + "/h", formatAmount(l.Amount, v.Currency)})
+		sessions += l.Sessions
+	}
+	rows = append(rows, []string{"Total", strconv.Itoa(sessions), formatMoney(v.Hours), "", formatAmount(v.Total, v.Currency)})
+
+	next := fmt.Sprintf("Run `time-tracker invoice show %s -o html > %s.html` to save it.", v.Number, v.Number)
+	if v.DryRun {
+		next = "This is a draft, so nothing has been recorded."
+	}
+	return fmt.Sprintf("🧾 invoice %s for %s, %s\n\n%s\n%s", v.Number, v.Client, formatPeriod(v.From, v.To), formatTable(
+		[]string{"Task", "Sessions", "Hours", "Rate", "Amount"}, rows,
+	), next)
+}
+
+func (v invoiceView) Header() []string {
+	return []string{"number", "task", "sessions", "duration_seconds", "rounded_seconds", "hours", "rate", "currency", "amount"}
+}
+
+func (v invoiceView) Rows() [][]string {
+	var rows [][]string
+	for _, l := range v.Lines {
+		rows = append(rows, []string{v.Number, l.Task, strconv.Itoa(l.Sessions), formatSeconds(l.DurationSeconds),
+			formatSeconds(l.RoundedSeconds), formatMoney(l.Hours), formatMoney(l.Rate), v.Currency, formatMoney(l.Amount)})
+	}
+	return rows
+}
+
+// invoiceDocument is the formatted content of an invoice, for the Markdown and HTML templates.
+type invoiceDocument struct {
+	Number, Client, Issued, Period, Hours, Total string
+	Lines                                        []invoiceDocumentLine
+}
+
+type invoiceDocumentLine struct {
+	Task, Hours, Rate, Amount string
+}
+
+func (v invoiceView) document() invoiceDocument {
+	d := invoiceDocument{
+		Number: v.Number,
+		Client: v.Client,
+		Issued: formatDate(v.IssuedAt),
+		Period: formatPeriod(v.From, v.To),
+		Hours:  formatMoney(v.Hours),
+		Total:  formatAmount(v.Total, v.Currency),
+	}
+	for _, l := range v.Lines {
+		d.Lines = append(d.Lines, invoiceDocumentLine{
+			Task:   l.Task,
+			Hours:  formatMoney(l.Hours),
+			Rate:   formatAmount(l.Rate, v.Currency),
+			Amount: formatAmount(l.Amount, v.Currency),
+		})
+	}
+	return d
+}
+
+var invoiceMarkdown = texttemplate.Must(texttemplate.New("invoice").Funcs(texttemplate.FuncMap{
+	"cell": func(s string) string { return strings.ReplaceAll(s, "|", `\|`) },
+}).Parse(`# Invoice {{.Number}}
+
+- **Client:** {{.Client}}
+- **Issued:** {{.Issued}}
+- **Period:** {{.Period}}
+
+| Task | Hours | Rate per hour | Amount |
+| --- | ---: | ---: | ---: |
+{{range .Lines}}| {{cell .Task}} | {{.Hours}} | {{.Rate}} | {{.Amount}} |
+{{end}}| **Total** | **{{.Hours}}** | | **{{.Total}}** |
+`))
+
+var invoiceHTML = htmltemplate.Must(htmltemplate.New("invoice").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ccc; padding: 0.4em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+tfoot th { border-bottom: none; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<dl>
+<dt>Client</dt><dd>{{.Client}}</dd>
+<dt>Issued</dt><dd>{{.Issued}}</dd>
+<dt>Period</dt><dd>{{.Period}}</dd>
+</dl>
+<table>
+<thead><tr><th>Task</th><th>Hours</th><th>Rate per hour</th><th>Amount</th></tr></thead>
+<tbody>
+{{range .Lines}}<tr><td>{{.Task}}</td><td>{{.Hours}}</td><td>{{.Rate}}</td><td>{{.Amount}}</td></tr>
+{{end}}</tbody>
+<tfoot><tr><th>Total</th><th>{{.Hours}}</th><th></th><th>{{.Total}}</th></tr></tfoot>
+</table>
+</body>
+</html>
+`))
+
+func (v invoiceView) Markdown() string {
+	var b strings.Builder
+	if err := invoiceMarkdown.Execute(&b, v.document()); err != nil {
+		return fmt.Sprintf("rendering invoice: %s\n", err)
+	}
+	return b.String()
+}
+
+func (v invoiceView) HTML() string {
+	var b strings.Builder
+	if err := invoiceHTML.Execute(&b, v.document()); err != nil {
+		return fmt.Sprintf("rendering invoice: %s\n", err)
+	}
+	return b.String()
+}
+
+// invoiceListView is the output of listing invoices.
+type invoiceListView struct {
+	Invoices []invoiceView `json:"invoices" yaml:"invoices"`
+}
+
+func (v invoiceListView) Text() string {
+	if len(v.Invoices) == 0 {
+		return "📭 no invoices have been recorded."
+	}
+	var rows [][]string
+	for _, i := range v.Invoices {
+		rows = append(rows, []string{i.Number, formatDate(i.IssuedAt), i.Client, formatPeriod(i.From, i.To), formatMoney(i.Hours), formatAmount(i.Total, i.Currency)})
+	}
+	return formatTable([]string{"Number", "Issued", "Client", "Period", "Hours", "Total"}, rows)
+}
+
+func (v invoiceListView) Header() []string {
+	return []string{"number", "issued_at", "client", "from", "to", "hours", "currency", "total"}
+}
+
+func (v invoiceListView) Rows() [][]string {
+	var rows [][]string
+	for _, i := range v.Invoices {
+		rows = append(rows, []string{i.Number, formatTime(i.IssuedAt), i.Client, formatTime(i.From), formatTime(i.To), formatMoney(i.Hours), i.Currency, formatMoney(i.Total)})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(invoiceCmd)
+	invoiceCmd.AddCommand(invoiceCreateCmd, invoiceShowCmd, invoiceExportCmd, invoiceListCmd)
+	addPeriodFlags(invoiceCreateCmd)
+	invoiceCreateCmd.Flags().StringVarP(&invoiceTag, "tag", "t", "", "invoice every task with this tag, instead of tasks with a name or prefix")
+	invoiceCreateCmd.Flags().StringVar(&invoiceClient, "client", "", "who the invoice is for, as shown on it (default is the prefix or tag)")
+	invoiceCreateCmd.Flags().BoolVar(&invoiceDryRun, "dry-run", false, "draft the invoice without recording it")
+}
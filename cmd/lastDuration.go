@@ -22,12 +22,11 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"database/sql"
 	"fmt"
-	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/danmurf/time-tracker/internal/app"
 	"github.com/danmurf/time-tracker/internal/tasks"
 	"github.com/spf13/cobra"
-	"os"
+	"time"
 )
 
 // lastDurationCmd represents the lastDuration command
@@ -37,35 +36,14 @@ var lastDurationCmd = &cobra.Command{
 	Long: `Gets the duration of the last task with the specified name. e.g.
 
 time-tracker lastDuration my-task`,
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
-			cmd.PrintErrln("command usage is `time-tracker start <task-name>`")
-			os.Exit(1)
+			return fmt.Errorf("command usage is `time-tracker lastDuration <task-name>`: %w", errInvalidUsage)
 		}
 
-		homeDir, err := os.UserHomeDir()
+		eventStorage, err := openEventStorage(cmd)
 		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("finding user home directory: %w", err))
-			os.Exit(1)
-		}
-
-		dbPath := fmt.Sprintf("%s/%s", homeDir, ".time-tracker")
-		if err = os.MkdirAll(dbPath, os.ModePerm); err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating time tracker directory [%s]: %w", dbPath, err))
-			os.Exit(1)
-		}
-
-		dbFilePath := fmt.Sprintf("%s/%s", dbPath, "time-tracker.db")
-		db, err := sql.Open("sqlite3", dbFilePath)
-		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating database: %w", err))
-			os.Exit(1)
-		}
-
-		eventStorage, err := eventstore.NewSQLEventStore(cmd.Context(), db)
-		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating event store: %w", err))
-			os.Exit(1)
+			return err
 		}
 
 		durations := tasks.NewDurations(eventStorage)
@@ -73,17 +51,44 @@ time-tracker lastDuration my-task`,
 
 		completed, err := durations.FetchLastCompleted(cmd.Context(), taskName)
 		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("fetching completed task: %w", err))
-			os.Exit(1)
+			return fmt.Errorf("fetching completed task: %w", err)
 		}
 
-		cmd.Printf(
-			"⏱  %s took %s (started at %s and finished at %s).\n",
-			taskName, completed.Duration, completed.Started.CreatedAt, completed.Finished.CreatedAt,
-		)
+		return output(cmd, newCompletedTaskView(completed))
 	},
 }
 
+// completedTaskView is the output of a task which has been started and finished.
+type completedTaskView struct {
+	Task            string    `json:"task" yaml:"task"`
+	StartedAt       time.Time `json:"started_at" yaml:"started_at"`
+	FinishedAt      time.Time `json:"finished_at" yaml:"finished_at"`
+	DurationSeconds float64   `json:"duration_seconds" yaml:"duration_seconds"`
+	duration        time.Duration
+}
+
+func newCompletedTaskView(ct app.CompletedTask) completedTaskView {
+	return completedTaskView{
+		Task:            ct.Name,
+		StartedAt:       ct.Started.CreatedAt.In(location),
+		FinishedAt:      ct.Finished.CreatedAt.In(location),
+		DurationSeconds: ct.Duration.Seconds(),
+		duration:        ct.Duration,
+	}
+}
+
+func (v completedTaskView) Text() string {
+	return fmt.Sprintf("⏱  %s took %s (started at %s and finished at %s).", v.Task, formatDuration(v.duration), v.StartedAt, v.FinishedAt)
+}
+
+func (v completedTaskView) Header() []string {
+	return []string{"task", "started_at", "finished_at", "duration_seconds", "duration"}
+}
+
+func (v completedTaskView) Rows() [][]string {
+	return [][]string{{v.Task, formatTime(v.StartedAt), formatTime(v.FinishedAt), formatSeconds(v.DurationSeconds), formatDuration(v.duration)}}
+}
+
 func init() {
 	rootCmd.AddCommand(lastDurationCmd)
 
@@ -0,0 +1,157 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/config"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+	"github.com/danmurf/time-tracker/internal/pkg/render"
+	"github.com/spf13/cobra"
+	"io"
+)
+
+const errInvalidUsage = app.Error("invalid usage")
+
+const (
+	exitOK       = 0
+	exitInternal = 1
+)
+
+var (
+	// outputFlag is the value of the --output flag, which is parsed into outputFormat before any command runs.
+	outputFlag   string
+	outputFormat = render.FormatText
+)
+
+// errorMappings maps errors to the stable codes used in structured error output, and to the process exit code. The
+// first match wins, and any other error is "internal" with exit code 1. Exit codes are documented in the README and
+// must not be changed or reused.
+var errorMappings = []struct {
+	err  error
+	code string
+	exit int
+}{
+	{err: errInvalidUsage, code: "invalid_usage", exit: 2},
+	{err: app.ErrTaskAlreadyStarted, code: "task_already_started", exit: 3},
+	{err: app.ErrTaskNotStarted, code: "task_not_started", exit: 4},
+	{err: app.ErrTaskNeverCompleted, code: "task_never_completed", exit: 5},
+	{err: app.ErrEventNotFound, code: "event_not_found", exit: 6},
+	{err: app.ErrEventChainBroken, code: "event_chain_broken", exit: 7},
+	{err: encryption.ErrWrongPassphrase, code: "wrong_passphrase", exit: 8},
+	{err: errInvalidConfig, code: "invalid_config", exit: 9},
+	{err: config.ErrProfileNotFound, code: "profile_not_found", exit: 10},
+	{err: config.ErrProfileExists, code: "profile_exists", exit: 11},
+	{err: app.ErrEventOutOfOrder, code: "event_out_of_order", exit: 12},
+	{err: app.ErrNothingToInvoice, code: "nothing_to_invoice", exit: 13},
+	{err: app.ErrCannotInvoice, code: "cannot_invoice", exit: 14},
+	{err: app.ErrOverBudget, code: "over_budget", exit: 15},
+}
+
+// describedError is an error with a friendlier description for text output.
+type describedError struct {
+	err         error
+	description string
+}
+
+func (e describedError) Error() string {
+	return e.err.Error()
+}
+
+func (e describedError) Unwrap() error {
+	return e.err
+}
+
+// describe attaches a friendlier description to an error, which is shown instead of the error in text output.
+func describe(err error, description string) error {
+	return describedError{err: err, description: description}
+}
+
+// usageArgs wraps a positional argument validator, so that its errors are reported as invalid usage.
+func usageArgs(validate cobra.PositionalArgs) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if err := validate(cmd, args); err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidUsage)
+		}
+		return nil
+	}
+}
+
+// output renders the view to stdout in the format selected with --output.
+func output(cmd *cobra.Command, view render.View) error {
+	if err := render.Render(cmd.OutOrStdout(), outputFormat, view); err != nil {
+		return fmt.Errorf("rendering output: %w", err)
+	}
+	return nil
+}
+
+// handleError is the error handler for every command. It renders the error to w in the format selected with
+// --output, and returns the exit code for it.
+func handleError(w io.Writer, err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	view := errorView{Error: errorDetail{Code: "internal", Message: err.Error()}, description: fmt.Sprintf("💥 %s", err)}
+	exit := exitInternal
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			view.Error.Code = m.code
+			exit = m.exit
+			break
+		}
+	}
+	var described describedError
+	if errors.As(err, &described) {
+		view.description = described.description
+	}
+
+	if renderErr := render.Render(w, outputFormat, view); renderErr != nil {
+		_, _ = fmt.Fprintln(w, view.description)
+	}
+
+	return exit
+}
+
+type errorDetail struct {
+	Code    string `json:"code" yaml:"code"`
+	Message string `json:"message" yaml:"message"`
+}
+
+type errorView struct {
+	Error       errorDetail `json:"error" yaml:"error"`
+	description string
+}
+
+func (v errorView) Text() string {
+	return v.description
+}
+
+func (v errorView) Header() []string {
+	return []string{"code", "message"}
+}
+
+func (v errorView) Rows() [][]string {
+	return [][]string{{v.Error.Code, v.Error.Message}}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// overtimeBy is the length of the periods given with --by.
+var overtimeBy string
+
+// overtimeCmd represents the overtime command
+var overtimeCmd = &cobra.Command{
+	Use:   "overtime",
+	Short: "Work out your overtime or flexitime balance",
+	Long: `Compare the time tracked in each week or month since your contract started with your contracted hours, with a
+running overtime or flexitime balance up to the end of today. Any task in progress counts, and absences are taken
+off the hours expected. For example:
+
+time-tracker overtime
+time-tracker overtime --by month
+
+Set the contract in the config file, with the date it started and the hours for each day of the week:
+
+contract:
+  start: 2026-01-05
+  hours: {monday: 8h, tuesday: 8h, wednesday: 8h, thursday: 8h, friday: 5h30m}`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		by := app.Granularity(overtimeBy)
+		if by != app.ByWeek && by != app.ByMonth {
+			return fmt.Errorf("--by [%s] must be week or month: %w", overtimeBy, errInvalidUsage)
+		}
+		firstDay, err := settings.FirstWeekday()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+		contract, err := settings.ContractedHours()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		calculator := tasks.NewOvertimeCalculator(tasks.NewSessionCollector(eventStorage), newAbsences(eventStorage), contract, firstDay)
+		overtime, err := calculator.Overtime(cmd.Context(), time.Now().In(location), by)
+		switch {
+		case errors.Is(err, app.ErrNoContract):
+			return describe(fmt.Errorf("%s: %w", err, errInvalidConfig), "📄 no contract has been set. Set contract in the config file, with the date it started and the hours for each day of the week.")
+		case err != nil:
+			return fmt.Errorf("working out overtime: %w", err)
+		}
+
+		return output(cmd, newOvertimeView(overtime, by))
+	},
+}
+
+// overtimeView is the output of working out the overtime balance.
+type overtimeView struct {
+	At             time.Time            `json:"at" yaml:"at"`
+	ContractStart  time.Time            `json:"contract_start" yaml:"contract_start"`
+	By             string               `json:"by" yaml:"by"`
+	Periods        []overtimePeriodView `json:"periods" yaml:"periods"`
+	BalanceSeconds float64              `json:"balance_seconds" yaml:"balance_seconds"`
+	balance        time.Duration
+}
+
+type overtimePeriodView struct {
+	From              time.Time `json:"from" yaml:"from"`
+	To                time.Time `json:"to" yaml:"to"`
+	ExpectedSeconds   float64   `json:"expected_seconds" yaml:"expected_seconds"`
+	AbsentSeconds     float64   `json:"absent_seconds" yaml:"absent_seconds"`
+	WorkedSeconds     float64   `json:"worked_seconds" yaml:"worked_seconds"`
+	DifferenceSeconds float64   `json:"difference_seconds" yaml:"difference_seconds"`
+	BalanceSeconds    float64   `json:"balance_seconds" yaml:"balance_seconds"`
+	expected          time.Duration
+	absent            time.Duration
+	worked            time.Duration
+	difference        time.Duration
+	balance           time.Duration
+}
+
+func newOvertimeView(o app.Overtime, by app.Granularity) overtimeView {
+	v := overtimeView{
+		At:             o.At.In(location),
+		ContractStart:  o.Contract.Start.In(location),
+		By:             string(by),
+		Periods:        []overtimePeriodView{},
+		BalanceSeconds: o.Balance.Seconds(),
+		balance:        o.Balance,
+	}
+	for _, p := range o.Periods {
+		v.Periods = append(v.Periods, overtimePeriodView{
+			From:              p.Period.From.In(location),
+			To:                p.Period.To.In(location),
+			ExpectedSeconds:   p.Expected.Seconds(),
+			AbsentSeconds:     p.Absent.Seconds(),
+			WorkedSeconds:     p.Worked.Seconds(),
+			DifferenceSeconds: p.Difference().Seconds(),
+			BalanceSeconds:    p.Balance.Seconds(),
+			expected:          p.Expected,
+			absent:            p.Absent,
+			worked:            p.Worked,
+			difference:        p.Difference(),
+			balance:           p.Balance,
+		})
+	}
+	return v
+}
+
+func (v overtimeView) Text() string {
+	if len(v.Periods) == 0 {
+		return fmt.Sprintf("📄 the contract starts on %s.", formatDate(v.ContractStart))
+	}
+
+	var rows [][]string
+	for _, p := range v.Periods {
+		rows = append(rows, []string{formatPeriod(p.From, p.To), formatDuration(p.expected), formatDuration(p.absent), formatDuration(p.worked), formatBalance(p.difference), formatBalance(p.balance)})
+	}
+	balance := fmt.Sprintf("⚖️  %s overtime", formatBalance(v.balance))
+	if v.balance < 0 {
+		balance = fmt.Sprintf("⚖️  %s to make up", formatDuration(-v.balance))
+	}
+	return fmt.Sprintf("%s\n%s at the end of %s.", formatTable([]string{"Period", "Expected", "Absent", "Worked", "Difference", "Balance"}, rows), balance, formatDate(v.At))
+}
+
+func (v overtimeView) Header() []string {
+	return []string{"from", "to", "expected_seconds", "expected", "absent_seconds", "absent", "worked_seconds", "worked", "difference_seconds", "difference", "balance_seconds", "balance"}
+}
+
+func (v overtimeView) Rows() [][]string {
+	var rows [][]string
+	for _, p := range v.Periods {
+		rows = append(rows, []string{
+			formatTime(p.From),
+			formatTime(p.To),
+			formatSeconds(p.ExpectedSeconds),
+			formatDuration(p.expected),
+			formatSeconds(p.AbsentSeconds),
+			formatDuration(p.absent),
+			formatSeconds(p.WorkedSeconds),
+			formatDuration(p.worked),
+			formatSeconds(p.DifferenceSeconds),
+			formatBalance(p.difference),
+			formatSeconds(p.BalanceSeconds),
+			formatBalance(p.balance),
+		})
+	}
+	return rows
+}
+
+// formatBalance formats a duration which may be negative, with a + if it's positive, e.g. +01:30:00.
+func formatBalance(d time.Duration) string {
+	if d > 0 {
+		return "+" + formatDuration(d)
+	}
+	return formatDuration(d)
+}
+
+func init() {
+	rootCmd.AddCommand(overtimeCmd)
+	overtimeCmd.Flags().StringVar(&overtimeBy, "by", string(app.ByWeek), "break the balance down by week or month")
+}
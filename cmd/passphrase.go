@@ -0,0 +1,75 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// passphraseEnv is the environment variable the encryption passphrase is read from, instead of prompting for it.
+const passphraseEnv = "TIME_TRACKER_PASSPHRASE"
+
+// readPassphrase reads the encryption passphrase from the environment, or prompts for it. When prompting on a
+// terminal, the passphrase is not echoed.
+func readPassphrase(cmd *cobra.Command) (string, error) {
+	if passphrase := os.Getenv(passphraseEnv); passphrase != "" {
+		return passphrase, nil
+	}
+
+	in := cmd.InOrStdin()
+	if f, ok := in.(*os.File); ok && isTerminal(f) {
+		if err := stty(f, "-echo"); err == nil {
+			defer func() {
+				_ = stty(f, "echo")
+				cmd.PrintErrln()
+			}()
+		}
+	}
+
+	cmd.PrintErr("🔑 passphrase: ")
+	line, err := bufio.NewReader(in).ReadString('\n')
+	passphrase := strings.TrimRight(line, "\r\n")
+	if err != nil && passphrase == "" {
+		return "", fmt.Errorf("reading from input: %w", err)
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+
+	return passphrase, nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func stty(f *os.File, arg string) error {
+	c := exec.Command("stty", arg)
+	c.Stdin = f
+	return c.Run()
+}
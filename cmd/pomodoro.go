@@ -0,0 +1,154 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// runPomodoro works on the task in pomodoros until it's interrupted, counting down each focus and break in the
+// foreground and ringing the terminal bell as each one ends. Running it again after it was killed carries on from the
+// recorded events.
+func runPomodoro(cmd *cobra.Command, eventStorage eventStorage, taskName string, tags []string) error {
+	pomodoroSettings, err := settings.PomodoroSettings()
+	if err != nil {
+		return fmt.Errorf("%s: %w", err, errInvalidConfig)
+	}
+	timer := tasks.NewPomodoros(eventStorage, eventStorage, eventStorage, pomodoroSettings).
+		WithClock(func() time.Time { return time.Now().In(location) })
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pomodoro, err := timer.Start(cmd.Context(), taskName, tags...)
+	switch {
+	case errors.Is(err, app.ErrTaskAlreadyStarted):
+		return describe(err, fmt.Sprintf("👀 %s already in progress. Run `time-tracker finish %s` first to work on it in pomodoros.", taskName, taskName))
+	case errors.Is(err, app.ErrEventOutOfOrder):
+		return describe(err, fmt.Sprintf("👀 %s can't start before it last finished", taskName))
+	case err != nil:
+		return fmt.Errorf("starting pomodoro: %w", err)
+	}
+
+	w := cmd.ErrOrStderr()
+	for pomodoro.Phase != app.PomodoroStopped {
+		if !countdown(ctx, w, pomodoro) {
+			if pomodoro, err = timer.Stop(cmd.Context(), taskName); err != nil {
+				return fmt.Errorf("stopping pomodoro: %w", err)
+			}
+			break
+		}
+		if pomodoro, err = timer.Advance(cmd.Context(), taskName, tags...); err != nil {
+			return fmt.Errorf("advancing pomodoro: %w", err)
+		}
+		_, _ = fmt.Fprint(w, "\a")
+	}
+
+	return output(cmd, newPomodoroView(pomodoro))
+}
+
+// countdown shows the time left in a focus or break until it ends, returning false if ctx is done first. On a
+// terminal, the time left is redrawn every second, otherwise the end is shown once.
+func countdown(ctx context.Context, w io.Writer, p app.Pomodoro) bool {
+	f, ok := w.(*os.File)
+	redraw := ok && isTerminal(f)
+	label := fmt.Sprintf("🍅 focusing on %s", p.TaskName)
+	if p.Phase == app.PomodoroBreak {
+		label = "☕ taking a break"
+	}
+	if !redraw {
+		_, _ = fmt.Fprintf(w, "%s until %s, %d completed today\n", label, p.Ends.In(location).Format("15:04:05"), p.Completed)
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		left := time.Until(p.Ends)
+		if redraw {
+			_, _ = fmt.Fprintf(w, "\r\033[K%s: %s left, %d completed today", label, formatCountdown(left), p.Completed)
+		}
+		if left <= 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			if redraw {
+				_, _ = fmt.Fprintln(w)
+			}
+			return false
+		case <-ticker.C:
+		}
+	}
+	if redraw {
+		_, _ = fmt.Fprintln(w)
+	}
+	return true
+}
+
+// formatCountdown formats the time left as minutes and seconds, rounded up to the next second, e.g. 24:59.
+func formatCountdown(left time.Duration) string {
+	if left < 0 {
+		left = 0
+	}
+	seconds := int((left + time.Second - 1) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// pomodoroView is the output of working on a task in pomodoros once it's stopped.
+type pomodoroView struct {
+	Task      string `json:"task" yaml:"task"`
+	Completed int    `json:"completed_today" yaml:"completed_today"`
+}
+
+func newPomodoroView(p app.Pomodoro) pomodoroView {
+	return pomodoroView{Task: p.TaskName, Completed: p.Completed}
+}
+
+func (v pomodoroView) Text() string {
+	noun := "pomodoros"
+	if v.Completed == 1 {
+		noun = "pomodoro"
+	}
+	return fmt.Sprintf("⏹  %s stopped. 🍅 %d %s completed today.", v.Task, v.Completed, noun)
+}
+
+func (v pomodoroView) Header() []string {
+	return []string{"task", "completed_today"}
+}
+
+func (v pomodoroView) Rows() [][]string {
+	return [][]string{{v.Task, strconv.Itoa(v.Completed)}}
+}
@@ -0,0 +1,181 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/pkg/config"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// profileCmd represents the profile command
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage profiles, each with a separate event store and settings",
+	Long: `Profiles keep time tracked in different contexts apart, e.g. for an employer and for freelance work. Each profile
+has its own event store and config file, and doesn't share any settings with other profiles. For example:
+
+time-tracker profile create freelance
+time-tracker --profile freelance start client-website
+time-tracker profile use freelance
+time-tracker profile list
+
+The profile is chosen with --profile, then $TIME_TRACKER_PROFILE, then the profile chosen with profile use, and is
+otherwise the default profile.`,
+	// Profiles must be manageable even if the active profile can't be loaded, so settings aren't loaded here.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := parseOutputFormat(); err != nil {
+			return err
+		}
+		_, err := openProfiles()
+		return err
+	},
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("finding user home directory: %w", err)
+		}
+		names, err := profiles.List()
+		if err != nil {
+			return err
+		}
+
+		view := profileListView{Profiles: []profileView{}}
+		for _, name := range names {
+			p := profileView{Name: name, Active: name == profile, Config: profiles.ConfigPath(name)}
+			if settings, err := config.Load(p.Config, profiles.Defaults(name), os.Getenv, homeDir); err == nil {
+				p.DB = settings.DB
+			}
+			view.Profiles = append(view.Profiles, p)
+		}
+
+		return output(cmd, view)
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := config.ValidateProfileName(name); err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidUsage)
+		}
+		err := profiles.Create(name)
+		switch {
+		case errors.Is(err, config.ErrProfileExists):
+			return describe(err, fmt.Sprintf("👀 the %s profile already exists", name))
+		case err != nil:
+			return err
+		}
+
+		return output(cmd, profileView{Name: name, Config: profiles.ConfigPath(name), DB: profiles.Defaults(name).DB, text: fmt.Sprintf(
+			"✨ %s profile created. Run `time-tracker profile use %s` to switch to it, or edit its settings in %s.",
+			name, name, profiles.ConfigPath(name),
+		)})
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Switch to a profile, which is used until another is chosen",
+	Args:  usageArgs(cobra.ExactArgs(1)),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		err := profiles.Use(name)
+		switch {
+		case errors.Is(err, config.ErrProfileNotFound):
+			return describe(err, fmt.Sprintf("👀 there is no %s profile. Run `time-tracker profile create %s` to create it.", name, name))
+		case err != nil:
+			return err
+		}
+
+		return output(cmd, profileView{Name: name, Active: true, Config: profiles.ConfigPath(name), text: fmt.Sprintf(
+			"🔀 switched to the %s profile.", name,
+		)})
+	},
+}
+
+// profileView is the output of creating or switching to a profile.
+type profileView struct {
+	Name   string `json:"name" yaml:"name"`
+	Active bool   `json:"active" yaml:"active"`
+	Config string `json:"config" yaml:"config"`
+	DB     string `json:"db,omitempty" yaml:"db,omitempty"`
+	text   string
+}
+
+func (v profileView) Text() string {
+	return v.text
+}
+
+func (v profileView) Header() []string {
+	return []string{"name", "active", "config", "db"}
+}
+
+func (v profileView) Rows() [][]string {
+	return [][]string{{v.Name, strconv.FormatBool(v.Active), v.Config, v.DB}}
+}
+
+type profileListView struct {
+	Profiles []profileView `json:"profiles" yaml:"profiles"`
+}
+
+func (v profileListView) Text() string {
+	var b strings.Builder
+	for _, p := range v.Profiles {
+		marker := " "
+		if p.Active {
+			marker = "*"
+		}
+		_, _ = fmt.Fprintf(&b, "%s %s\t%s\n", marker, p.Name, p.DB)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (v profileListView) Header() []string {
+	return profileView{}.Header()
+}
+
+func (v profileListView) Rows() [][]string {
+	var rows [][]string
+	for _, p := range v.Profiles {
+		rows = append(rows, p.Rows()...)
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd, profileCreateCmd, profileUseCmd)
+}
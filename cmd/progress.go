@@ -0,0 +1,205 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/spf13/cobra"
+	"strings"
+	"time"
+)
+
+// progressCmd represents the progress command
+var progressCmd = &cobra.Command{
+	Use:   "progress",
+	Short: "Show the time tracked today and this week against your goals",
+	Long: `Show the time tracked today and this week, including any task in progress, against the goals set with
+time-tracker goal set, and when today's goal will be reached if you carry on working without a break.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		firstDay, err := settings.FirstWeekday()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+		contract, err := settings.ContractedHours()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		progress, err := newGoals(eventStorage, contract, firstDay).Progress(cmd.Context(), time.Now().In(location))
+		if err != nil {
+			return fmt.Errorf("working out progress: %w", err)
+		}
+
+		return output(cmd, newProgressView(progress))
+	},
+}
+
+// progressView is the output of working out the progress towards the goals.
+type progressView struct {
+	At              time.Time        `json:"at" yaml:"at"`
+	Day             goalProgressView `json:"day" yaml:"day"`
+	Week            goalProgressView `json:"week" yaml:"week"`
+	Absences        []absenceView    `json:"absences" yaml:"absences"`
+	Running         []string         `json:"running" yaml:"running"`
+	ProjectedFinish *time.Time       `json:"projected_finish,omitempty" yaml:"projected_finish,omitempty"`
+}
+
+type goalProgressView struct {
+	From             time.Time `json:"from" yaml:"from"`
+	To               time.Time `json:"to" yaml:"to"`
+	GoalSeconds      float64   `json:"goal_seconds" yaml:"goal_seconds"`
+	ExcusedSeconds   float64   `json:"excused_seconds" yaml:"excused_seconds"`
+	TrackedSeconds   float64   `json:"tracked_seconds" yaml:"tracked_seconds"`
+	RemainingSeconds float64   `json:"remaining_seconds" yaml:"remaining_seconds"`
+	goal             time.Duration
+	target           time.Duration
+	excused          time.Duration
+	tracked          time.Duration
+	remaining        time.Duration
+}
+
+func newGoalProgressView(p app.GoalProgress) goalProgressView {
+	return goalProgressView{
+		From:             p.Period.From.In(location),
+		To:               p.Period.To.In(location),
+		GoalSeconds:      p.Goal.Seconds(),
+		ExcusedSeconds:   p.Excused.Seconds(),
+		TrackedSeconds:   p.Tracked.Seconds(),
+		RemainingSeconds: p.Remaining().Seconds(),
+		goal:             p.Goal,
+		target:           p.Target(),
+		excused:          p.Excused,
+		tracked:          p.Tracked,
+		remaining:        p.Remaining(),
+	}
+}
+
+func newProgressView(p app.Progress) progressView {
+	v := progressView{
+		At:       p.At.In(location),
+		Day:      newGoalProgressView(p.Day),
+		Week:     newGoalProgressView(p.Week),
+		Absences: []absenceView{},
+		Running:  append([]string{}, p.Running...),
+	}
+	for _, a := range p.Absences {
+		v.Absences = append(v.Absences, newAbsenceView(a))
+	}
+	if !p.ProjectedFinish.IsZero() {
+		finish := p.ProjectedFinish.In(location)
+		v.ProjectedFinish = &finish
+	}
+	return v
+}
+
+func (v progressView) Text() string {
+	lines := []string{
+		"📅 Today:     " + v.Day.summary(),
+		"🗓  This week: " + v.Week.summary(),
+	}
+	for _, a := range v.Absences {
+		if a.Date == app.Date(v.At).Format("2006-01-02") {
+			lines = append(lines, fmt.Sprintf("🌴 Today is %s.", a.label()))
+		}
+	}
+	if len(v.Running) > 0 {
+		lines = append(lines, fmt.Sprintf("⏱  In progress: %s", strings.Join(v.Running, ", ")))
+	}
+	if v.ProjectedFinish != nil {
+		finish := v.ProjectedFinish.Format("15:04")
+		if formatDate(*v.ProjectedFinish) != formatDate(v.At) {
+			finish = v.ProjectedFinish.Format("2006-01-02 15:04")
+		}
+		if len(v.Running) > 0 {
+			lines = append(lines, fmt.Sprintf("🏁 Today's goal will be reached at %s if you carry on.", finish))
+		} else {
+			lines = append(lines, fmt.Sprintf("🏁 Today's goal would be reached at %s if you started now.", finish))
+		}
+	}
+	if v.Day.goal == 0 && v.Week.goal == 0 {
+		lines = append(lines, "🎯 No goals have been set. Run `time-tracker goal set --daily 7h30m` to set one.")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// summary is the time tracked against the goal, e.g. "05:00:00 of 07:30:00 (67%), 02:30:00 to go".
+func (v goalProgressView) summary() string {
+	if v.goal == 0 {
+		return fmt.Sprintf("%s tracked, no goal", formatDuration(v.tracked))
+	}
+	if v.target == 0 {
+		return fmt.Sprintf("%s tracked, goal excused by absences", formatDuration(v.tracked))
+	}
+	target := formatDuration(v.target)
+	if v.excused > 0 {
+		target += fmt.Sprintf(" after %s of absences", formatDuration(v.excused))
+	}
+	summary := fmt.Sprintf("%s of %s (%.0f%%)", formatDuration(v.tracked), target, 100*v.tracked.Seconds()/v.target.Seconds())
+	if v.remaining == 0 {
+		return summary + ", goal reached ✅"
+	}
+	return fmt.Sprintf("%s, %s to go", summary, formatDuration(v.remaining))
+}
+
+func (v progressView) Header() []string {
+	return []string{"period", "from", "to", "goal_seconds", "goal", "excused_seconds", "excused", "tracked_seconds", "tracked", "remaining_seconds", "remaining", "projected_finish"}
+}
+
+func (v progressView) Rows() [][]string {
+	finish := ""
+	if v.ProjectedFinish != nil {
+		finish = formatTime(*v.ProjectedFinish)
+	}
+	var rows [][]string
+	for _, p := range []struct {
+		name     string
+		progress goalProgressView
+		finish   string
+	}{{"day", v.Day, finish}, {"week", v.Week, ""}} {
+		rows = append(rows, []string{
+			p.name,
+			formatTime(p.progress.From),
+			formatTime(p.progress.To),
+			formatSeconds(p.progress.GoalSeconds),
+			formatDuration(p.progress.goal),
+			formatSeconds(p.progress.ExcusedSeconds),
+			formatDuration(p.progress.excused),
+			formatSeconds(p.progress.TrackedSeconds),
+			formatDuration(p.progress.tracked),
+			formatSeconds(p.progress.RemainingSeconds),
+			formatDuration(p.progress.remaining),
+			p.finish,
+		})
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(progressCmd)
+}
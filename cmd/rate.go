@@ -0,0 +1,224 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"strconv"
+	"time"
+)
+
+var (
+	// rateTag is the tag given with --tag, for a rate which applies to every task with the tag.
+	rateTag string
+	// rateCurrency is the currency given with --currency.
+	rateCurrency string
+	// rateFrom is the time given with --from, when the rate takes effect.
+	rateFrom string
+)
+
+// rateCmd represents the rate command
+var rateCmd = &cobra.Command{
+	Use:   "rate",
+	Short: "Manage the hourly rates that time is charged at",
+	Long: `Keep a registry of hourly rates for a task, a task name prefix such as acme-*, or a tag, for the earnings report.
+For example:
+
+time-tracker rate set acme-website 120 --currency EUR
+time-tracker rate set "acme-*" 110
+time-tracker rate set --tag globex 90 --currency USD
+time-tracker rate set acme-website 130 --from 2026-11-01
+time-tracker rate list
+
+A rate for a task beats a rate for a prefix, a longer prefix beats a shorter one, and a prefix beats a tag. Rates
+are recorded as events, so they are synced and exported along with the time they are charged for.`,
+}
+
+var rateSetCmd = &cobra.Command{
+	Use:   "set [<task>|<prefix>*] <amount>",
+	Short: "Set the hourly rate for a task, a task name prefix or a tag",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if rateTag != "" {
+			return usageArgs(cobra.ExactArgs(1))(cmd, args)
+		}
+		return usageArgs(cobra.ExactArgs(2))(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rate := app.Rate{Tag: rateTag, Currency: settings.Currency}
+		if rateTag == "" {
+			rate.Task, args = args[0], args[1:]
+		}
+		var err error
+		if rate.Amount, err = strconv.ParseFloat(args[0], 64); err != nil {
+			return fmt.Errorf("amount [%s] must be a number: %w", args[0], errInvalidUsage)
+		}
+		if rateCurrency != "" {
+			rate.Currency = rateCurrency
+		}
+		if rate.Currency == "" {
+			return fmt.Errorf("a currency must be given with --currency or set in the config file: %w", errInvalidUsage)
+		}
+		if rateFrom != "" {
+			if rate.EffectiveFrom, err = parseTimeFlag("from", rateFrom, time.Now()); err != nil {
+				return err
+			}
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		err = tasks.NewRates(eventStorage, eventStorage).SetRate(cmd.Context(), rate)
+		switch {
+		case errors.Is(err, app.ErrInvalidRate):
+			return fmt.Errorf("%s: %w", err, errInvalidUsage)
+		case err != nil:
+			return fmt.Errorf("setting rate: %w", err)
+		}
+
+		v := newRateView(rate)
+		v.text = fmt.Sprintf("💰 %s is charged at %s", v.target(), v.amount())
+		if !rate.EffectiveFrom.IsZero() {
+			v.text += fmt.Sprintf(" from %s", v.effectiveFrom())
+		}
+		v.text += "."
+		return output(cmd, v)
+	},
+}
+
+var rateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List rates",
+	Args:  usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		rates, err := tasks.NewRates(eventStorage, eventStorage).Rates(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("listing rates: %w", err)
+		}
+
+		v := rateListView{Rates: []rateView{}}
+		for _, rate := range rates {
+			v.Rates = append(v.Rates, newRateView(rate))
+		}
+		return output(cmd, v)
+	},
+}
+
+// rateView is the output of setting a rate.
+type rateView struct {
+	Task          string     `json:"task,omitempty" yaml:"task,omitempty"`
+	Tag           string     `json:"tag,omitempty" yaml:"tag,omitempty"`
+	Amount        float64    `json:"amount" yaml:"amount"`
+	Currency      string     `json:"currency" yaml:"currency"`
+	EffectiveFrom *time.Time `json:"effective_from,omitempty" yaml:"effective_from,omitempty"`
+	text          string
+}
+
+func newRateView(r app.Rate) rateView {
+	v := rateView{Task: r.Task, Tag: r.Tag, Amount: r.Amount, Currency: r.Currency}
+	if !r.EffectiveFrom.IsZero() {
+		from := r.EffectiveFrom.In(location)
+		v.EffectiveFrom = &from
+	}
+	return v
+}
+
+// target describes what the rate is for.
+func (v rateView) target() string {
+	if v.Tag != "" {
+		return "tag " + v.Tag
+	}
+	return v.Task
+}
+
+// amount formats the rate as an amount per hour, e.g. 120.00 EUR/h.
+func (v rateView) amount() string {
+	return formatAmount(v.Amount, v.Currency) + "/h"
+}
+
+func (v rateView) effectiveFrom() string {
+	if v.EffectiveFrom == nil {
+		return ""
+	}
+	return formatTime(*v.EffectiveFrom)
+}
+
+func (v rateView) Text() string {
+	return v.text
+}
+
+func (v rateView) Header() []string {
+	return []string{"task", "tag", "amount", "currency", "effective_from"}
+}
+
+func (v rateView) Rows() [][]string {
+	return [][]string{{v.Task, v.Tag, formatMoney(v.Amount), v.Currency, v.effectiveFrom()}}
+}
+
+type rateListView struct {
+	Rates []rateView `json:"rates" yaml:"rates"`
+}
+
+func (v rateListView) Text() string {
+	if len(v.Rates) == 0 {
+		return "📭 no rates have been set. Run `time-tracker rate set <task> <amount>` to set one."
+	}
+	var rows [][]string
+	for _, r := range v.Rates {
+		from := "always"
+		if r.EffectiveFrom != nil {
+			from = formatTime(*r.EffectiveFrom)
+		}
+		rows = append(rows, []string{r.target(), r.amount(), from})
+	}
+	return formatTable([]string{"For", "Rate", "From"}, rows)
+}
+
+func (v rateListView) Header() []string {
+	return rateView{}.Header()
+}
+
+func (v rateListView) Rows() [][]string {
+	var rows [][]string
+	for _, r := range v.Rates {
+		rows = append(rows, r.Rows()...)
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(rateCmd)
+	rateCmd.AddCommand(rateSetCmd, rateListCmd)
+	rateSetCmd.Flags().StringVarP(&rateTag, "tag", "t", "", "set the rate for every task with this tag, instead of for a task")
+	rateSetCmd.Flags().StringVar(&rateCurrency, "currency", "", "the currency of the rate, e.g. EUR (default is the currency setting)")
+	rateSetCmd.Flags().StringVar(&rateFrom, "from", "", "when the rate takes effect, if not for all time, "+timeFlagHelp)
+}
@@ -0,0 +1,327 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportPeriodFlags are the flags which select the period of a report, each with an offset from the current period.
+var reportPeriodFlags = []string{"day", "week", "month"}
+
+// reportCmd represents the report command
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarise the time spent on tasks in a day, week or month",
+	Long: `Summarise the completed sessions of work in a day, week or month, with the total time spent on each task, the
+first start and last finish of each day, and a grand total. For example:
+
+time-tracker report            # today
+time-tracker report --week     # this week
+time-tracker report --week -1  # last week
+time-tracker report --month -2 # the month before last
+time-tracker report --from monday --to "friday 12:00"
+
+Weeks start on the week_start day in the config file, Monday by default. If there are rounding rules in the config
+file, rounded durations are shown alongside the exact ones.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		period, err := reportPeriod(cmd, time.Now().In(location))
+		if err != nil {
+			return err
+		}
+		rounding, err := settings.RoundingRules()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		reporter := tasks.NewReporter(tasks.NewSessionCollector(eventStorage), rounding)
+		report, err := reporter.Report(cmd.Context(), period)
+		if err != nil {
+			return fmt.Errorf("reporting: %w", err)
+		}
+
+		return output(cmd, newReportView(report, len(rounding) > 0))
+	},
+}
+
+// reportPeriod returns the period selected with --day, --week or --month, or --from and --to, or today if none of
+// them are given.
+func reportPeriod(cmd *cobra.Command, now time.Time) (app.Period, error) {
+	if cmd.Flags().Changed("from") || cmd.Flags().Changed("to") {
+		return customPeriod(cmd, now)
+	}
+
+	selected := ""
+	offset := 0
+	for _, name := range reportPeriodFlags {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+		if selected != "" {
+			return app.Period{}, fmt.Errorf("only one of --%s and --%s can be given: %w", selected, name, errInvalidUsage)
+		}
+		selected = name
+		var err error
+		if offset, err = cmd.Flags().GetInt(name); err != nil {
+			return app.Period{}, fmt.Errorf("reading --%s: %w", name, err)
+		}
+	}
+
+	switch selected {
+	case "week":
+		firstDay, err := settings.FirstWeekday()
+		if err != nil {
+			return app.Period{}, fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+		return app.WeekPeriod(now, firstDay, offset), nil
+	case "month":
+		return app.MonthPeriod(now, offset), nil
+	default:
+		return app.DayPeriod(now, offset), nil
+	}
+}
+
+// customPeriod returns the period from --from, or the start of today, up to --to, or now.
+func customPeriod(cmd *cobra.Command, now time.Time) (app.Period, error) {
+	for _, name := range reportPeriodFlags {
+		if cmd.Flags().Changed(name) {
+			return app.Period{}, fmt.Errorf("--%s can't be given with --from or --to: %w", name, errInvalidUsage)
+		}
+	}
+
+	period := app.Period{From: app.DayPeriod(now, 0).From, To: now}
+	for _, flag := range []struct {
+		name string
+		time *time.Time
+	}{{"from", &period.From}, {"to", &period.To}} {
+		expr, err := cmd.Flags().GetString(flag.name)
+		if err != nil {
+			return app.Period{}, fmt.Errorf("reading --%s: %w", flag.name, err)
+		}
+		if expr == "" {
+			continue
+		}
+		if *flag.time, err = parseTimeFlag(flag.name, expr, now); err != nil {
+			return app.Period{}, err
+		}
+	}
+	if !period.From.Before(period.To) {
+		return app.Period{}, fmt.Errorf("--from must be before --to: %w", errInvalidUsage)
+	}
+	return period, nil
+}
+
+var periodOffset = regexp.MustCompile(`^[-+]?\d+$`)
+
+// joinPeriodOffsets joins period flags to a following offset, e.g. `--week -1` becomes `--week=-1`. The period flags
+// can be given without an offset, so the flag parser would otherwise read a negative offset as another flag. Only the
+// period flags of the command being run are joined, so the arguments of other commands are left as they are.
+func joinPeriodOffsets(root *cobra.Command, args []string) []string {
+	cmd, _, err := root.Find(args)
+	if err != nil {
+		return args
+	}
+
+	joined := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			return append(joined, args[i:]...)
+		}
+		if isPeriodFlag(cmd, arg) && i+1 < len(args) && periodOffset.MatchString(args[i+1]) {
+			arg += "=" + args[i+1]
+			i++
+		}
+		joined = append(joined, arg)
+	}
+	return joined
+}
+
+// isPeriodFlag reports whether the argument is one of the command's period flags, which are int flags that can be
+// given without a value.
+func isPeriodFlag(cmd *cobra.Command, arg string) bool {
+	if !strings.HasPrefix(arg, "--") {
+		return false
+	}
+	flag := cmd.Flags().Lookup(strings.TrimPrefix(arg, "--"))
+	return flag != nil && flag.NoOptDefVal != "" && flag.Value.Type() == "int"
+}
+
+// addPeriodFlags adds the flags read by reportPeriod to a command which reports on a period.
+func addPeriodFlags(cmd *cobra.Command) {
+	for _, name := range reportPeriodFlags {
+		cmd.Flags().Int(name, 0, fmt.Sprintf("report on a %s, optionally offset from this one, e.g. --%s -1 for the last", name, name))
+		cmd.Flags().Lookup(name).NoOptDefVal = "0"
+	}
+	cmd.Flags().String("from", "", "report from this time, "+timeFlagHelp)
+	cmd.Flags().String("to", "", "report up to, but not including, this time (default now)")
+}
+
+// reportView is the output of summarising the sessions in a period.
+type reportView struct {
+	From                time.Time       `json:"from" yaml:"from"`
+	To                  time.Time       `json:"to" yaml:"to"`
+	Tasks               []taskTotalView `json:"tasks" yaml:"tasks"`
+	Days                []dayTotalView  `json:"days" yaml:"days"`
+	Sessions            int             `json:"sessions" yaml:"sessions"`
+	TotalSeconds        float64         `json:"total_seconds" yaml:"total_seconds"`
+	RoundedTotalSeconds float64         `json:"rounded_total_seconds" yaml:"rounded_total_seconds"`
+	total               time.Duration
+	roundedTotal        time.Duration
+	// rounding is whether there are rounding rules, so rounded durations are worth showing in text.
+	rounding bool
+}
+
+type taskTotalView struct {
+	Task            string  `json:"task" yaml:"task"`
+	Sessions        int     `json:"sessions" yaml:"sessions"`
+	DurationSeconds float64 `json:"duration_seconds" yaml:"duration_seconds"`
+	RoundedSeconds  float64 `json:"rounded_seconds" yaml:"rounded_seconds"`
+	duration        time.Duration
+	rounded         time.Duration
+}
+
+type dayTotalView struct {
+	Date            string    `json:"date" yaml:"date"`
+	Sessions        int       `json:"sessions" yaml:"sessions"`
+	FirstStarted    time.Time `json:"first_started" yaml:"first_started"`
+	LastFinished    time.Time `json:"last_finished" yaml:"last_finished"`
+	DurationSeconds float64   `json:"duration_seconds" yaml:"duration_seconds"`
+	RoundedSeconds  float64   `json:"rounded_seconds" yaml:"rounded_seconds"`
+	duration        time.Duration
+	rounded         time.Duration
+}
+
+func newReportView(r app.Report, rounding bool) reportView {
+	v := reportView{
+		From:                r.Period.From.In(location),
+		To:                  r.Period.To.In(location),
+		Tasks:               []taskTotalView{},
+		Days:                []dayTotalView{},
+		Sessions:            r.Sessions,
+		TotalSeconds:        r.Total.Seconds(),
+		RoundedTotalSeconds: r.RoundedTotal.Seconds(),
+		total:               r.Total,
+		roundedTotal:        r.RoundedTotal,
+		rounding:            rounding,
+	}
+	for _, t := range r.Tasks {
+		v.Tasks = append(v.Tasks, taskTotalView{
+			Task:            t.TaskName,
+			Sessions:        t.Sessions,
+			DurationSeconds: t.Duration.Seconds(),
+			RoundedSeconds:  t.Rounded.Seconds(),
+			duration:        t.Duration,
+			rounded:         t.Rounded,
+		})
+	}
+	for _, d := range r.Days {
+		v.Days = append(v.Days, dayTotalView{
+			Date:            formatDate(d.Date),
+			Sessions:        d.Sessions,
+			FirstStarted:    d.FirstStarted.In(location),
+			LastFinished:    d.LastFinished.In(location),
+			DurationSeconds: d.Duration.Seconds(),
+			RoundedSeconds:  d.Rounded.Seconds(),
+			duration:        d.Duration,
+			rounded:         d.Rounded,
+		})
+	}
+	return v
+}
+
+func (v reportView) Text() string {
+	period := formatPeriod(v.From, v.To)
+	if v.Sessions == 0 {
+		return fmt.Sprintf("📭 no completed sessions %s.", period)
+	}
+
+	tasks := [][]string{}
+	for _, t := range v.Tasks {
+		tasks = append(tasks, v.withRounded([]string{t.Task, strconv.Itoa(t.Sessions), formatDuration(t.duration)}, t.rounded))
+	}
+	tasks = append(tasks, v.withRounded([]string{"Total", strconv.Itoa(v.Sessions), formatDuration(v.total)}, v.roundedTotal))
+
+	days := [][]string{}
+	for _, d := range v.Days {
+		days = append(days, v.withRounded([]string{
+			d.FirstStarted.Format("Mon 02 Jan"),
+			strconv.Itoa(d.Sessions),
+			d.FirstStarted.Format("15:04"),
+			d.LastFinished.Format("15:04"),
+			formatDuration(d.duration),
+		}, d.rounded))
+	}
+
+	taskHeader := []string{"Task", "Sessions", "Duration"}
+	dayHeader := []string{"Day", "Sessions", "First start", "Last finish", "Duration"}
+	if v.rounding {
+		taskHeader = append(taskHeader, "Rounded")
+		dayHeader = append(dayHeader, "Rounded")
+	}
+
+	return fmt.Sprintf("📊 %s\n\n%s\n%s", period, formatTable(taskHeader, tasks), formatTable(dayHeader, days))
+}
+
+// withRounded adds the rounded duration to a row of the text output, if there are rounding rules.
+func (v reportView) withRounded(row []string, rounded time.Duration) []string {
+	if !v.rounding {
+		return row
+	}
+	return append(row, formatDuration(rounded))
+}
+
+func (v reportView) Header() []string {
+	return []string{"row", "task", "date", "sessions", "first_started", "last_finished", "duration_seconds", "duration", "rounded_seconds", "rounded"}
+}
+
+func (v reportView) Rows() [][]string {
+	var rows [][]string
+	for _, t := range v.Tasks {
+		rows = append(rows, []string{"task", t.Task, "", strconv.Itoa(t.Sessions), "", "",
+			formatSeconds(t.DurationSeconds), formatDuration(t.duration), formatSeconds(t.RoundedSeconds), formatDuration(t.rounded)})
+	}
+	for _, d := range v.Days {
+		rows = append(rows, []string{"day", "", d.Date, strconv.Itoa(d.Sessions), formatTime(d.FirstStarted), formatTime(d.LastFinished),
+			formatSeconds(d.DurationSeconds), formatDuration(d.duration), formatSeconds(d.RoundedSeconds), formatDuration(d.rounded)})
+	}
+	return append(rows, []string{"total", "", "", strconv.Itoa(v.Sessions), "", "",
+		formatSeconds(v.TotalSeconds), formatDuration(v.total), formatSeconds(v.RoundedTotalSeconds), formatDuration(v.roundedTotal)})
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+	addPeriodFlags(reportCmd)
+}
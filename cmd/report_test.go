@@ -0,0 +1,78 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestJoinPeriodOffsets(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{name: "report with a negative offset", args: "report --week -1", want: "report --week=-1"},
+		{name: "report with a positive offset", args: "report --month +2 -o json", want: "report --month=+2 -o json"},
+		{name: "report without an offset", args: "report --day -o json", want: "report --day -o json"},
+		{name: "global flags before the command", args: "--profile work report --day -3", want: "--profile work report --day=-3"},
+		{name: "subcommand with period flags", args: "invoice create acme --month -1", want: "invoice create acme --month=-1"},
+		{name: "timesheet", args: "timesheet --week -1", want: "timesheet --week=-1"},
+		{name: "after the end of the flags", args: "report -- --week -1", want: "report -- --week -1"},
+		{name: "command without period flags", args: "start my-task --week -1", want: "start my-task --week -1"},
+		{name: "flag which always takes a value", args: "report --from -1", want: "report --from -1"},
+		{name: "unknown command", args: "unknown --week -1", want: "unknown --week -1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, strings.Fields(tt.want), joinPeriodOffsets(rootCmd, strings.Fields(tt.args)))
+		})
+	}
+}
+
+func TestReportCmd_PeriodOffset(t *testing.T) {
+	homeDir := t.TempDir()
+	t.Setenv("HOME", homeDir)
+	t.Setenv("XDG_CONFIG_HOME", homeDir)
+	t.Setenv("TIME_TRACKER_PROFILE", "")
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs(joinPeriodOffsets(rootCmd, strings.Fields("report --week -1 --ephemeral -o json")))
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+		ephemeral, outputFlag = false, "text"
+		week := reportCmd.Flags().Lookup("week")
+		_ = week.Value.Set("0")
+		week.Changed = false
+	})
+
+	assert.NoError(t, rootCmd.Execute())
+	offset, err := reportCmd.Flags().GetInt("week")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, offset)
+	assert.True(t, json.Valid(out.Bytes()), "the report should be rendered as json, got %q", out.String())
+}
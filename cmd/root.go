@@ -22,6 +22,8 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/pkg/render"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -36,16 +38,63 @@ var rootCmd = &cobra.Command{
 time-tracker start task1
 time-tracker finish task1
 
-The time spent in between start and finish for project1 will be recorded.`,
+The time spent in between start and finish for project1 will be recorded.
+
+Exit codes:
+  0   success
+  1   internal error, e.g. the database could not be opened
+  2   invalid usage
+  3   task already started
+  4   task not started
+  5   task never completed
+  6   event not found
+  7   event chain broken (see verify)
+  8   wrong passphrase
+  9   invalid config
+  10  profile not found
+  11  profile already exists
+  12  event out of order, e.g. finishing a task before it started
+  13  nothing to invoice
+  14  time can't be invoiced, e.g. it has no rate
+  15  a budget is overrun, with --strict
+
+Settings are read from $XDG_CONFIG_HOME/time-tracker/config.yaml (or ~/.config/time-tracker/config.yaml), and can
+be overridden with TIME_TRACKER_* environment variables, then flags. For example:
+
+db: ~/.time-tracker/time-tracker.db
+time_zone: Europe/London
+week_start: monday
+duration_format: hms
+default_tags: [acme]
+
+Profiles keep separate event stores and settings, e.g. for employed and freelance work. See the profile command.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := parseOutputFormat(); err != nil {
+			return err
+		}
+		return loadSettings()
+	},
+	// Errors are rendered by handleError, in the selected output format
+	SilenceErrors: true,
+	SilenceUsage:  true,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
+	rootCmd.SetArgs(joinPeriodOffsets(rootCmd, os.Args[1:]))
 	err := rootCmd.Execute()
+	os.Exit(handleError(rootCmd.ErrOrStderr(), err))
+}
+
+// parseOutputFormat parses the --output flag into outputFormat.
+func parseOutputFormat() error {
+	format, err := render.ParseFormat(outputFlag)
 	if err != nil {
-		os.Exit(1)
+		return fmt.Errorf("%s: %w", err, errInvalidUsage)
 	}
+	outputFormat = format
+	return nil
 }
 
 func init() {
@@ -53,7 +102,16 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.time-tracker.yaml)")
+	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "config file (default is $XDG_CONFIG_HOME/time-tracker/config.yaml)")
+	rootCmd.PersistentFlags().StringVarP(&profileFlag, "profile", "p", "", "profile to use, with its own event store and settings (default is the active profile)")
+	rootCmd.PersistentFlags().StringVar(&dbFlag, "db", "", "event store file (default is ~/.time-tracker/time-tracker.db)")
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return fmt.Errorf("%s: %w", err, errInvalidUsage)
+	})
+	rootCmd.PersistentFlags().StringVarP(&outputFlag, "output", "o", string(render.FormatText), "output format: text, json, csv, yaml, markdown or html")
+	rootCmd.PersistentFlags().StringVar(&durationFormatFlag, "duration-format", "", "how durations are shown: hms, decimal, human or iso8601 (default is the duration_format setting, or hms)")
+	rootCmd.PersistentFlags().BoolVar(&ephemeral, "ephemeral", false, "keep events in memory only, nothing is saved (for demos and scripting)")
+	rootCmd.PersistentFlags().BoolVar(&encrypt, "encrypt", false, "encrypt task names in the event store with a passphrase, read from $"+passphraseEnv+" or prompted for")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
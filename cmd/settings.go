@@ -0,0 +1,131 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/config"
+	"github.com/danmurf/time-tracker/internal/pkg/durationfmt"
+	"os"
+	"time"
+)
+
+const errInvalidConfig = app.Error("invalid config")
+
+var (
+	// configFlag is the path of the default profile's config file, overriding $TIME_TRACKER_CONFIG and the XDG location.
+	configFlag string
+	// dbFlag is the path of the event store, overriding the configured one.
+	dbFlag string
+	// durationFormatFlag is the style that durations are shown in, overriding the configured one.
+	durationFormatFlag string
+	// profileFlag is the profile to use, overriding $TIME_TRACKER_PROFILE and the active profile.
+	profileFlag string
+	// profiles and profile are set up by openProfiles before any command runs.
+	profiles config.Profiles
+	profile  string
+	// settings are loaded by loadSettings before any command runs.
+	settings config.Config
+	// location is the time zone that times are shown in.
+	location = time.Local
+	// durationStyle is the style that durations are shown in.
+	durationStyle = durationfmt.StyleHMS
+)
+
+// openProfiles finds the profiles, and the one selected with --profile, $TIME_TRACKER_PROFILE or profile use.
+func openProfiles() (homeDir string, err error) {
+	homeDir, err = os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("finding user home directory: %w", err)
+	}
+
+	profiles = config.NewProfiles(config.Path(os.Getenv, homeDir), homeDir)
+	profile = profileFlag
+	if profile == "" {
+		profile = os.Getenv(config.EnvPrefix + "PROFILE")
+	}
+	if profile == "" {
+		if profile, err = profiles.Active(); err != nil {
+			return "", err
+		}
+	}
+
+	return homeDir, nil
+}
+
+// loadSettings is the shared bootstrap for every command. It reads the selected profile's config file and
+// environment variables, then applies any global flags.
+func loadSettings() error {
+	homeDir, err := openProfiles()
+	if err != nil {
+		return err
+	}
+	if err = config.ValidateProfileName(profile); err != nil {
+		return fmt.Errorf("%s: %w", err, errInvalidUsage)
+	}
+	exists, err := profiles.Exists(profile)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return describe(
+			fmt.Errorf("loading profile [%s]: %w", profile, config.ErrProfileNotFound),
+			fmt.Sprintf("👀 there is no %s profile. Run `time-tracker profile create %s` to create it.", profile, profile),
+		)
+	}
+
+	if configFlag != "" && profile != config.DefaultProfile {
+		return fmt.Errorf("--config can't be used with the %s profile, which has its own config file [%s]: %w", profile, profiles.ConfigPath(profile), errInvalidUsage)
+	}
+	getenv := profiles.Getenv(profile, os.Getenv)
+	path := configFlag
+	if path == "" {
+		path = getenv(config.EnvPrefix + "CONFIG")
+	}
+	if path == "" {
+		path = profiles.ConfigPath(profile)
+	}
+
+	loaded, err := config.Load(config.ExpandHome(path, homeDir), profiles.Defaults(profile), getenv, homeDir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", err, errInvalidConfig)
+	}
+	if dbFlag != "" {
+		loaded.DB = config.ExpandHome(dbFlag, homeDir)
+	}
+	if location, err = loaded.Location(); err != nil {
+		return fmt.Errorf("%s: %w", err, errInvalidConfig)
+	}
+	if durationFormatFlag != "" {
+		loaded.DurationFormat = durationFormatFlag
+		if _, err = loaded.DurationStyle(); err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidUsage)
+		}
+	}
+	if durationStyle, err = loaded.DurationStyle(); err != nil {
+		return fmt.Errorf("%s: %w", err, errInvalidConfig)
+	}
+	settings = loaded
+
+	return nil
+}
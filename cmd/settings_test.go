@@ -0,0 +1,89 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"github.com/danmurf/time-tracker/internal/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSettings_Profiles(t *testing.T) {
+	tests := []struct {
+		name       string
+		profile    string
+		configFlag string
+		wantDB     string
+		wantErr    error
+	}{
+		{
+			name:    "default profile uses the overrides",
+			profile: config.DefaultProfile,
+			wantDB:  "/tmp/overridden.db",
+		},
+		{
+			name:    "other profiles ignore the overrides",
+			profile: "work",
+			wantDB:  ".time-tracker/profiles/work.db",
+		},
+		{
+			name:       "config flag with the default profile",
+			profile:    config.DefaultProfile,
+			configFlag: "/tmp/missing-config.yaml",
+			wantDB:     "/tmp/overridden.db",
+		},
+		{
+			name:       "config flag with another profile",
+			profile:    "work",
+			configFlag: "/tmp/missing-config.yaml",
+			wantErr:    errInvalidUsage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			homeDir := t.TempDir()
+			t.Setenv("HOME", homeDir)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(homeDir, ".config"))
+			t.Setenv("TIME_TRACKER_PROFILE", "")
+			t.Setenv("TIME_TRACKER_CONFIG", filepath.Join(homeDir, "overridden.yaml"))
+			t.Setenv("TIME_TRACKER_DB", "/tmp/overridden.db")
+			assert.NoError(t, config.NewProfiles(config.Path(os.Getenv, homeDir), homeDir).Create("work"))
+			profileFlag, configFlag = tt.profile, tt.configFlag
+			t.Cleanup(func() { profileFlag, configFlag, settings = "", "", config.Config{} })
+
+			err := loadSettings()
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected error [%v], got [%v]", tt.wantErr, err)
+				return
+			}
+			assert.NoError(t, err)
+			if filepath.IsAbs(tt.wantDB) {
+				assert.Equal(t, tt.wantDB, settings.DB)
+			} else {
+				assert.Equal(t, filepath.Join(homeDir, tt.wantDB), settings.DB)
+			}
+		})
+	}
+}
@@ -22,15 +22,23 @@ THE SOFTWARE.
 package cmd
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/danmurf/time-tracker/internal/app"
-	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
 	"github.com/danmurf/time-tracker/internal/tasks"
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/cobra"
-	"os"
+	"time"
+)
+
+var (
+	// startTags are the tags given with --tag.
+	startTags []string
+	// startAt is the time given with --at.
+	startAt string
+	// startStrict is whether --strict was given, to refuse to start a task which has used up a budget.
+	startStrict bool
+	// startPomodoro is whether --pomodoro was given, to work on the task in pomodoros.
+	startPomodoro bool
 )
 
 // startCmd represents the start command
@@ -39,56 +47,90 @@ var startCmd = &cobra.Command{
 	Short: "Start working on a task",
 	Long: `Record that you have started working on a specific task, for example:
 
-time-tracker start task1`,
-	Run: func(cmd *cobra.Command, args []string) {
+time-tracker start task1
+
+Tasks can be labelled with tags, e.g. for the client or project, in addition to the default tags from the config
+file:
+
+time-tracker start task1 --tag acme --tag billable
+
+If you forgot to start it at the time, give the time you started with --at, for example:
+
+time-tracker start task1 --at 9:00
+time-tracker start task1 --at -15m
+
+The time left in any estimate or budget for the task is shown, with a warning once it's overrun. Give --strict to
+refuse to start the task instead.
+
+Give --pomodoro to work on the task in pomodoros, with a countdown of each focus and break that rings the terminal
+bell as each one ends, until you press Ctrl+C. Each focus is recorded as a session of the task and each break as the
+pause between them. If it's killed, run the same command to carry on where it left off. Set the lengths in the config
+file:
+
+pomodoro: {focus: 25m, break: 5m, long_break: 15m, long_break_every: 4}`,
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
-			cmd.PrintErrln("command usage is `time-tracker start <task-name>`")
-			os.Exit(1)
+			return fmt.Errorf("command usage is `time-tracker start <task-name>`: %w", errInvalidUsage)
 		}
 
-		homeDir, err := os.UserHomeDir()
+		if startPomodoro && startAt != "" {
+			return fmt.Errorf("--at can't be given with --pomodoro: %w", errInvalidUsage)
+		}
+		at, err := parseAtFlag(startAt)
 		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("finding user home directory: %w", err))
-			os.Exit(1)
+			return err
 		}
 
-		dbPath := fmt.Sprintf("%s/%s", homeDir, ".time-tracker")
-		if err = os.MkdirAll(dbPath, os.ModePerm); err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating time tracker directory [%s]: %w", dbPath, err))
-			os.Exit(1)
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
 		}
 
-		dbFilePath := fmt.Sprintf("%s/%s", dbPath, "time-tracker.db")
-		db, err := sql.Open("sqlite3", dbFilePath)
+		taskName := args[0]
+		budgets, err := checkBudgets(cmd, eventStorage, taskName)
 		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating database: %w", err))
-			os.Exit(1)
+			return err
+		}
+		if over, ok := overBudget(budgets, true); ok && startStrict {
+			return describe(
+				fmt.Errorf("%s [%s]: %w", budgetKind(over.Budget), over.Budget.Task, app.ErrOverBudget),
+				fmt.Sprintf("🚫 %s not started, as there's no time left: %s", taskName, newBudgetView(over).Text()),
+			)
 		}
 
-		eventStorage, err := eventstore.NewSQLEventStore(cmd.Context(), db)
-		if err != nil {
-			cmd.PrintErrln(fmt.Errorf("creating event store: %w", err))
-			os.Exit(1)
+		tags := append(append([]string{}, settings.DefaultTags...), startTags...)
+		if startPomodoro {
+			return runPomodoro(cmd, eventStorage, taskName, tags)
 		}
 
-		starter := tasks.NewStarter(eventStorage, eventStorage)
-		taskName := args[0]
-		err = starter.Start(cmd.Context(), taskName)
+		starter := tasks.NewStarter(eventStorage, eventStorage).WithClock(func() time.Time { return at })
+		err = starter.Start(cmd.Context(), taskName, tags...)
 		switch {
-		case !errors.Is(err, app.ErrTaskAlreadyStarted) && err != nil:
-			cmd.PrintErrln(fmt.Errorf("💥 starting task: %w", err))
-			os.Exit(1)
 		case errors.Is(err, app.ErrTaskAlreadyStarted):
-			cmd.PrintErrln(fmt.Sprintf("👀 %s already in progress", taskName))
-			os.Exit(1)
+			return describe(err, fmt.Sprintf("👀 %s already in progress", taskName))
+		case errors.Is(err, app.ErrEventOutOfOrder):
+			return describe(err, fmt.Sprintf("👀 %s can't start before it last finished", taskName))
+		case err != nil:
+			return fmt.Errorf("starting task: %w", err)
+		}
+
+		started, err := eventStorage.LatestByName(cmd.Context(), taskName)
+		if err != nil {
+			return fmt.Errorf("finding started event: %w", err)
 		}
 
-		cmd.Printf("⏱  %s started. Run `time-tracker finish %s` when you have finished work.\n", taskName, taskName)
+		return output(cmd, newTaskEventView(started, fmt.Sprintf(
+			"⏱  %s started. Run `time-tracker finish %s` when you have finished work.", taskName, taskName,
+		)).withBudgets(budgets))
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().StringVar(&startAt, "at", "", "when the task was started, if not now, "+timeFlagHelp)
+	startCmd.Flags().StringSliceVarP(&startTags, "tag", "t", nil, "tag the task, e.g. with a client or project (can be repeated)")
+	startCmd.Flags().BoolVar(&startStrict, "strict", false, "refuse to start the task if an estimate or budget for it is used up")
+	startCmd.Flags().BoolVar(&startPomodoro, "pomodoro", false, "work on the task in pomodoros, counting down each focus and break until interrupted")
 
 	// Here you will define your flags and configuration settings.
 
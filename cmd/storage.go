@@ -0,0 +1,122 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+	"os"
+	"path/filepath"
+)
+
+// eventStorage is the event store behaviour needed by the commands.
+type eventStorage interface {
+	app.EventStore
+	app.EventFinder
+	app.EventLister
+}
+
+var (
+	// ephemeral keeps all events in memory, so nothing is read from or written to disk.
+	ephemeral bool
+	// encrypt enables encryption of the event store. Once enabled, it stays enabled for that store.
+	encrypt bool
+	// passphrase is remembered once read, so that it is only asked for once when opening several stores.
+	passphrase string
+	// sqliteDriver is the database/sql driver that sqlite event stores are opened with.
+	sqliteDriver = "sqlite3"
+)
+
+// openEventStorage opens the event store selected by the settings and global flags. Unless running ephemerally, this
+// is the configured sqlite database, which is decrypted with the user's passphrase if it is encrypted.
+func openEventStorage(cmd *cobra.Command) (eventStorage, error) {
+	if ephemeral {
+		return eventstore.NewMemoryEventStore(), nil
+	}
+
+	dbPath := filepath.Dir(settings.DB)
+	if err := os.MkdirAll(dbPath, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("creating time tracker directory [%s]: %w", dbPath, err)
+	}
+
+	return openSQLEventStorage(cmd, settings.DB)
+}
+
+// openSQLEventStorage opens the sqlite event store at the given path, which is decrypted with the user's passphrase if
+// it is encrypted.
+func openSQLEventStorage(cmd *cobra.Command, dbFilePath string) (eventStorage, error) {
+	db, err := sql.Open(sqliteDriver, dbFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("creating database: %w", err)
+	}
+
+	eventStorage, err := eventstore.NewSQLEventStore(cmd.Context(), db)
+	if err != nil {
+		return nil, fmt.Errorf("creating event store: %w", err)
+	}
+
+	keyFilePath := fmt.Sprintf("%s.%s", dbFilePath, "key")
+	encrypted, err := encryption.KeyFileExists(keyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if !encrypt && !encrypted {
+		return eventStorage, nil
+	}
+
+	if passphrase == "" {
+		if passphrase, err = readPassphrase(cmd); err != nil {
+			return nil, fmt.Errorf("reading passphrase: %w", err)
+		}
+	}
+	key, err := encryption.LoadOrCreateKey(keyFilePath, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("loading encryption key: %w", err)
+	}
+	cipher, err := encryption.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+
+	encryptedStorage := eventstore.NewEncryptedEventStore(eventStorage, cipher)
+	if !encrypted {
+		// Encryption has just been enabled, so encrypt the events already stored.
+		err = encryptedStorage.EncryptExisting(cmd.Context())
+		switch {
+		case errors.Is(err, eventstore.ErrSpaceNotReclaimed):
+			// The events are encrypted, so the key must be kept.
+			fmt.Fprintf(cmd.ErrOrStderr(), "⚠️  existing events were encrypted, but their plaintext may remain in unused space in %s (%s)\n", dbFilePath, err)
+		case err != nil:
+			// The events weren't rewritten, so the store is left unencrypted and the new key is removed again.
+			_ = os.Remove(keyFilePath)
+			return nil, fmt.Errorf("encrypting existing events: %w", err)
+		}
+	}
+
+	return encryptedStorage, nil
+}
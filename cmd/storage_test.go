@@ -0,0 +1,134 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingVacuumDriver is a sqlite driver which fails to vacuum, as it would with a full disk.
+type failingVacuumDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *failingVacuumDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return failingVacuumConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type failingVacuumConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c failingVacuumConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.HasPrefix(query, "VACUUM") {
+		return nil, errors.New("database or disk is full")
+	}
+	return c.SQLiteConn.ExecContext(ctx, query, args)
+}
+
+func init() {
+	sql.Register("sqlite3-failing-vacuum", &failingVacuumDriver{})
+}
+
+func TestOpenSQLEventStorage_EncryptingExistingEvents(t *testing.T) {
+	tests := []struct {
+		name        string
+		driver      string
+		tamper      bool
+		wantErr     bool
+		wantKey     bool
+		wantWarning bool
+	}{
+		{name: "plaintext store", driver: "sqlite3", wantKey: true},
+		{name: "space not reclaimed", driver: "sqlite3-failing-vacuum", wantKey: true, wantWarning: true},
+		{name: "chain broken", driver: "sqlite3", tamper: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dbFilePath := filepath.Join(t.TempDir(), "time-tracker.db")
+			event := app.Event{
+				ID:        uuid.New(),
+				Type:      app.EventTypeTaskStarted,
+				TaskName:  "acme-website",
+				CreatedAt: time.Now().Truncate(time.Second).UTC(),
+			}
+			db, err := sql.Open("sqlite3", dbFilePath)
+			assert.NoError(t, err)
+			plaintext, err := eventstore.NewSQLEventStore(ctx, db)
+			assert.NoError(t, err)
+			assert.NoError(t, plaintext.Store(ctx, event))
+			if tt.tamper {
+				_, err = db.ExecContext(ctx, "UPDATE event_store SET task_name = 'other-task';")
+				assert.NoError(t, err, "tampering with stored test data")
+			}
+			assert.NoError(t, db.Close())
+
+			sqliteDriver, encrypt, passphrase = tt.driver, true, "secret"
+			t.Cleanup(func() { sqliteDriver, encrypt, passphrase = "sqlite3", false, "" })
+			var stderr bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetContext(ctx)
+			cmd.SetErr(&stderr)
+
+			storage, err := openSQLEventStorage(cmd, dbFilePath)
+			keyExists, keyErr := encryption.KeyFileExists(dbFilePath + ".key")
+			assert.NoError(t, keyErr)
+			assert.Equal(t, tt.wantKey, keyExists, "the key should only be kept if the events were encrypted")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantWarning, strings.Contains(stderr.String(), "plaintext may remain"), "got %q", stderr.String())
+
+			got, err := storage.LatestByName(ctx, "acme-website")
+			assert.NoError(t, err, "existing events should be found once encrypted")
+			assert.Equal(t, event.ID, got.ID)
+			raw, err := os.ReadFile(dbFilePath)
+			assert.NoError(t, err)
+			if !tt.wantWarning {
+				assert.NotContains(t, string(raw), "acme-website", "the plaintext should be removed from the database file")
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/danmurf/time-tracker/internal/pkg/gitsync"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// syncWith is the path to the other event store to sync with.
+	syncWith string
+	// syncGit is the path to a git repository to sync events through.
+	syncGit string
+	// syncAllowPlaintext allows the events of an encrypted store to be synced to a plaintext store or through git, where
+	// they are decrypted.
+	syncAllowPlaintext bool
+)
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Merge events with another time tracker database",
+	Long: `Merge the events in this time tracker with those in another time tracker database, for example one
+copied from another machine. Events are copied both ways, so both databases end up with every event:
+
+time-tracker sync --with /mnt/desktop/.time-tracker/time-tracker.db
+
+If only one of the databases is encrypted, syncing would copy its decrypted events into the other, so it is refused
+unless --allow-plaintext is given. Give --encrypt to encrypt both instead.
+
+Alternatively, sync through a git repository, which is created if it doesn't exist. Events are kept as one
+newline delimited JSON file per day. If the repository has an origin remote, it is pulled from before the sync
+and pushed to afterwards, so every clone of it can sync the same events (in plaintext):
+
+time-tracker sync --git ~/dotfiles/time-tracker
+
+As the events are written to the repository in plaintext, an encrypted event store is only synced through git with
+--allow-plaintext.
+
+Tasks which were started (or finished) on both machines without a finish (or start) in between are reported as
+conflicts.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if (syncWith == "") == (syncGit == "") {
+			return fmt.Errorf(
+				"command usage is `time-tracker sync --with <path-to-other-db>` or `time-tracker sync --git <path-to-repository>`: %w",
+				errInvalidUsage,
+			)
+		}
+
+		local, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		var result app.SyncResult
+		if syncWith != "" {
+			result, err = syncWithDB(cmd, local)
+		} else {
+			result, err = syncWithGit(cmd, local)
+		}
+		if err != nil {
+			return fmt.Errorf("syncing: %w", err)
+		}
+
+		return output(cmd, newSyncView(syncWith+syncGit, result))
+	},
+}
+
+func syncWithDB(cmd *cobra.Command, local eventStorage) (app.SyncResult, error) {
+	if _, err := os.Stat(syncWith); err != nil {
+		return app.SyncResult{}, fmt.Errorf("opening other database: %w", err)
+	}
+	remote, err := openSQLEventStorage(cmd, syncWith)
+	if err != nil {
+		return app.SyncResult{}, err
+	}
+	if isEncrypted(local) != isEncrypted(remote) && !syncAllowPlaintext {
+		return app.SyncResult{}, fmt.Errorf(
+			"only one of the event stores is encrypted, so its events would be copied into the other in plaintext, use --encrypt to encrypt both or --allow-plaintext to sync them anyway: %w",
+			errInvalidUsage,
+		)
+	}
+
+	return tasks.NewSyncer().Sync(cmd.Context(), local, remote)
+}
+
+func syncWithGit(cmd *cobra.Command, local eventStorage) (app.SyncResult, error) {
+	if isEncrypted(local) && !syncAllowPlaintext {
+		return app.SyncResult{}, fmt.Errorf(
+			"the event store is encrypted, but events are synced through git in plaintext, use --allow-plaintext to sync them anyway: %w",
+			errInvalidUsage,
+		)
+	}
+
+	repo, err := gitsync.Open(cmd.Context(), syncGit)
+	if err != nil {
+		return app.SyncResult{}, fmt.Errorf("opening git repository: %w", err)
+	}
+	hasRemote, err := repo.HasRemote(cmd.Context())
+	if err != nil {
+		return app.SyncResult{}, err
+	}
+	if hasRemote {
+		if err = repo.Pull(cmd.Context()); err != nil {
+			return app.SyncResult{}, err
+		}
+	}
+
+	result, err := tasks.NewSyncer().Sync(cmd.Context(), local, repo)
+	if err != nil {
+		return result, err
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown host"
+	}
+	if _, err = repo.Commit(cmd.Context(), fmt.Sprintf("Add %d events from %s", result.CopiedToRemote, hostname)); err != nil {
+		return result, err
+	}
+	if hasRemote {
+		if err = repo.Push(cmd.Context()); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// isEncrypted reports whether the event store encrypts its events.
+func isEncrypted(s eventStorage) bool {
+	_, encrypted := s.(eventstore.EncryptedEventStore)
+	return encrypted
+}
+
+type syncConflictView struct {
+	Task     string    `json:"task" yaml:"task"`
+	Event    string    `json:"event" yaml:"event"`
+	FirstAt  time.Time `json:"first_at" yaml:"first_at"`
+	SecondAt time.Time `json:"second_at" yaml:"second_at"`
+}
+
+// syncView is the output of syncing with another event store.
+type syncView struct {
+	With           string             `json:"with" yaml:"with"`
+	CopiedToLocal  int                `json:"copied_to_local" yaml:"copied_to_local"`
+	CopiedToRemote int                `json:"copied_to_remote" yaml:"copied_to_remote"`
+	Conflicts      []syncConflictView `json:"conflicts" yaml:"conflicts"`
+	// DuplicateInvoiceNumbers are shared by invoices issued in both event stores.
+	DuplicateInvoiceNumbers []string `json:"duplicate_invoice_numbers" yaml:"duplicate_invoice_numbers"`
+}
+
+func newSyncView(with string, result app.SyncResult) syncView {
+	v := syncView{
+		With:                    with,
+		CopiedToLocal:           result.CopiedToLocal,
+		CopiedToRemote:          result.CopiedToRemote,
+		Conflicts:               []syncConflictView{},
+		DuplicateInvoiceNumbers: append([]string{}, result.DuplicateInvoiceNumbers...),
+	}
+	for _, c := range result.Conflicts {
+		v.Conflicts = append(v.Conflicts, syncConflictView{
+			Task:     c.TaskName,
+			Event:    strings.TrimPrefix(string(c.First.Type), "task-"),
+			FirstAt:  c.First.CreatedAt.In(location),
+			SecondAt: c.Second.CreatedAt.In(location),
+		})
+	}
+	return v
+}
+
+func (v syncView) Text() string {
+	lines := []string{fmt.Sprintf("🔄 copied %d events from %s and %d events to it.", v.CopiedToLocal, v.With, v.CopiedToRemote)}
+	for _, c := range v.Conflicts {
+		lines = append(lines, fmt.Sprintf(
+			"⚠️  %s was %s on both sides with nothing in between (at %s and %s).",
+			c.Task, c.Event, c.FirstAt, c.SecondAt,
+		))
+	}
+	if len(v.DuplicateInvoiceNumbers) > 0 {
+		lines = append(lines, duplicateInvoicesWarning(v.DuplicateInvoiceNumbers))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Header is one row per conflict, so that the conflicts can be processed. The copied counts are repeated on each.
+func (v syncView) Header() []string {
+	return []string{"with", "copied_to_local", "copied_to_remote", "duplicate_invoice_numbers", "conflict_task", "conflict_event", "conflict_first_at", "conflict_second_at"}
+}
+
+func (v syncView) Rows() [][]string {
+	counts := []string{v.With, strconv.Itoa(v.CopiedToLocal), strconv.Itoa(v.CopiedToRemote), strings.Join(v.DuplicateInvoiceNumbers, " ")}
+	if len(v.Conflicts) == 0 {
+		return [][]string{append(counts, "", "", "", "")}
+	}
+	var rows [][]string
+	for _, c := range v.Conflicts {
+		row := append(append([]string{}, counts...), c.Task, c.Event, formatTime(c.FirstAt), formatTime(c.SecondAt))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().StringVar(&syncWith, "with", "", "path to the other time tracker database")
+	syncCmd.Flags().StringVar(&syncGit, "git", "", "path to a git repository to sync events through")
+	syncCmd.Flags().BoolVar(&syncAllowPlaintext, "allow-plaintext", false, "sync an encrypted event store with a plaintext one, or through git, which stores events in plaintext")
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyncWithDB_EncryptedWithPlaintext(t *testing.T) {
+	tests := []struct {
+		name           string
+		encryptRemote  bool
+		allowPlaintext bool
+		wantErr        error
+		wantPlaintext  bool
+	}{
+		{name: "plaintext remote", wantErr: errInvalidUsage},
+		{name: "plaintext remote allowed", allowPlaintext: true, wantPlaintext: true},
+		{name: "remote encrypted too", encryptRemote: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			cmd := &cobra.Command{}
+			cmd.SetContext(ctx)
+			encrypt, passphrase = true, "secret"
+			t.Cleanup(func() { encrypt, passphrase, syncWith, syncAllowPlaintext = false, "", "", false })
+
+			local, err := openSQLEventStorage(cmd, filepath.Join(dir, "local.db"))
+			assert.NoError(t, err)
+			assert.NoError(t, local.Store(ctx, app.Event{
+				ID:        uuid.New(),
+				Type:      app.EventTypeTaskStarted,
+				TaskName:  "acme-confidential",
+				CreatedAt: time.Now().Truncate(time.Second).UTC(),
+			}))
+
+			syncWith = filepath.Join(dir, "remote.db")
+			db, err := sql.Open("sqlite3", syncWith)
+			assert.NoError(t, err)
+			_, err = eventstore.NewSQLEventStore(ctx, db)
+			assert.NoError(t, err, "creating the plaintext remote store")
+			assert.NoError(t, db.Close())
+
+			encrypt, syncAllowPlaintext = tt.encryptRemote, tt.allowPlaintext
+			_, err = syncWithDB(cmd, local)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected error [%v], got [%v]", tt.wantErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			raw, err := os.ReadFile(syncWith)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantPlaintext, strings.Contains(string(raw), "acme-confidential"), "decrypted events should only be copied when allowed")
+		})
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/pkg/timeexpr"
+	"time"
+)
+
+// timeFlagHelp describes the time expressions accepted by time flags.
+const timeFlagHelp = "e.g. 17:30, yesterday 17:30, monday, -1h30m, 2026-10-01 or last week"
+
+// parseTimeFlag resolves the time expression given to a flag, relative to now in the configured time zone.
+func parseTimeFlag(flag, expr string, now time.Time) (time.Time, error) {
+	firstDay, err := settings.FirstWeekday()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", err, errInvalidConfig)
+	}
+
+	t, err := timeexpr.Parse(expr, now.In(location), firstDay)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s: %s: %w", flag, err, errInvalidUsage)
+	}
+	return t, nil
+}
+
+// parseAtFlag resolves the time given with --at, which must not be in the future. The time is now if --at isn't
+// given.
+func parseAtFlag(expr string) (time.Time, error) {
+	now := time.Now()
+	if expr == "" {
+		return now, nil
+	}
+
+	at, err := parseTimeFlag("at", expr, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if at.After(now) {
+		return time.Time{}, fmt.Errorf("--at %s is in the future: %w", formatTime(at.In(location)), errInvalidUsage)
+	}
+	return at, nil
+}
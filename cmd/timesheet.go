@@ -0,0 +1,202 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/tasks"
+	"github.com/spf13/cobra"
+	"time"
+)
+
+// timesheetWeek is the offset from this week given with --week.
+var timesheetWeek int
+
+// timesheetCmd represents the timesheet command
+var timesheetCmd = &cobra.Command{
+	Use:   "timesheet",
+	Short: "Show a weekly timesheet of hours per task and day",
+	Long: `Show a grid of the hours spent on each task on each day of a week, with totals for each task and each day.
+Sessions which cross midnight count towards both days. For example:
+
+time-tracker timesheet                        # this week
+time-tracker timesheet --week -1              # last week
+time-tracker timesheet --week -1 -o csv       # for a spreadsheet
+time-tracker timesheet --week -1 -o markdown  # for a wiki or email
+
+Weeks start on the week_start day in the config file, Monday by default. If there are rounding rules in the config
+file, each task has a second row with its rounded durations.`,
+	Args: usageArgs(cobra.NoArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		firstDay, err := settings.FirstWeekday()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+		week := app.WeekPeriod(time.Now().In(location), firstDay, timesheetWeek)
+		rounding, err := settings.RoundingRules()
+		if err != nil {
+			return fmt.Errorf("%s: %w", err, errInvalidConfig)
+		}
+
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		timesheets := tasks.NewTimesheets(tasks.NewSessionCollector(eventStorage), newAbsences(eventStorage), rounding)
+		timesheet, err := timesheets.Timesheet(cmd.Context(), week)
+		if err != nil {
+			return fmt.Errorf("building timesheet: %w", err)
+		}
+
+		return output(cmd, newTimesheetView(timesheet, len(rounding) > 0))
+	},
+}
+
+// timesheetView is the output of a timesheet. Durations are shown in the configured style in text, CSV and Markdown,
+// so that the decimal style can be pasted straight into most timesheets.
+type timesheetView struct {
+	From                time.Time          `json:"from" yaml:"from"`
+	To                  time.Time          `json:"to" yaml:"to"`
+	Days                []string           `json:"days" yaml:"days"`
+	Absences            []absenceView      `json:"absences" yaml:"absences"`
+	Tasks               []timesheetRowView `json:"tasks" yaml:"tasks"`
+	DaySeconds          []float64          `json:"day_seconds" yaml:"day_seconds"`
+	TotalSeconds        float64            `json:"total_seconds" yaml:"total_seconds"`
+	RoundedDaySeconds   []float64          `json:"rounded_day_seconds" yaml:"rounded_day_seconds"`
+	RoundedTotalSeconds float64            `json:"rounded_total_seconds" yaml:"rounded_total_seconds"`
+	days                []time.Time
+	// absent is a label for the absence on each day, or empty if there isn't one.
+	absent           []string
+	dayTotals        []time.Duration
+	total            time.Duration
+	roundedDayTotals []time.Duration
+	roundedTotal     time.Duration
+	// rounding is whether there are rounding rules, so rounded rows are worth showing.
+	rounding bool
+}
+
+type timesheetRowView struct {
+	Task                string    `json:"task" yaml:"task"`
+	DaySeconds          []float64 `json:"day_seconds" yaml:"day_seconds"`
+	TotalSeconds        float64   `json:"total_seconds" yaml:"total_seconds"`
+	RoundedDaySeconds   []float64 `json:"rounded_day_seconds" yaml:"rounded_day_seconds"`
+	RoundedTotalSeconds float64   `json:"rounded_total_seconds" yaml:"rounded_total_seconds"`
+	days                []time.Duration
+	total               time.Duration
+	roundedDays         []time.Duration
+	roundedTotal        time.Duration
+}
+
+func newTimesheetView(t app.Timesheet, rounding bool) timesheetView {
+	v := timesheetView{
+		From:                t.Period.From.In(location),
+		To:                  t.Period.To.In(location),
+		Days:                []string{},
+		Absences:            []absenceView{},
+		Tasks:               []timesheetRowView{},
+		DaySeconds:          seconds(t.DayTotals),
+		TotalSeconds:        t.Total.Seconds(),
+		RoundedDaySeconds:   seconds(t.RoundedDayTotals),
+		RoundedTotalSeconds: t.RoundedTotal.Seconds(),
+		dayTotals:           t.DayTotals,
+		total:               t.Total,
+		roundedDayTotals:    t.RoundedDayTotals,
+		roundedTotal:        t.RoundedTotal,
+		rounding:            rounding,
+	}
+	for _, day := range t.Days {
+		v.Days = append(v.Days, formatDate(day.From))
+		v.days = append(v.days, day.From.In(location))
+	}
+	for _, absence := range t.Absences {
+		label := ""
+		if absence.Kind != "" {
+			a := newAbsenceView(absence)
+			v.Absences = append(v.Absences, a)
+			label = a.label()
+		}
+		v.absent = append(v.absent, label)
+	}
+	for _, row := range t.Rows {
+		v.Tasks = append(v.Tasks, timesheetRowView{
+			Task:                row.TaskName,
+			DaySeconds:          seconds(row.Days),
+			TotalSeconds:        row.Total.Seconds(),
+			RoundedDaySeconds:   seconds(row.RoundedDays),
+			RoundedTotalSeconds: row.RoundedTotal.Seconds(),
+			days:                row.Days,
+			total:               row.Total,
+			roundedDays:         row.RoundedDays,
+			roundedTotal:        row.RoundedTotal,
+		})
+	}
+	return v
+}
+
+func (v timesheetView) Text() string {
+	header := []string{"Task"}
+	for _, day := range v.days {
+		header = append(header, day.Format("Mon 02"))
+	}
+	header = append(header, "Total")
+	return fmt.Sprintf("🗓  %s to %s\n\n%s", formatDate(v.From), formatDate(v.To.AddDate(0, 0, -1)), formatTable(header, v.Rows()))
+}
+
+func (v timesheetView) Header() []string {
+	header := []string{"Task"}
+	for _, day := range v.days {
+		header = append(header, day.Format("Mon 2006-01-02"))
+	}
+	return append(header, "Total")
+}
+
+// Rows returns a row for each task and the totals, each followed by its rounded durations if there are rounding
+// rules, and the absences if there are any.
+func (v timesheetView) Rows() [][]string {
+	var rows [][]string
+	for _, task := range v.Tasks {
+		rows = append(rows, timesheetRow(task.Task, task.days, task.total))
+		if v.rounding {
+			rows = append(rows, timesheetRow(task.Task+" (rounded)", task.roundedDays, task.roundedTotal))
+		}
+	}
+	rows = append(rows, timesheetRow("Total", v.dayTotals, v.total))
+	if v.rounding {
+		rows = append(rows, timesheetRow("Total (rounded)", v.roundedDayTotals, v.roundedTotal))
+	}
+	if len(v.Absences) > 0 {
+		rows = append(rows, append(append([]string{"Absent"}, v.absent...), ""))
+	}
+	return rows
+}
+
+func timesheetRow(label string, days []time.Duration, total time.Duration) []string {
+	return append(append([]string{label}, formatDurations(days...)...), formatDuration(total))
+}
+
+func init() {
+	rootCmd.AddCommand(timesheetCmd)
+	timesheetCmd.Flags().IntVar(&timesheetWeek, "week", 0, "the week, as an offset from this one, e.g. --week -1 for last week")
+	timesheetCmd.Flags().Lookup("week").NoOptDefVal = "0"
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify that no events have been tampered with",
+	Long: `Walk the hash chain of stored events and report the first event which was edited, deleted,
+reordered or inserted directly in the database, for example:
+
+time-tracker verify
+
+Each event is hashed together with the hash of the event before it. The hash of the last event (the chain head)
+is printed when the chain verifies; recording it elsewhere, e.g. alongside a submitted timesheet, also makes it
+possible to detect the removal or rewriting of the most recent events.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		eventStorage, err := openEventStorage(cmd)
+		if err != nil {
+			return err
+		}
+
+		verifier, ok := eventStorage.(app.EventChainVerifier)
+		if !ok {
+			return fmt.Errorf("the event store in use does not support verification")
+		}
+
+		verification, err := verifier.VerifyChain(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("verifying events: %w", err)
+		}
+
+		if b := verification.Break; b != nil {
+			return describe(
+				fmt.Errorf("event %d (%s) failed verification: %s: %w", b.Sequence, b.EventID, b.Reason, app.ErrEventChainBroken),
+				fmt.Sprintf("🚨 event %d (%s) failed verification: %s.", b.Sequence, b.EventID, b.Reason),
+			)
+		}
+
+		return output(cmd, verificationView{Events: verification.Events, Head: verification.Head})
+	},
+}
+
+// verificationView is the output of verifying the event hash chain.
+type verificationView struct {
+	Events int    `json:"events" yaml:"events"`
+	Head   string `json:"head" yaml:"head"`
+}
+
+func (v verificationView) Text() string {
+	return fmt.Sprintf("✅ %d events verified. Chain head: %s", v.Events, v.Head)
+}
+
+func (v verificationView) Header() []string {
+	return []string{"events", "head"}
+}
+
+func (v verificationView) Rows() [][]string {
+	return [][]string{{strconv.Itoa(v.Events), v.Head}}
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright © 2022 Dan Murfitt <dan@murfitt.net>
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// taskEventView is the output of recording that a task was started or finished, with the time left in its budgets.
+type taskEventView struct {
+	Task    string       `json:"task" yaml:"task"`
+	Event   string       `json:"event" yaml:"event"`
+	At      time.Time    `json:"at" yaml:"at"`
+	Tags    []string     `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Budgets []budgetView `json:"budgets,omitempty" yaml:"budgets,omitempty"`
+	text    string
+}
+
+func newTaskEventView(e app.Event, text string) taskEventView {
+	event := strings.TrimPrefix(string(e.Type), "task-")
+	return taskEventView{Task: e.TaskName, Event: event, At: e.CreatedAt.In(location), Tags: e.Tags, text: text}
+}
+
+// withBudgets adds the status of the task's budgets to the view.
+func (v taskEventView) withBudgets(statuses []app.BudgetStatus) taskEventView {
+	for _, s := range statuses {
+		v.Budgets = append(v.Budgets, newBudgetView(s))
+	}
+	return v
+}
+
+func (v taskEventView) Text() string {
+	text := v.text
+	for _, b := range v.Budgets {
+		icon := "⏳"
+		if b.Over {
+			icon = "⚠️ "
+		}
+		text += fmt.Sprintf("\n%s %s", icon, b.Text())
+	}
+	return text
+}
+
+func (v taskEventView) Header() []string {
+	return []string{"task", "event", "at", "tags"}
+}
+
+func (v taskEventView) Rows() [][]string {
+	return [][]string{{v.Task, v.Event, formatTime(v.At), strings.Join(v.Tags, ",")}}
+}
+
+// duplicateInvoicesWarning warns that invoices were issued with the same number in separate event stores.
+func duplicateInvoicesWarning(numbers []string) string {
+	return fmt.Sprintf(
+		"⚠️  invoice numbers %s were issued in both event stores, so they are now shared by more than one invoice. Issue invoices from a single store.",
+		strings.Join(numbers, ", "),
+	)
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', -1, 64)
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// formatPeriod formats a period of whole days as its first and last dates, or any other period as its first and last
+// times.
+func formatPeriod(from, to time.Time) string {
+	if !isMidnight(from) || !isMidnight(to) {
+		return fmt.Sprintf("%s %s to %s %s", formatDate(from), from.Format("15:04"), formatDate(to), to.Format("15:04"))
+	}
+	period := formatDate(from)
+	if last := formatDate(to.AddDate(0, 0, -1)); last != period {
+		period = fmt.Sprintf("%s to %s", period, last)
+	}
+	return period
+}
+
+func isMidnight(t time.Time) bool {
+	return t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0
+}
+
+// formatDuration formats a duration in the configured style, e.g. 01:30:00 or 1.50h.
+func formatDuration(d time.Duration) string {
+	return durationStyle.Format(d)
+}
+
+// formatDurations formats each duration in the configured style.
+func formatDurations(durations ...time.Duration) []string {
+	formatted := make([]string, len(durations))
+	for i, d := range durations {
+		formatted[i] = formatDuration(d)
+	}
+	return formatted
+}
+
+// formatTable lines up the columns of a table for text output.
+func formatTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+func seconds(durations []time.Duration) []float64 {
+	s := make([]float64, len(durations))
+	for i, d := range durations {
+		s[i] = d.Seconds()
+	}
+	return s
+}
+
+// formatMoney formats an amount of money to two decimal places, e.g. 120.00.
+func formatMoney(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// formatAmount formats an amount of money with its currency, e.g. 120.00 EUR.
+func formatAmount(amount float64, currency string) string {
+	return formatMoney(amount) + " " + currency
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	EventTypeAbsenceAdded   = EventType("absence-added")
+	EventTypeAbsenceRemoved = EventType("absence-removed")
+
+	ErrInvalidAbsence = Error("invalid absence")
+)
+
+// AbsenceKind is why a day wasn't worked.
+type AbsenceKind string
+
+const (
+	Vacation = AbsenceKind("vacation")
+	Sick     = AbsenceKind("sick")
+	Holiday  = AbsenceKind("holiday")
+)
+
+// AbsenceKinds lists every kind of absence.
+var AbsenceKinds = []AbsenceKind{Vacation, Sick, Holiday}
+
+// Absence is a day, or half a day, which wasn't worked, such as a vacation day or a public holiday.
+type Absence struct {
+	// Date is the day of the absence, at midnight UTC. It's the same calendar day wherever it's seen from.
+	Date time.Time
+	Kind AbsenceKind
+	Half bool
+	// Note is what the absence is for, e.g. the name of a public holiday.
+	Note string
+	// AddedAt is when the absence was added. It replaces any absence added earlier on the same day.
+	AddedAt time.Time
+}
+
+// Date returns the calendar day containing t, in t's location, at midnight UTC, as absences are dated.
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Fraction is how much of the day the absence is for: 1 for a whole day or 0.5 for half a day.
+func (a Absence) Fraction() float64 {
+	if a.Half {
+		return 0.5
+	}
+	return 1
+}
+
+// Absences is every absence which has been added, one for each day at most.
+type Absences []Absence
+
+// On returns the absence on the day containing t, in t's location. The second return value is false if there isn't
+// one.
+func (as Absences) On(t time.Time) (Absence, bool) {
+	date := Date(t)
+	for _, a := range as {
+		if a.Date.Equal(date) {
+			return a, true
+		}
+	}
+	return Absence{}, false
+}
+
+// Excused returns the part of the time to be worked on the day containing t which the absence that day excuses.
+func (as Absences) Excused(t time.Time, hours time.Duration) time.Duration {
+	a, ok := as.On(t)
+	if !ok {
+		return 0
+	}
+	return time.Duration(float64(hours) * a.Fraction())
+}
+
+// AbsenceAdder is used to add absences, replacing any others on the same days. It returns ErrInvalidAbsence if an
+// absence can't be used.
+type AbsenceAdder interface {
+	AddAbsences(ctx context.Context, absences ...Absence) error
+}
+
+// AbsenceRemover is used to remove the absences on days.
+type AbsenceRemover interface {
+	RemoveAbsences(ctx context.Context, dates ...time.Time) error
+}
+
+// AbsenceLister is used to list the absences, oldest first.
+type AbsenceLister interface {
+	Absences(ctx context.Context) (Absences, error)
+}
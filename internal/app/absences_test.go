@@ -0,0 +1,37 @@
+package app_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAbsences_Excused(t *testing.T) {
+	date := func(day int) time.Time {
+		return time.Date(2026, 12, day, 0, 0, 0, 0, time.UTC)
+	}
+	absences := app.Absences{
+		{Date: date(24), Kind: app.Vacation, Half: true},
+		{Date: date(25), Kind: app.Holiday},
+	}
+	// 23:30 in New York on the 24th is the 25th in UTC, but the absence is on the calendar day it's seen on.
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Duration
+	}{
+		{name: "whole day", at: date(25).Add(15 * time.Hour), want: 8 * time.Hour},
+		{name: "half day", at: date(24).Add(9 * time.Hour), want: 4 * time.Hour},
+		{name: "no absence", at: date(23).Add(9 * time.Hour), want: 0},
+		{name: "other location", at: time.Date(2026, 12, 24, 23, 30, 0, 0, newYork), want: 4 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, absences.Excused(tt.at, 8*time.Hour))
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	EventTypeBudgetSet = EventType("budget-set")
+
+	ErrInvalidBudget = Error("invalid budget")
+	ErrOverBudget    = Error("over budget")
+)
+
+// Budget is the time that can be spent on a task, such as an estimate for the task, or on every task with a name
+// prefix, such as the budget for a fixed-price project.
+type Budget struct {
+	// Task is the name of the task the budget is for, or a prefix followed by *, e.g. acme-*.
+	Task     string
+	Duration time.Duration
+	// SetAt is when the budget was set. It replaces any budget set earlier for the same task or prefix.
+	SetAt time.Time
+}
+
+// Matches reports whether time spent on the task counts towards the budget.
+func (b Budget) Matches(taskName string) bool {
+	return matches(b.Task, "", taskName, nil)
+}
+
+// IsPrefix reports whether the budget is for every task with a name prefix, rather than an estimate for one task.
+func (b Budget) IsPrefix() bool {
+	return strings.HasSuffix(b.Task, "*")
+}
+
+// BudgetStatus is the time spent against a budget so far, including any sessions still in progress.
+type BudgetStatus struct {
+	Budget   Budget
+	Sessions int
+	Spent    time.Duration
+}
+
+// Remaining is the time left in the budget, which is negative once it's overrun.
+func (s BudgetStatus) Remaining() time.Duration {
+	return s.Budget.Duration - s.Spent
+}
+
+// Over reports whether more time has been spent than the budget allows.
+func (s BudgetStatus) Over() bool {
+	return s.Spent > s.Budget.Duration
+}
+
+// BudgetSetter is used to set the budget for a task or for tasks with a name prefix. A budget of zero removes it. It
+// returns ErrInvalidBudget if the budget can't be used.
+type BudgetSetter interface {
+	SetBudget(ctx context.Context, budget Budget) error
+}
+
+// BudgetChecker is used to check the time spent against budgets.
+type BudgetChecker interface {
+	// Statuses returns the status of every budget, sorted by task.
+	Statuses(ctx context.Context) ([]BudgetStatus, error)
+	// StatusesFor returns the status of the budgets which the time spent on a task counts towards, the task's own
+	// estimate first and then prefixes from the longest.
+	StatusesFor(ctx context.Context, taskName string) ([]BudgetStatus, error)
+}
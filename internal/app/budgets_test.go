@@ -0,0 +1,37 @@
+package app_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBudget_Matches(t *testing.T) {
+	tests := []struct {
+		budget   string
+		taskName string
+		want     bool
+	}{
+		{budget: "acme-web", taskName: "acme-web", want: true},
+		{budget: "acme-web", taskName: "acme-web-shop", want: false},
+		{budget: "acme-*", taskName: "acme-web", want: true},
+		{budget: "acme-*", taskName: "acme", want: false},
+		{budget: "acme-*", taskName: "globex-web", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.budget+" "+tt.taskName, func(t *testing.T) {
+			assert.Equal(t, tt.want, app.Budget{Task: tt.budget}.Matches(tt.taskName))
+		})
+	}
+}
+
+func TestBudgetStatus_Remaining(t *testing.T) {
+	status := app.BudgetStatus{Budget: app.Budget{Task: "acme-*", Duration: 8 * time.Hour}, Spent: 8 * time.Hour}
+	assert.Equal(t, time.Duration(0), status.Remaining())
+	assert.False(t, status.Over(), "using the whole budget isn't going over it")
+
+	status.Spent += time.Minute
+	assert.Equal(t, -time.Minute, status.Remaining())
+	assert.True(t, status.Over())
+}
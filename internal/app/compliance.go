@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// BreakRule requires breaks adding up to at least Minimum on a day with more than After of work.
+type BreakRule struct {
+	After   time.Duration
+	Minimum time.Duration
+}
+
+// WorkingTimeRules are the limits on working time which the compliance report checks, such as those of the EU Working
+// Time Directive. A limit of zero isn't checked.
+type WorkingTimeRules struct {
+	Breaks []BreakRule
+	// MaxDaily is the most time that may be worked on a day.
+	MaxDaily time.Duration
+	// MinRest is the least time between finishing work on one day and starting it on the next.
+	MinRest time.Duration
+}
+
+// ViolationKind is which working-time rule was broken.
+type ViolationKind string
+
+const (
+	ViolationBreak    = ViolationKind("break")
+	ViolationMaxDaily = ViolationKind("max-daily")
+	ViolationRest     = ViolationKind("rest")
+)
+
+// Violation is a working-time rule broken on a day: Actual is the break, work or rest time taken and Limit is what the
+// rule allows or requires. After is the work after which a break was required, for break violations.
+type Violation struct {
+	Kind   ViolationKind
+	Actual time.Duration
+	Limit  time.Duration
+	After  time.Duration
+}
+
+// WorkDay is the work started on a day, which is checked against the working-time rules. Sessions count towards the
+// day they started on, even if they carry on past midnight, and overlapping sessions are only counted once.
+type WorkDay struct {
+	Day      Period
+	Started  time.Time
+	Finished time.Time
+	Worked   time.Duration
+	// Breaks is the time between sessions from starting to finishing.
+	Breaks time.Duration
+	// Rest is the time since work last finished before the day started, or zero if none was done the day before.
+	Rest       time.Duration
+	Violations []Violation
+}
+
+// Compliance is each day worked in a period checked against the working-time rules.
+type Compliance struct {
+	Period Period
+	Rules  WorkingTimeRules
+	Days   []WorkDay
+}
+
+// Violations counts the rules broken in the period.
+func (c Compliance) Violations() int {
+	var violations int
+	for _, d := range c.Days {
+		violations += len(d.Violations)
+	}
+	return violations
+}
+
+// ComplianceChecker is used to check the sessions in a period, including any in progress, against working-time rules.
+type ComplianceChecker interface {
+	Compliance(ctx context.Context, period Period) (Compliance, error)
+}
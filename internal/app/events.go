@@ -10,7 +10,8 @@ const (
 	EventTypeTaskStarted  = EventType("task-started")
 	EventTypeTaskFinished = EventType("task-finished")
 
-	ErrEventNotFound = Error("event not found")
+	ErrEventNotFound    = Error("event not found")
+	ErrEventChainBroken = Error("event chain broken")
 )
 
 type EventType string
@@ -21,6 +22,11 @@ type Event struct {
 	Type      EventType
 	TaskName  string
 	CreatedAt time.Time
+	// Tags label the task, e.g. with a client or project, so that tasks can be grouped together.
+	Tags []string
+	// Data holds the details of events which aren't about starting or finishing a task, such as setting a rate. Those
+	// events have no TaskName, so they are never found by name.
+	Data map[string]string
 }
 
 //go:generate mockery --name=EventStore
@@ -36,3 +42,30 @@ type EventFinder interface {
 	LatestByName(ctx context.Context, taskName string) (Event, error)
 	LatestByNameType(ctx context.Context, taskName string, eventType EventType) (Event, error)
 }
+
+// EventLister is used to list every stored event, newest first.
+type EventLister interface {
+	FetchAll(ctx context.Context) ([]Event, error)
+}
+
+// ChainBreak describes the first event at which a tamper-evident event log no longer verifies.
+type ChainBreak struct {
+	Sequence int64
+	EventID  string
+	Reason   string
+}
+
+// ChainVerification is the result of walking a tamper-evident event log. Head is the hash of the last event in the
+// chain, which can be recorded elsewhere to detect later removal of the most recent events. Break is nil if the whole
+// chain verified.
+type ChainVerification struct {
+	Events int
+	Head   string
+	Break  *ChainBreak
+}
+
+// EventChainVerifier is implemented by event stores which chain each event to the previous one with a hash, so that
+// events edited, deleted or reordered outside the application can be detected.
+type EventChainVerifier interface {
+	VerifyChain(ctx context.Context) (ChainVerification, error)
+}
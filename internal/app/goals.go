@@ -0,0 +1,75 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	EventTypeGoalsSet = EventType("goals-set")
+
+	ErrInvalidGoal = Error("invalid goal")
+)
+
+// Goals are the hours that should be tracked each day and each week. A goal of zero isn't set.
+type Goals struct {
+	Daily  time.Duration
+	Weekly time.Duration
+	// SetAt is when the goals were set. They replace any goals set earlier.
+	SetAt time.Time
+}
+
+// GoalProgress is the time tracked in a period against a goal for it, including any sessions still in progress.
+type GoalProgress struct {
+	Period Period
+	Goal   time.Duration
+	// Excused is the part of the goal which absences in the period excuse.
+	Excused time.Duration
+	Tracked time.Duration
+}
+
+// Target is the time to track to reach the goal once absences are taken off it.
+func (p GoalProgress) Target() time.Duration {
+	if p.Excused >= p.Goal {
+		return 0
+	}
+	return p.Goal - p.Excused
+}
+
+// Remaining is the time still to track to reach the goal, or zero once it's reached.
+func (p GoalProgress) Remaining() time.Duration {
+	if p.Tracked >= p.Target() {
+		return 0
+	}
+	return p.Target() - p.Tracked
+}
+
+// Progress is the time tracked today and this week against the goals, at a time.
+type Progress struct {
+	At    time.Time
+	Goals Goals
+	Day   GoalProgress
+	Week  GoalProgress
+	// Absences are the absences in the week.
+	Absences Absences
+	// Running are the names of the tasks in progress.
+	Running []string
+	// ProjectedFinish is when today's goal will be reached if work carries on from At without a break. It's zero if
+	// there's no daily goal or it's already reached.
+	ProjectedFinish time.Time
+}
+
+// GoalSetter is used to set the daily and weekly goals. It returns ErrInvalidGoal if they can't be used.
+type GoalSetter interface {
+	SetGoals(ctx context.Context, goals Goals) error
+}
+
+// GoalLister is used to find the goals which are set.
+type GoalLister interface {
+	Goals(ctx context.Context) (Goals, error)
+}
+
+// ProgressTracker is used to work out the progress towards the goals at a time, with days and weeks in its location.
+type ProgressTracker interface {
+	Progress(ctx context.Context, at time.Time) (Progress, error)
+}
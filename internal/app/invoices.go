@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"math"
+	"time"
+)
+
+const (
+	EventTypeInvoiceCreated = EventType("invoice-created")
+
+	ErrNothingToInvoice = Error("nothing to invoice")
+	ErrCannotInvoice    = Error("time can't be invoiced")
+)
+
+// InvoiceRequest selects the time to invoice: the completed sessions which started in the period, on tasks matching
+// Task or with Tag, which haven't already been invoiced.
+type InvoiceRequest struct {
+	// Client is who the invoice is for, as shown on it.
+	Client string
+	// Task is the name of a task, or a prefix followed by *, e.g. acme-*. It's empty if the invoice is for a tag.
+	Task   string
+	Tag    string
+	Period Period
+	// DryRun drafts the invoice without recording it, so its number and sessions aren't used up.
+	DryRun bool
+}
+
+// Matches reports whether a session of the task with the tags should be invoiced.
+func (r InvoiceRequest) Matches(taskName string, tags []string) bool {
+	return matches(r.Task, r.Tag, taskName, tags)
+}
+
+// InvoiceLine is the time spent on a task at a rate. Quantity is the rounded time in hours, to two decimal places,
+// and Amount is the quantity multiplied by the rate, to the nearest cent.
+type InvoiceLine struct {
+	TaskName string
+	Rate     Rate
+	Sessions int
+	Duration time.Duration
+	Rounded  time.Duration
+	Quantity float64
+	Amount   float64
+}
+
+// Invoice is a numbered bill for the time spent on a client's tasks, in a single currency. Sessions lists the IDs of
+// the sessions it includes, so that they aren't invoiced again.
+type Invoice struct {
+	Number   string
+	Client   string
+	Task     string
+	Tag      string
+	Period   Period
+	IssuedAt time.Time
+	Currency string
+	Lines    []InvoiceLine
+	Total    float64
+	Sessions []uuid.UUID
+}
+
+// Quantity is the total rounded time on the invoice in hours.
+func (i Invoice) Quantity() float64 {
+	var quantity float64
+	for _, l := range i.Lines {
+		quantity += l.Quantity
+	}
+	return RoundCents(quantity)
+}
+
+// RoundCents rounds an amount to two decimal places, halfway amounts away from zero.
+func RoundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// Invoicer is used to invoice a client for time which hasn't been invoiced yet. It returns ErrNothingToInvoice if
+// there is no such time, and ErrCannotInvoice if some of it has no rate or the rates are in different currencies.
+type Invoicer interface {
+	Invoice(ctx context.Context, request InvoiceRequest) (Invoice, error)
+}
+
+// InvoiceLister is used to list the invoices that have been recorded, oldest first.
+type InvoiceLister interface {
+	Invoices(ctx context.Context) ([]Invoice, error)
+}
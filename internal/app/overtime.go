@@ -0,0 +1,78 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+const ErrNoContract = Error("no contract")
+
+// Contract is the hours that should be worked on each day of the week, from a start date.
+type Contract struct {
+	// Start is the first day of the contract, at midnight.
+	Start time.Time
+	// Hours is the time to work on each day of the week, indexed by time.Weekday.
+	Hours [7]time.Duration
+}
+
+// IsZero reports whether no contract has been set.
+func (c Contract) IsZero() bool {
+	return c.Start.IsZero()
+}
+
+// Weekly is the time to work in a full week.
+func (c Contract) Weekly() time.Duration {
+	var weekly time.Duration
+	for _, d := range c.Hours {
+		weekly += d
+	}
+	return weekly
+}
+
+// Expected is the time to work on the day containing t, which is zero before the contract starts.
+func (c Contract) Expected(t time.Time) time.Duration {
+	if c.IsZero() || DayPeriod(t, 0).From.Before(DayPeriod(c.Start.In(t.Location()), 0).From) {
+		return 0
+	}
+	return c.Hours[t.Weekday()]
+}
+
+// Granularity is the length of the periods that a report is broken down into.
+type Granularity string
+
+const (
+	ByWeek  = Granularity("week")
+	ByMonth = Granularity("month")
+)
+
+// OvertimePeriod is the time worked in a period compared with the time that should have been worked.
+type OvertimePeriod struct {
+	Period Period
+	// Expected is the contracted time, less the time excused by absences.
+	Expected time.Duration
+	// Absent is the contracted time excused by absences.
+	Absent time.Duration
+	Worked time.Duration
+	// Balance is the running balance at the end of the period, since the contract started.
+	Balance time.Duration
+}
+
+// Difference is the overtime in the period, which is negative if less time was worked than expected.
+func (p OvertimePeriod) Difference() time.Duration {
+	return p.Worked - p.Expected
+}
+
+// Overtime is the running overtime or flexitime balance from the start of a contract up to a time, including the
+// whole of that day and any sessions still in progress.
+type Overtime struct {
+	Contract Contract
+	At       time.Time
+	Periods  []OvertimePeriod
+	Balance  time.Duration
+}
+
+// OvertimeCalculator is used to work out the overtime balance at a time, broken down by week or month. It returns
+// ErrNoContract if there is no contract.
+type OvertimeCalculator interface {
+	Overtime(ctx context.Context, at time.Time, by Granularity) (Overtime, error)
+}
@@ -0,0 +1,37 @@
+package app_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestContract_Expected(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	assert.NoError(t, err)
+	contract := app.Contract{Start: time.Date(2022, 6, 1, 0, 0, 0, 0, london)}
+	contract.Hours[time.Monday] = 8 * time.Hour
+	contract.Hours[time.Wednesday] = 7*time.Hour + 30*time.Minute
+	contract.Hours[time.Friday] = 4 * time.Hour
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want time.Duration
+	}{
+		{name: "before the contract starts", t: time.Date(2022, 5, 30, 12, 0, 0, 0, london), want: 0},
+		{name: "the first day", t: time.Date(2022, 6, 1, 0, 0, 0, 0, london), want: 7*time.Hour + 30*time.Minute},
+		{name: "a day off", t: time.Date(2022, 6, 2, 12, 0, 0, 0, london), want: 0},
+		{name: "a short day", t: time.Date(2022, 6, 3, 23, 0, 0, 0, london), want: 4 * time.Hour},
+		{name: "in another location", t: time.Date(2022, 6, 6, 1, 0, 0, 0, time.FixedZone("", -3600)), want: 8 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, contract.Expected(tt.t))
+		})
+	}
+
+	assert.Equal(t, 19*time.Hour+30*time.Minute, contract.Weekly())
+	assert.Equal(t, time.Duration(0), app.Contract{}.Expected(time.Date(2022, 6, 6, 12, 0, 0, 0, london)))
+}
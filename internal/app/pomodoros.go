@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// PomodoroSettings are the lengths of the focus and break intervals of pomodoros. Every LongBreakEvery pomodoros
+// completed in a day are followed by a long break, unless it's zero.
+type PomodoroSettings struct {
+	Focus          time.Duration
+	Break          time.Duration
+	LongBreak      time.Duration
+	LongBreakEvery int
+}
+
+// BreakAfter is the length of the break after the pomodoro which makes completed in the day.
+func (s PomodoroSettings) BreakAfter(completed int) time.Duration {
+	if s.LongBreakEvery > 0 && s.LongBreak > 0 && completed > 0 && completed%s.LongBreakEvery == 0 {
+		return s.LongBreak
+	}
+	return s.Break
+}
+
+// PomodoroPhase is what a pomodoro timer is doing.
+type PomodoroPhase string
+
+const (
+	PomodoroFocus   = PomodoroPhase("focus")
+	PomodoroBreak   = PomodoroPhase("break")
+	PomodoroStopped = PomodoroPhase("stopped")
+)
+
+// Pomodoro is the phase a pomodoro timer for a task is in at a time.
+type Pomodoro struct {
+	TaskName string
+	Phase    PomodoroPhase
+	// Started and Ends are when the focus or break started and when it ends. They're zero once stopped.
+	Started time.Time
+	Ends    time.Time
+	// Completed counts the pomodoros completed in the day, on any task.
+	Completed int
+}
+
+// PomodoroTimer is used to work on a task in focus intervals, recorded as sessions, with breaks between them. The
+// phase is worked out from the recorded events, so a timer can be resumed after it stops unexpectedly.
+type PomodoroTimer interface {
+	// Start starts focusing on the task, or resumes a pomodoro already in progress for it. It returns
+	// ErrTaskAlreadyStarted if the task was started without one.
+	Start(ctx context.Context, taskName string, tags ...string) (Pomodoro, error)
+	// Advance finishes a focus which is up, starting a break, and starts the next focus once a break is over. The
+	// pomodoro is stopped if the task was finished some other way.
+	Advance(ctx context.Context, taskName string, tags ...string) (Pomodoro, error)
+	// Stop finishes a focus in progress, which only counts as completed if its time was already up.
+	Stop(ctx context.Context, taskName string) (Pomodoro, error)
+}
@@ -0,0 +1,16 @@
+package app_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPomodoroSettings_BreakAfter(t *testing.T) {
+	settings := app.PomodoroSettings{Break: 5 * time.Minute, LongBreak: 15 * time.Minute, LongBreakEvery: 4}
+	for completed, want := range map[int]time.Duration{1: 5 * time.Minute, 4: 15 * time.Minute, 5: 5 * time.Minute, 8: 15 * time.Minute} {
+		assert.Equal(t, want, settings.BreakAfter(completed))
+	}
+	assert.Equal(t, 5*time.Minute, app.PomodoroSettings{Break: 5 * time.Minute}.BreakAfter(4), "there shouldn't be long breaks without a long break length")
+}
@@ -0,0 +1,121 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	EventTypeRateSet = EventType("rate-set")
+
+	ErrInvalidRate = Error("invalid rate")
+)
+
+// Rate is the amount charged per hour for the time spent on a task, on tasks with a name prefix, or on tasks with a
+// tag, from a date.
+type Rate struct {
+	// Task is the name of the task the rate is for, or a prefix followed by *, e.g. acme-*. It's empty if the rate is
+	// for a tag.
+	Task     string
+	Tag      string
+	Amount   float64
+	Currency string
+	// EffectiveFrom is when the rate starts to apply. If it's zero, the rate applies to all time.
+	EffectiveFrom time.Time
+	// SetAt is when the rate was set. It replaces any rate set earlier for the same task or tag from the same time.
+	SetAt time.Time
+}
+
+// Matches reports whether the rate applies to a session of the task with the tags, at any time.
+func (r Rate) Matches(taskName string, tags []string) bool {
+	return matches(r.Task, r.Tag, taskName, tags)
+}
+
+// specificity ranks rates for the same session: a task's own rate beats a prefix, a longer prefix beats a shorter
+// one, and any of them beats a tag.
+func (r Rate) specificity() int {
+	switch {
+	case r.Task == "":
+		return 0
+	case strings.HasSuffix(r.Task, "*"):
+		return len(r.Task)
+	default:
+		return 1 << 30
+	}
+}
+
+// Rates is every rate that has been set.
+type Rates []Rate
+
+// For returns the rate for a session of the task with the tags which started at the time. This is the most specific
+// matching rate in effect at the time, and if there are several, the one which took effect most recently. The second
+// return value is false if there is no rate for the session.
+func (rs Rates) For(taskName string, tags []string, at time.Time) (Rate, bool) {
+	var (
+		best  Rate
+		found bool
+	)
+	for _, r := range rs {
+		if !r.Matches(taskName, tags) || r.EffectiveFrom.After(at) {
+			continue
+		}
+		if !found || r.beats(best) {
+			best, found = r, true
+		}
+	}
+	return best, found
+}
+
+func (r Rate) beats(other Rate) bool {
+	switch {
+	case r.specificity() != other.specificity():
+		return r.specificity() > other.specificity()
+	case !r.EffectiveFrom.Equal(other.EffectiveFrom):
+		return r.EffectiveFrom.After(other.EffectiveFrom)
+	default:
+		return r.SetAt.After(other.SetAt)
+	}
+}
+
+// RateSetter is used to set the rate for a task, for tasks with a name prefix, or for tasks with a tag. It returns
+// ErrInvalidRate if the rate can't be used.
+type RateSetter interface {
+	SetRate(ctx context.Context, rate Rate) error
+}
+
+// RateLister is used to list the rates that have been set, leaving out any which have been replaced.
+type RateLister interface {
+	Rates(ctx context.Context) (Rates, error)
+}
+
+// EarningsLine is the time spent on a task at a rate, and the amount earned. Time which has no rate has a zero Rate.
+type EarningsLine struct {
+	TaskName string
+	Rate     Rate
+	Sessions int
+	Duration time.Duration
+	Amount   float64
+}
+
+// CurrencyTotal is the total time and amount earned in a currency.
+type CurrencyTotal struct {
+	Currency string
+	Duration time.Duration
+	Amount   float64
+}
+
+// Earnings is the amount earned by the completed sessions in a period, at the rate in effect when each started.
+// Sessions which started before or finished after the period only count the time within it.
+type Earnings struct {
+	Period     Period
+	Lines      []EarningsLine
+	Currencies []CurrencyTotal
+	// Unrated is the time which has no rate.
+	Unrated time.Duration
+}
+
+// EarningsCalculator is used to work out the amount earned in a period.
+type EarningsCalculator interface {
+	Earnings(ctx context.Context, period Period) (Earnings, error)
+}
@@ -0,0 +1,45 @@
+package app_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRates_For(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2022, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	rates := app.Rates{
+		{Tag: "acme", Amount: 100, Currency: "EUR", SetAt: date(1, 1)},
+		{Task: "acme-*", Amount: 110, Currency: "EUR", SetAt: date(1, 1)},
+		{Task: "acme-web-*", Amount: 115, Currency: "EUR", SetAt: date(1, 1)},
+		{Task: "acme-web-shop", Amount: 120, Currency: "EUR", SetAt: date(1, 1)},
+		{Task: "acme-web-shop", Amount: 130, Currency: "EUR", EffectiveFrom: date(6, 1), SetAt: date(5, 1)},
+		{Task: "acme-web-shop", Amount: 135, Currency: "EUR", EffectiveFrom: date(6, 1), SetAt: date(5, 2)},
+		{Tag: "globex", Amount: 90, Currency: "USD", SetAt: date(1, 1)},
+	}
+	tests := []struct {
+		name     string
+		taskName string
+		tags     []string
+		at       time.Time
+		want     float64
+	}{
+		{name: "tag", taskName: "support", tags: []string{"acme"}, at: date(3, 1), want: 100},
+		{name: "prefix beats tag", taskName: "acme-api", tags: []string{"acme"}, at: date(3, 1), want: 110},
+		{name: "longer prefix", taskName: "acme-web-blog", at: date(3, 1), want: 115},
+		{name: "task beats prefix", taskName: "acme-web-shop", at: date(3, 1), want: 120},
+		{name: "later effective date", taskName: "acme-web-shop", at: date(6, 1), want: 135},
+		{name: "other tag", taskName: "support", tags: []string{"globex"}, at: date(3, 1), want: 90},
+		{name: "no rate", taskName: "support", at: date(3, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rates.For(tt.taskName, tt.tags, tt.at)
+			assert.Equal(t, tt.want != 0, ok)
+			assert.Equal(t, tt.want, got.Amount)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Period is a span of time, from the start of From up to but not including To.
+type Period struct {
+	From time.Time
+	To   time.Time
+}
+
+// DayPeriod returns the day containing t, moved by offset days, e.g. -1 for the day before. Days start at midnight
+// in t's location.
+func DayPeriod(t time.Time, offset int) Period {
+	from := time.Date(t.Year(), t.Month(), t.Day()+offset, 0, 0, 0, 0, t.Location())
+	return Period{From: from, To: from.AddDate(0, 0, 1)}
+}
+
+// WeekPeriod returns the week containing t, moved by offset weeks, with weeks starting on firstDay.
+func WeekPeriod(t time.Time, firstDay time.Weekday, offset int) Period {
+	sinceFirstDay := (int(t.Weekday()) - int(firstDay) + 7) % 7
+	from := time.Date(t.Year(), t.Month(), t.Day()-sinceFirstDay+7*offset, 0, 0, 0, 0, t.Location())
+	return Period{From: from, To: from.AddDate(0, 0, 7)}
+}
+
+// MonthPeriod returns the calendar month containing t, moved by offset months.
+func MonthPeriod(t time.Time, offset int) Period {
+	from := time.Date(t.Year(), t.Month()+time.Month(offset), 1, 0, 0, 0, 0, t.Location())
+	return Period{From: from, To: from.AddDate(0, 1, 0)}
+}
+
+// Session is a single period of work on a task, from a task started event to the task finished event which follows
+// it. A session which hasn't finished yet is InProgress, and its Finished time is the time it was collected.
+type Session struct {
+	// ID is the ID of the task started event.
+	ID         uuid.UUID
+	TaskName   string
+	Tags       []string
+	Started    time.Time
+	Finished   time.Time
+	InProgress bool
+}
+
+// Duration is the time between the session starting and finishing.
+func (s Session) Duration() time.Duration {
+	return s.Finished.Sub(s.Started)
+}
+
+// Within returns the part of the session which falls within the period. The second return value is false if none of
+// it does.
+func (s Session) Within(p Period) (Session, bool) {
+	if !s.Started.Before(p.To) || !s.Finished.After(p.From) {
+		return Session{}, false
+	}
+	if s.Started.Before(p.From) {
+		s.Started = p.From
+	}
+	if s.Finished.After(p.To) {
+		s.Finished = p.To
+	}
+	return s, true
+}
+
+// SessionLister is used to list every session which overlaps a period, oldest first, including any still in
+// progress.
+type SessionLister interface {
+	Sessions(ctx context.Context, period Period) ([]Session, error)
+}
+
+// TaskTotal is the time spent on a task in a report's period. Rounded is the duration after applying any rounding
+// rules, and is the same as Duration if none apply.
+type TaskTotal struct {
+	TaskName string
+	Sessions int
+	Duration time.Duration
+	Rounded  time.Duration
+}
+
+// DayTotal is the time spent on all tasks on a day in a report's period. FirstStarted and LastFinished are when work
+// began and ended that day.
+type DayTotal struct {
+	Date         time.Time
+	Sessions     int
+	FirstStarted time.Time
+	LastFinished time.Time
+	Duration     time.Duration
+	Rounded      time.Duration
+}
+
+// Report summarises the completed sessions in a period. Sessions which started before or finished after the period
+// only count the time within it. Rounding is applied to the time spent on each task each day, and the rounded totals
+// are the sums of those.
+type Report struct {
+	Period       Period
+	Tasks        []TaskTotal
+	Days         []DayTotal
+	Sessions     int
+	Total        time.Duration
+	RoundedTotal time.Duration
+}
+
+// Reporter is used to summarise the time spent on tasks in a period.
+type Reporter interface {
+	Report(ctx context.Context, period Period) (Report, error)
+}
+
+// TimesheetRow is the time spent on a task on each day of a timesheet, before and after rounding.
+type TimesheetRow struct {
+	TaskName     string
+	Days         []time.Duration
+	Total        time.Duration
+	RoundedDays  []time.Duration
+	RoundedTotal time.Duration
+}
+
+// Timesheet is a grid of the time spent on each task on each day of a period, usually a week, with totals for each
+// task and each day. Sessions which cross midnight count towards both days.
+type Timesheet struct {
+	Period Period
+	Days   []Period
+	// Absences is the absence on each day, or a zero Absence on the days without one.
+	Absences         []Absence
+	Rows             []TimesheetRow
+	DayTotals        []time.Duration
+	Total            time.Duration
+	RoundedDayTotals []time.Duration
+	RoundedTotal     time.Duration
+}
+
+// TimesheetBuilder is used to build a timesheet of the completed sessions in a period.
+type TimesheetBuilder interface {
+	Timesheet(ctx context.Context, period Period) (Timesheet, error)
+}
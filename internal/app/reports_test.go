@@ -0,0 +1,61 @@
+package app_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPeriods(t *testing.T) {
+	// Wednesday
+	now := time.Date(2022, 6, 8, 15, 4, 5, 0, time.UTC)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2022, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		got  app.Period
+		want app.Period
+	}{
+		{name: "today", got: app.DayPeriod(now, 0), want: app.Period{From: date(6, 8), To: date(6, 9)}},
+		{name: "yesterday", got: app.DayPeriod(now, -1), want: app.Period{From: date(6, 7), To: date(6, 8)}},
+		{name: "week starting monday", got: app.WeekPeriod(now, time.Monday, 0), want: app.Period{From: date(6, 6), To: date(6, 13)}},
+		{name: "last week starting sunday", got: app.WeekPeriod(now, time.Sunday, -1), want: app.Period{From: date(5, 29), To: date(6, 5)}},
+		{name: "week starting today", got: app.WeekPeriod(now, time.Wednesday, 0), want: app.Period{From: date(6, 8), To: date(6, 15)}},
+		{name: "week starting tomorrow", got: app.WeekPeriod(now, time.Thursday, 0), want: app.Period{From: date(6, 2), To: date(6, 9)}},
+		{name: "this month", got: app.MonthPeriod(now, 0), want: app.Period{From: date(6, 1), To: date(7, 1)}},
+		{name: "month before last", got: app.MonthPeriod(now, -2), want: app.Period{From: date(4, 1), To: date(5, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.got)
+		})
+	}
+}
+
+func TestSession_Within(t *testing.T) {
+	at := func(hour int) time.Time {
+		return time.Date(2022, 6, 8, hour, 0, 0, 0, time.UTC)
+	}
+	period := app.Period{From: at(9), To: at(17)}
+	tests := []struct {
+		name    string
+		session app.Session
+		want    app.Session
+		wantOK  bool
+	}{
+		{name: "inside", session: app.Session{Started: at(10), Finished: at(11)}, want: app.Session{Started: at(10), Finished: at(11)}, wantOK: true},
+		{name: "overlapping start", session: app.Session{Started: at(8), Finished: at(10)}, want: app.Session{Started: at(9), Finished: at(10)}, wantOK: true},
+		{name: "overlapping end", session: app.Session{Started: at(16), Finished: at(18)}, want: app.Session{Started: at(16), Finished: at(17)}, wantOK: true},
+		{name: "before", session: app.Session{Started: at(7), Finished: at(9)}},
+		{name: "after", session: app.Session{Started: at(17), Finished: at(18)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.session.Within(period)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	RoundNearest = RoundingMode("nearest")
+	RoundUp      = RoundingMode("up")
+	RoundDown    = RoundingMode("down")
+
+	PerSession = RoundingScope("session")
+	PerDay     = RoundingScope("day")
+)
+
+// RoundingMode is the direction that durations are rounded in.
+type RoundingMode string
+
+// RoundingScope is what is rounded: each session, or the total time spent on a task each day.
+type RoundingScope string
+
+// RoundingRule rounds the time spent on matching tasks to a multiple of Increment, e.g. up to 15 minutes, as clients
+// are often billed in fixed increments.
+type RoundingRule struct {
+	// Task is the name of the tasks the rule applies to, or a prefix followed by *, e.g. acme-*. Any task matches if
+	// it's empty.
+	Task string
+	// Tag is a tag that tasks must have for the rule to apply. Any task matches if it's empty.
+	Tag       string
+	Mode      RoundingMode
+	Increment time.Duration
+	Per       RoundingScope
+}
+
+// Matches reports whether the rule applies to a session of the task with the tags.
+func (r RoundingRule) Matches(taskName string, tags []string) bool {
+	return matches(r.Task, r.Tag, taskName, tags)
+}
+
+// Round rounds the duration to a multiple of the increment. Halfway durations are rounded up by RoundNearest.
+func (r RoundingRule) Round(d time.Duration) time.Duration {
+	if r.Increment <= 0 {
+		return d
+	}
+	whole := d / r.Increment * r.Increment
+	switch r.Mode {
+	case RoundUp:
+		if whole < d {
+			whole += r.Increment
+		}
+	case RoundDown:
+	default:
+		if d-whole >= (r.Increment+1)/2 {
+			whole += r.Increment
+		}
+	}
+	return whole
+}
+
+// RoundingRules are checked in order, and the first rule which matches a session is applied to it. Sessions which
+// don't match any rule aren't rounded.
+type RoundingRules []RoundingRule
+
+// For returns the rule which applies to a session of the task with the tags. The second return value is false if
+// no rule does.
+func (rr RoundingRules) For(taskName string, tags []string) (RoundingRule, bool) {
+	for _, r := range rr {
+		if r.Matches(taskName, tags) {
+			return r, true
+		}
+	}
+	return RoundingRule{}, false
+}
+
+// matches reports whether a task with the tags is matched by a task name, or a name prefix followed by *, and a tag.
+// An empty task or tag matches anything.
+func matches(task, tag, taskName string, tags []string) bool {
+	switch {
+	case task == "":
+	case strings.HasSuffix(task, "*"):
+		if !strings.HasPrefix(taskName, strings.TrimSuffix(task, "*")) {
+			return false
+		}
+	case task != taskName:
+		return false
+	}
+
+	if tag == "" {
+		return true
+	}
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
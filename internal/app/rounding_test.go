@@ -0,0 +1,65 @@
+package app_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRoundingRule_Round(t *testing.T) {
+	rule := func(mode app.RoundingMode, minutes int) app.RoundingRule {
+		return app.RoundingRule{Mode: mode, Increment: time.Duration(minutes) * time.Minute}
+	}
+	tests := []struct {
+		name     string
+		rule     app.RoundingRule
+		duration time.Duration
+		want     time.Duration
+	}{
+		{name: "nearest down", rule: rule(app.RoundNearest, 15), duration: 37 * time.Minute, want: 30 * time.Minute},
+		{name: "nearest up", rule: rule(app.RoundNearest, 15), duration: 38 * time.Minute, want: 45 * time.Minute},
+		{name: "nearest halfway", rule: rule(app.RoundNearest, 15), duration: 37*time.Minute + 30*time.Second, want: 45 * time.Minute},
+		{name: "up", rule: rule(app.RoundUp, 15), duration: 31 * time.Minute, want: 45 * time.Minute},
+		{name: "up exact", rule: rule(app.RoundUp, 15), duration: 30 * time.Minute, want: 30 * time.Minute},
+		{name: "up a second", rule: rule(app.RoundUp, 6), duration: time.Second, want: 6 * time.Minute},
+		{name: "down", rule: rule(app.RoundDown, 30), duration: 59 * time.Minute, want: 30 * time.Minute},
+		{name: "no increment", rule: rule(app.RoundUp, 0), duration: 59 * time.Minute, want: 59 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rule.Round(tt.duration))
+		})
+	}
+}
+
+func TestRoundingRules_For(t *testing.T) {
+	rules := app.RoundingRules{
+		{Task: "support", Mode: app.RoundUp, Increment: 30 * time.Minute},
+		{Task: "acme-*", Mode: app.RoundUp, Increment: 15 * time.Minute},
+		{Tag: "billable", Mode: app.RoundNearest, Increment: 6 * time.Minute},
+	}
+	tests := []struct {
+		name     string
+		taskName string
+		tags     []string
+		want     int
+	}{
+		{name: "task", taskName: "support", want: 0},
+		{name: "task before tag", taskName: "support", tags: []string{"billable"}, want: 0},
+		{name: "prefix", taskName: "acme-website", want: 1},
+		{name: "tag", taskName: "website", tags: []string{"acme", "billable"}, want: 2},
+		{name: "no match", taskName: "supportive", want: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rules.For(tt.taskName, tt.tags)
+			if tt.want < 0 {
+				assert.False(t, ok)
+				return
+			}
+			assert.True(t, ok)
+			assert.Equal(t, rules[tt.want], got)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// SyncableEventStore is an event store whose events can be merged with another.
+type SyncableEventStore interface {
+	EventStore
+	EventLister
+}
+
+// SyncConflict describes two events for the same task, one from each side of a sync, which don't make sense
+// together once merged. For example, the same task started on both machines with no finish in between.
+type SyncConflict struct {
+	TaskName string
+	First    Event
+	Second   Event
+}
+
+// SyncResult summarises a sync between a local and a remote event store.
+type SyncResult struct {
+	CopiedToLocal  int
+	CopiedToRemote int
+	Conflicts      []SyncConflict
+	// DuplicateInvoiceNumbers are the numbers of invoices issued in both stores with the same number.
+	DuplicateInvoiceNumbers []string
+}
+
+// EventSyncer is used to merge two event stores, so that both end up with every event from either. Events are
+// matched by ID, so syncing the same stores again copies nothing.
+type EventSyncer interface {
+	Sync(ctx context.Context, local, remote SyncableEventStore) (SyncResult, error)
+}
+
+// ImportResult summarises importing events into an event store. Earliest and Latest are the times of the first and
+// last events imported.
+type ImportResult struct {
+	Imported   int
+	Duplicates int
+	Earliest   time.Time
+	Latest     time.Time
+	// DuplicateInvoiceNumbers are the numbers of imported invoices which were already used by another invoice.
+	DuplicateInvoiceNumbers []string
+}
+
+// EventImporter is used to add events to an event store, skipping any it already has (matched by ID). If dryRun is
+// true, nothing is stored but the result is the same.
+type EventImporter interface {
+	Import(ctx context.Context, events []Event, dryRun bool) (ImportResult, error)
+}
@@ -9,6 +9,7 @@ const (
 	ErrTaskAlreadyStarted = Error("task already started")
 	ErrTaskNotStarted     = Error("task not started")
 	ErrTaskNeverCompleted = Error("task never completed")
+	ErrEventOutOfOrder    = Error("event out of order")
 )
 
 // CompletedTask represents a task which has been both started and finished. The CompletedTask.Duration field
@@ -20,14 +21,15 @@ type CompletedTask struct {
 	Duration time.Duration
 }
 
-// TaskStarter is used to start a task with the given name. It can return ErrTaskAlreadyStarted if the task has
-// already been started.
+// TaskStarter is used to start a task with the given name, labelled with any tags. It can return
+// ErrTaskAlreadyStarted if the task has already been started, or ErrEventOutOfOrder if it would start before the
+// task's latest event.
 type TaskStarter interface {
-	Start(ctx context.Context, taskName string) error
+	Start(ctx context.Context, taskName string, tags ...string) error
 }
 
 // TaskFinisher is used to finish a currently running task. It can return ErrTaskNotStarted if the task is not
-// currently in progress.
+// currently in progress, or ErrEventOutOfOrder if it would finish before it started.
 type TaskFinisher interface {
 	Finish(ctx context.Context, taskName string) error
 }
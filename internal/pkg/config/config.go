@@ -0,0 +1,429 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/durationfmt"
+	"github.com/danmurf/time-tracker/internal/pkg/ubl"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of every environment variable which overrides a setting, e.g. TIME_TRACKER_DB.
+const EnvPrefix = "TIME_TRACKER_"
+
+// Config holds the user's settings. They are read from the config file, then overridden by environment variables,
+// which are in turn overridden by command line flags.
+type Config struct {
+	// DB is the path to the sqlite event store.
+	DB string `yaml:"db"`
+	// TimeZone is the IANA name of the time zone that times are shown in, e.g. Europe/London. If empty, the system's
+	// local time zone is used.
+	TimeZone string `yaml:"time_zone"`
+	// WeekStart is the day that weeks start on in weekly reports, e.g. monday.
+	WeekStart string `yaml:"week_start"`
+	// DurationFormat is the style that durations are shown in: hms, decimal, human or iso8601.
+	DurationFormat string `yaml:"duration_format"`
+	// DefaultTags are added to every task when it is started.
+	DefaultTags []string `yaml:"default_tags"`
+	// Currency is the ISO 4217 code of the currency that rates are set in when none is given, e.g. EUR.
+	Currency string `yaml:"currency"`
+	// Rounding lists the rules for rounding billable time, the first matching rule applying to each session.
+	Rounding []Rounding `yaml:"rounding"`
+	// Invoicing holds what's needed to export invoices as e-invoices.
+	Invoicing Invoicing `yaml:"invoicing"`
+	// Contract is the hours that should be worked, for the overtime report.
+	Contract Contract `yaml:"contract"`
+	// Compliance is the working-time rules checked by the compliance report.
+	Compliance Compliance `yaml:"compliance"`
+	// Pomodoro is the length of the focus and break intervals for start --pomodoro.
+	Pomodoro Pomodoro `yaml:"pomodoro"`
+}
+
+// Pomodoro is the length of the focus and break intervals of pomodoros, e.g.
+// `{focus: 25m, break: 5m, long_break: 15m, long_break_every: 4}`. Settings which aren't set keep their defaults, and
+// a long_break_every of 0 means there are no long breaks.
+type Pomodoro struct {
+	Focus          string `yaml:"focus"`
+	Break          string `yaml:"break"`
+	LongBreak      string `yaml:"long_break"`
+	LongBreakEvery int    `yaml:"long_break_every"`
+}
+
+// Compliance is the working-time rules to check, e.g.
+// `{breaks: [{after: 6h, minimum: 30m}, {after: 9h, minimum: 45m}], max_daily: 10h, min_rest: 11h}`. Rules which
+// aren't set keep their defaults, and a limit of 0 isn't checked.
+type Compliance struct {
+	Breaks   []BreakRule `yaml:"breaks"`
+	MaxDaily string      `yaml:"max_daily"`
+	MinRest  string      `yaml:"min_rest"`
+}
+
+// BreakRule requires breaks adding up to at least Minimum on a day with more than After of work.
+type BreakRule struct {
+	After   string `yaml:"after"`
+	Minimum string `yaml:"minimum"`
+}
+
+// Contract is the hours to work on each day of the week, from the date the contract started, e.g.
+// `{start: 2026-01-05, hours: {monday: 8h, tuesday: 8h, wednesday: 8h, thursday: 8h, friday: 5h30m}}`. Days which
+// aren't listed have no hours.
+type Contract struct {
+	Start string            `yaml:"start"`
+	Hours map[string]string `yaml:"hours"`
+}
+
+// Rounding is a rule for rounding the time spent on tasks, matching tasks by name or prefix, e.g. acme-*, and by
+// tag. For example, `{tag: acme, mode: up, increment: 15m, per: day}` rounds each day's time on tasks tagged acme up
+// to the next 15 minutes.
+type Rounding struct {
+	Task string `yaml:"task"`
+	Tag  string `yaml:"tag"`
+	// Mode is nearest, up or down, nearest by default.
+	Mode string `yaml:"mode"`
+	// Increment is a duration such as 6m, 15m or 30m.
+	Increment string `yaml:"increment"`
+	// Per is session or day, session by default.
+	Per string `yaml:"per"`
+}
+
+// Invoicing holds who invoices are from, who each client is, the VAT charged on invoiced time and how many days
+// clients have to pay. Clients are keyed by the client name on invoices, and can override the VAT, e.g. for reverse
+// charge:
+//
+//	invoicing:
+//	  seller: {name: My Company Ltd, street: 1 High Street, city: London, postal_code: N1 9GU, country: GB, vat_id: GB123456789}
+//	  tax: {category: S, percent: 20}
+//	  payment_days: 30
+//	  clients:
+//	    acme:
+//	      name: Acme BV
+//	      country: NL
+//	      vat_id: NL987654321B01
+//	      tax: {category: AE, exemption_reason: Reverse charge}
+type Invoicing struct {
+	Seller      Party             `yaml:"seller"`
+	Tax         Tax               `yaml:"tax"`
+	PaymentDays int               `yaml:"payment_days"`
+	Clients     map[string]Client `yaml:"clients"`
+}
+
+// Party is who an invoice is from or to. Country is an ISO 3166-1 alpha-2 code, e.g. GB.
+type Party struct {
+	Name       string `yaml:"name"`
+	Street     string `yaml:"street"`
+	City       string `yaml:"city"`
+	PostalCode string `yaml:"postal_code"`
+	Country    string `yaml:"country"`
+	VATID      string `yaml:"vat_id"`
+}
+
+// Client is who invoices for a client are to, and the VAT charged to them if it isn't the usual VAT.
+type Client struct {
+	Party `yaml:",inline"`
+	Tax   *Tax `yaml:"tax"`
+}
+
+// Tax is a UNCL 5305 VAT category code, e.g. S for the standard rate, the rate as a percentage, and why time isn't
+// taxed for categories such as E for exempt.
+type Tax struct {
+	Category        string  `yaml:"category"`
+	Percent         float64 `yaml:"percent"`
+	ExemptionReason string  `yaml:"exemption_reason"`
+}
+
+// Default returns the settings used when nothing has been configured. The event store stays in the directory used
+// before settings could be configured, so that existing events are still found.
+func Default(homeDir string) Config {
+	return Config{
+		DB:             filepath.Join(homeDir, ".time-tracker", "time-tracker.db"),
+		WeekStart:      "monday",
+		DurationFormat: string(durationfmt.StyleHMS),
+		Compliance: Compliance{
+			Breaks:   []BreakRule{{After: "6h", Minimum: "30m"}},
+			MaxDaily: "10h",
+			MinRest:  "11h",
+		},
+		Pomodoro: Pomodoro{Focus: "25m", Break: "5m", LongBreak: "15m", LongBreakEvery: 4},
+	}
+}
+
+// Path returns the location of the config file, following the XDG base directory specification:
+// $XDG_CONFIG_HOME/time-tracker/config.yaml, falling back to ~/.config/time-tracker/config.yaml.
+func Path(getenv func(string) string, homeDir string) string {
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" || !filepath.IsAbs(configHome) {
+		configHome = filepath.Join(homeDir, ".config")
+	}
+	return filepath.Join(configHome, "time-tracker", "config.yaml")
+}
+
+// Load reads the config file at path over the defaults, then applies any TIME_TRACKER_* environment variables. A
+// missing config file is not an error, as every setting has a default.
+func Load(path string, defaults Config, getenv func(string) string, homeDir string) (Config, error) {
+	c := defaults
+
+	contents, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return Config{}, fmt.Errorf("reading config file: %w", err)
+	default:
+		decoder := yaml.NewDecoder(bytes.NewReader(contents))
+		decoder.KnownFields(true)
+		if err = decoder.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+			return Config{}, fmt.Errorf("parsing config file [%s]: %w", path, err)
+		}
+	}
+
+	for name, setting := range map[string]*string{
+		"DB":              &c.DB,
+		"TIME_ZONE":       &c.TimeZone,
+		"WEEK_START":      &c.WeekStart,
+		"DURATION_FORMAT": &c.DurationFormat,
+		"CURRENCY":        &c.Currency,
+	} {
+		if value := getenv(EnvPrefix + name); value != "" {
+			*setting = value
+		}
+	}
+	if value := getenv(EnvPrefix + "DEFAULT_TAGS"); value != "" {
+		c.DefaultTags = strings.Split(value, ",")
+	}
+
+	c.DB = ExpandHome(c.DB, homeDir)
+
+	return c, c.Validate()
+}
+
+// Validate checks that the settings can be used.
+func (c Config) Validate() error {
+	if c.DB == "" {
+		return fmt.Errorf("db must be set")
+	}
+	if _, err := c.Location(); err != nil {
+		return err
+	}
+	if _, err := c.FirstWeekday(); err != nil {
+		return err
+	}
+	if _, err := c.DurationStyle(); err != nil {
+		return err
+	}
+	if _, err := c.RoundingRules(); err != nil {
+		return err
+	}
+	if _, err := c.ContractedHours(); err != nil {
+		return err
+	}
+	if _, err := c.WorkingTimeRules(); err != nil {
+		return err
+	}
+	if _, err := c.PomodoroSettings(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Location returns the time zone that times are shown in.
+func (c Config) Location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone [%s]: %w", c.TimeZone, err)
+	}
+	return location, nil
+}
+
+// FirstWeekday returns the day that weeks start on.
+func (c Config) FirstWeekday() (time.Weekday, error) {
+	if day, ok := parseWeekday(c.WeekStart); ok {
+		return day, nil
+	}
+	return time.Monday, fmt.Errorf("unknown week start [%s], must be a day such as monday", c.WeekStart)
+}
+
+// parseWeekday parses the name of a day, e.g. monday. The second return value is false if it isn't a day.
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(name, day.String()) {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// DurationStyle returns the style that durations are shown in.
+func (c Config) DurationStyle() (durationfmt.Style, error) {
+	return durationfmt.ParseStyle(c.DurationFormat)
+}
+
+// RoundingRules returns the rules for rounding the time spent on tasks.
+func (c Config) RoundingRules() (app.RoundingRules, error) {
+	var rules app.RoundingRules
+	for i, r := range c.Rounding {
+		rule := app.RoundingRule{Task: r.Task, Tag: r.Tag, Mode: app.RoundNearest, Per: app.PerSession}
+		switch mode := app.RoundingMode(strings.ToLower(r.Mode)); mode {
+		case "":
+		case app.RoundNearest, app.RoundUp, app.RoundDown:
+			rule.Mode = mode
+		default:
+			return nil, fmt.Errorf("unknown mode [%s] in rounding rule %d, must be nearest, up or down", r.Mode, i+1)
+		}
+		switch per := app.RoundingScope(strings.ToLower(r.Per)); per {
+		case "":
+		case app.PerSession, app.PerDay:
+			rule.Per = per
+		default:
+			return nil, fmt.Errorf("unknown per [%s] in rounding rule %d, must be session or day", r.Per, i+1)
+		}
+		increment, err := time.ParseDuration(r.Increment)
+		if err != nil || increment <= 0 {
+			return nil, fmt.Errorf("invalid increment [%s] in rounding rule %d, must be a duration such as 15m", r.Increment, i+1)
+		}
+		rule.Increment = increment
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ContractedHours returns the contract, which is zero if it isn't set. Its start is midnight in the configured time
+// zone.
+func (c Config) ContractedHours() (app.Contract, error) {
+	var contract app.Contract
+	if c.Contract.Start == "" {
+		if len(c.Contract.Hours) > 0 {
+			return app.Contract{}, fmt.Errorf("contract.start must be set to the date the contract started, e.g. 2026-01-05")
+		}
+		return contract, nil
+	}
+
+	location, err := c.Location()
+	if err != nil {
+		return app.Contract{}, err
+	}
+	if contract.Start, err = time.ParseInLocation("2006-01-02", c.Contract.Start, location); err != nil {
+		return app.Contract{}, fmt.Errorf("invalid contract.start [%s], must be a date such as 2026-01-05", c.Contract.Start)
+	}
+	for name, hours := range c.Contract.Hours {
+		day, ok := parseWeekday(name)
+		if !ok {
+			return app.Contract{}, fmt.Errorf("unknown day [%s] in contract.hours, must be a day such as monday", name)
+		}
+		duration, err := time.ParseDuration(hours)
+		if err != nil || duration < 0 || duration > 24*time.Hour {
+			return app.Contract{}, fmt.Errorf("invalid hours [%s] for %s in contract.hours, must be a duration such as 7h30m", hours, name)
+		}
+		contract.Hours[day] = duration
+	}
+	return contract, nil
+}
+
+// WorkingTimeRules returns the working-time rules to check.
+func (c Config) WorkingTimeRules() (app.WorkingTimeRules, error) {
+	var rules app.WorkingTimeRules
+	for i, b := range c.Compliance.Breaks {
+		after, err := parseLimit(b.After)
+		if err != nil {
+			return app.WorkingTimeRules{}, fmt.Errorf("invalid after [%s] in compliance break rule %d, must be a duration such as 6h", b.After, i+1)
+		}
+		minimum, err := parseLimit(b.Minimum)
+		if err != nil {
+			return app.WorkingTimeRules{}, fmt.Errorf("invalid minimum [%s] in compliance break rule %d, must be a duration such as 30m", b.Minimum, i+1)
+		}
+		rules.Breaks = append(rules.Breaks, app.BreakRule{After: after, Minimum: minimum})
+	}
+	var err error
+	if rules.MaxDaily, err = parseLimit(c.Compliance.MaxDaily); err != nil {
+		return app.WorkingTimeRules{}, fmt.Errorf("invalid compliance.max_daily [%s], must be a duration such as 10h", c.Compliance.MaxDaily)
+	}
+	if rules.MinRest, err = parseLimit(c.Compliance.MinRest); err != nil {
+		return app.WorkingTimeRules{}, fmt.Errorf("invalid compliance.min_rest [%s], must be a duration such as 11h", c.Compliance.MinRest)
+	}
+	return rules, nil
+}
+
+// PomodoroSettings returns the length of the focus and break intervals of pomodoros.
+func (c Config) PomodoroSettings() (app.PomodoroSettings, error) {
+	settings := app.PomodoroSettings{LongBreakEvery: c.Pomodoro.LongBreakEvery}
+	for _, setting := range []struct {
+		name     string
+		value    string
+		duration *time.Duration
+	}{
+		{"focus", c.Pomodoro.Focus, &settings.Focus},
+		{"break", c.Pomodoro.Break, &settings.Break},
+		{"long_break", c.Pomodoro.LongBreak, &settings.LongBreak},
+	} {
+		d, err := time.ParseDuration(setting.value)
+		if err != nil || d <= 0 {
+			return app.PomodoroSettings{}, fmt.Errorf("invalid pomodoro.%s [%s], must be a duration such as 25m", setting.name, setting.value)
+		}
+		*setting.duration = d
+	}
+	if settings.LongBreakEvery < 0 {
+		return app.PomodoroSettings{}, fmt.Errorf("invalid pomodoro.long_break_every [%d], must not be negative", settings.LongBreakEvery)
+	}
+	return settings, nil
+}
+
+// parseLimit parses a duration which mustn't be negative, where an empty limit or 0 is no limit.
+func parseLimit(s string) (time.Duration, error) {
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("negative duration")
+	}
+	return d, err
+}
+
+// EInvoice returns the details needed to export an invoice to a client as an e-invoice. The client's name is the
+// client's key if it isn't set.
+func (c Config) EInvoice(client string) (ubl.Details, error) {
+	if c.Invoicing.Seller.Name == "" {
+		return ubl.Details{}, fmt.Errorf("invoicing.seller must be set to export e-invoices")
+	}
+	buyer, ok := c.Invoicing.Clients[client]
+	if !ok {
+		return ubl.Details{}, fmt.Errorf("client [%s] must be set in invoicing.clients to export e-invoices", client)
+	}
+	if buyer.Name == "" {
+		buyer.Name = client
+	}
+	tax := c.Invoicing.Tax
+	if buyer.Tax != nil {
+		tax = *buyer.Tax
+	}
+
+	details := ubl.Details{
+		Seller:      ubl.Party(c.Invoicing.Seller),
+		Buyer:       ubl.Party(buyer.Party),
+		Tax:         ubl.Tax(tax),
+		PaymentDays: c.Invoicing.PaymentDays,
+	}
+	if err := ubl.Validate(details); err != nil {
+		return ubl.Details{}, fmt.Errorf("invalid invoicing for client [%s]: %w", client, err)
+	}
+	return details, nil
+}
+
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+func ExpandHome(path, homeDir string) string {
+	if path == "~" {
+		return homeDir
+	}
+	if strings.HasPrefix(path, "~/") {
+		return filepath.Join(homeDir, path[2:])
+	}
+	return path
+}
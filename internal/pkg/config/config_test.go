@@ -0,0 +1,300 @@
+package config_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/config"
+	"github.com/danmurf/time-tracker/internal/pkg/ubl"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "XDG config home",
+			env:  map[string]string{"XDG_CONFIG_HOME": "/xdg"},
+			want: "/xdg/time-tracker/config.yaml",
+		},
+		{
+			name: "XDG config home not set",
+			want: "/home/me/.config/time-tracker/config.yaml",
+		},
+		{
+			name: "relative XDG config home is ignored",
+			env:  map[string]string{"XDG_CONFIG_HOME": "xdg"},
+			want: "/home/me/.config/time-tracker/config.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, config.Path(getenv(tt.env), "/home/me"))
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	defaults := config.Default("/home/me")
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		want    config.Config
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "no config file",
+			want:    defaults,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "empty config file",
+			file:    "\n",
+			want:    defaults,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "config file",
+			file: "db: ~/work.db\ntime_zone: Europe/London\nweek_start: Sunday\nduration_format: decimal\ncurrency: GBP\ndefault_tags: [acme]\nrounding:\n  - {tag: acme, mode: up, increment: 15m, per: day}\n",
+			want: config.Config{
+				DB:             "/home/me/work.db",
+				TimeZone:       "Europe/London",
+				WeekStart:      "Sunday",
+				DurationFormat: "decimal",
+				Currency:       "GBP",
+				DefaultTags:    []string{"acme"},
+				Rounding:       []config.Rounding{{Tag: "acme", Mode: "up", Increment: "15m", Per: "day"}},
+				Compliance:     defaults.Compliance,
+				Pomodoro:       defaults.Pomodoro,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "environment variables override config file",
+			file: "db: /data/file.db\ntime_zone: Europe/London\n",
+			env: map[string]string{
+				"TIME_TRACKER_DB":              "/data/env.db",
+				"TIME_TRACKER_WEEK_START":      "saturday",
+				"TIME_TRACKER_DURATION_FORMAT": "iso8601",
+				"TIME_TRACKER_CURRENCY":        "EUR",
+				"TIME_TRACKER_DEFAULT_TAGS":    "acme,billable",
+			},
+			want: config.Config{
+				DB:             "/data/env.db",
+				TimeZone:       "Europe/London",
+				WeekStart:      "saturday",
+				DurationFormat: "iso8601",
+				Currency:       "EUR",
+				DefaultTags:    []string{"acme", "billable"},
+				Compliance:     defaults.Compliance,
+				Pomodoro:       defaults.Pomodoro,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "unknown setting",
+			file:    "databse: /data/file.db\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown time zone",
+			env:     map[string]string{"TIME_TRACKER_TIME_ZONE": "Mars/Olympus_Mons"},
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown week start",
+			file:    "week_start: someday\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid rounding increment",
+			file:    "rounding: [{tag: acme, increment: quarter}]\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown rounding mode",
+			file:    "rounding: [{tag: acme, mode: sideways, increment: 15m}]\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "contract without a start",
+			file:    "contract: {hours: {monday: 8h}}\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown day in contract",
+			file:    "contract: {start: 2022-06-01, hours: {someday: 8h}}\n",
+			wantErr: assert.Error,
+		},
+		{
+			name: "compliance rules replace the defaults which are set",
+			file: "compliance: {breaks: [{after: 6h, minimum: 30m}, {after: 9h, minimum: 45m}], min_rest: 0}\n",
+			want: config.Config{
+				DB:             defaults.DB,
+				WeekStart:      defaults.WeekStart,
+				DurationFormat: defaults.DurationFormat,
+				Compliance: config.Compliance{
+					Breaks:   []config.BreakRule{{After: "6h", Minimum: "30m"}, {After: "9h", Minimum: "45m"}},
+					MaxDaily: "10h",
+					MinRest:  "0",
+				},
+				Pomodoro: defaults.Pomodoro,
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid compliance rule",
+			file:    "compliance: {max_daily: all day}\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid pomodoro focus",
+			file:    "pomodoro: {focus: 0}\n",
+			wantErr: assert.Error,
+		},
+		{
+			name:    "unknown duration format",
+			file:    "duration_format: fortnights\n",
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if tt.file != "" {
+				assert.NoError(t, os.WriteFile(path, []byte(tt.file), 0o600))
+			}
+
+			got, err := config.Load(path, defaults, getenv(tt.env), "/home/me")
+			if !tt.wantErr(t, err) || err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfig_FirstWeekday(t *testing.T) {
+	got, err := config.Config{WeekStart: "Sunday"}.FirstWeekday()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Sunday, got)
+}
+
+func TestConfig_RoundingRules(t *testing.T) {
+	c := config.Config{Rounding: []config.Rounding{
+		{Task: "acme-*", Mode: "up", Increment: "15m", Per: "day"},
+		{Tag: "billable", Increment: "6m"},
+	}}
+
+	got, err := c.RoundingRules()
+	assert.NoError(t, err)
+	assert.Equal(t, app.RoundingRules{
+		{Task: "acme-*", Mode: app.RoundUp, Increment: 15 * time.Minute, Per: app.PerDay},
+		{Tag: "billable", Mode: app.RoundNearest, Increment: 6 * time.Minute, Per: app.PerSession},
+	}, got)
+}
+
+func TestConfig_ContractedHours(t *testing.T) {
+	c := config.Config{
+		TimeZone: "Europe/London",
+		Contract: config.Contract{Start: "2022-06-01", Hours: map[string]string{"Monday": "8h", "friday": "5h30m"}},
+	}
+
+	got, err := c.ContractedHours()
+	assert.NoError(t, err)
+	london, err := time.LoadLocation("Europe/London")
+	assert.NoError(t, err)
+	want := app.Contract{Start: time.Date(2022, 6, 1, 0, 0, 0, 0, london)}
+	want.Hours[time.Monday] = 8 * time.Hour
+	want.Hours[time.Friday] = 5*time.Hour + 30*time.Minute
+	assert.Equal(t, want, got)
+
+	got, err = config.Config{}.ContractedHours()
+	assert.NoError(t, err)
+	assert.True(t, got.IsZero(), "the contract shouldn't have to be set")
+
+	c.Contract.Hours["friday"] = "25h"
+	_, err = c.ContractedHours()
+	assert.Error(t, err)
+}
+
+func TestConfig_WorkingTimeRules(t *testing.T) {
+	got, err := config.Default("/home/me").WorkingTimeRules()
+	assert.NoError(t, err)
+	assert.Equal(t, app.WorkingTimeRules{
+		Breaks:   []app.BreakRule{{After: 6 * time.Hour, Minimum: 30 * time.Minute}},
+		MaxDaily: 10 * time.Hour,
+		MinRest:  11 * time.Hour,
+	}, got)
+
+	got, err = config.Config{Compliance: config.Compliance{MaxDaily: "0"}}.WorkingTimeRules()
+	assert.NoError(t, err)
+	assert.Equal(t, app.WorkingTimeRules{}, got, "a limit of 0 shouldn't be checked")
+
+	_, err = config.Config{Compliance: config.Compliance{Breaks: []config.BreakRule{{After: "6h", Minimum: "-30m"}}}}.WorkingTimeRules()
+	assert.Error(t, err)
+}
+
+func TestConfig_PomodoroSettings(t *testing.T) {
+	c := config.Default("/home/me")
+	c.Pomodoro.Focus = "50m"
+
+	got, err := c.PomodoroSettings()
+	assert.NoError(t, err)
+	assert.Equal(t, app.PomodoroSettings{Focus: 50 * time.Minute, Break: 5 * time.Minute, LongBreak: 15 * time.Minute, LongBreakEvery: 4}, got)
+}
+
+func TestConfig_EInvoice(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`invoicing:
+  seller: {name: Seller Ltd, street: 1 High Street, city: London, postal_code: N1 9GU, country: GB, vat_id: GB123456789}
+  tax: {category: S, percent: 20}
+  payment_days: 30
+  clients:
+    acme: {country: GB}
+    globex:
+      name: Globex BV
+      country: NL
+      vat_id: NL987654321B01
+      tax: {category: AE, exemption_reason: Reverse charge}
+    initech: {name: Initech}
+`), 0o600))
+	c, err := config.Load(path, config.Default("/home/me"), getenv(nil), "/home/me")
+	assert.NoError(t, err)
+	seller := ubl.Party{Name: "Seller Ltd", Street: "1 High Street", City: "London", PostalCode: "N1 9GU", Country: "GB", VATID: "GB123456789"}
+
+	got, err := c.EInvoice("acme")
+	assert.NoError(t, err)
+	assert.Equal(t, ubl.Details{
+		Seller:      seller,
+		Buyer:       ubl.Party{Name: "acme", Country: "GB"},
+		Tax:         ubl.Tax{Category: "S", Percent: 20},
+		PaymentDays: 30,
+	}, got, "the client's name should default to its key")
+
+	got, err = c.EInvoice("globex")
+	assert.NoError(t, err)
+	assert.Equal(t, ubl.Party{Name: "Globex BV", Country: "NL", VATID: "NL987654321B01"}, got.Buyer)
+	assert.Equal(t, ubl.Tax{Category: "AE", ExemptionReason: "Reverse charge"}, got.Tax, "a client's tax should override the usual tax")
+
+	_, err = c.EInvoice("initech")
+	assert.Error(t, err, "a client without a country can't be invoiced")
+
+	_, err = c.EInvoice("hooli")
+	assert.Error(t, err, "a client must be configured")
+
+	_, err = config.Config{}.EInvoice("acme")
+	assert.Error(t, err, "the seller must be configured")
+}
+
+func getenv(env map[string]string) func(string) string {
+	return func(name string) string {
+		return env[name]
+	}
+}
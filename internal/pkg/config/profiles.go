@@ -0,0 +1,178 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DefaultProfile is the profile used until another is chosen. It uses the original config file and event store.
+	DefaultProfile = "default"
+
+	activeProfileFile = "active-profile"
+	profilesDir       = "profiles"
+
+	ErrProfileNotFound = app.Error("profile not found")
+	ErrProfileExists   = app.Error("profile already exists")
+)
+
+var profileName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_-]*$`)
+
+// Profiles manages named profiles, each with its own config file and event store, so that time tracked in one
+// context, e.g. for an employer, can never be mixed with another, e.g. freelance work. Profiles don't inherit
+// settings from the default profile, or from each other.
+type Profiles struct {
+	// configDir is the directory containing the default profile's config file.
+	configDir string
+	homeDir   string
+}
+
+// NewProfiles manages the profiles whose config files are alongside the config file at configPath.
+func NewProfiles(configPath, homeDir string) Profiles {
+	return Profiles{configDir: filepath.Dir(configPath), homeDir: homeDir}
+}
+
+// ConfigPath returns the path of the profile's config file.
+func (p Profiles) ConfigPath(name string) string {
+	if name == DefaultProfile {
+		return filepath.Join(p.configDir, "config.yaml")
+	}
+	return filepath.Join(p.configDir, profilesDir, name+".yaml")
+}
+
+// Defaults returns the settings used by the profile when they aren't configured. Each profile has its own event
+// store by default.
+func (p Profiles) Defaults(name string) Config {
+	c := Default(p.homeDir)
+	if name != DefaultProfile {
+		c.DB = filepath.Join(p.homeDir, ".time-tracker", profilesDir, name+".db")
+	}
+	return c
+}
+
+// Getenv returns a getenv for loading the profile's settings. TIME_TRACKER_CONFIG and TIME_TRACKER_DB locate the
+// default profile's config file and event store, so they are hidden from other profiles, which have their own.
+func (p Profiles) Getenv(name string, getenv func(string) string) func(string) string {
+	if name == DefaultProfile {
+		return getenv
+	}
+	return func(key string) string {
+		if key == EnvPrefix+"CONFIG" || key == EnvPrefix+"DB" {
+			return ""
+		}
+		return getenv(key)
+	}
+}
+
+// Exists reports whether the profile has been created. The default profile always exists.
+func (p Profiles) Exists(name string) (bool, error) {
+	if name == DefaultProfile {
+		return true, nil
+	}
+	_, err := os.Stat(p.ConfigPath(name))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("checking for profile [%s]: %w", name, err)
+	}
+	return true, nil
+}
+
+// List returns the names of every profile, sorted, with the default profile first.
+func (p Profiles) List() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(p.configDir, profilesDir, "*.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("listing profiles: %w", err)
+	}
+
+	var names []string
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		if profileName.MatchString(name) && name != DefaultProfile {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return append([]string{DefaultProfile}, names...), nil
+}
+
+// Create creates a profile, with a config file naming its own event store. It returns ErrProfileExists if there is
+// already a profile with the name.
+func (p Profiles) Create(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	exists, err := p.Exists(name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("creating profile [%s]: %w", name, ErrProfileExists)
+	}
+
+	path := p.ConfigPath(name)
+	if err = os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("creating profiles directory: %w", err)
+	}
+	contents := fmt.Sprintf("# Settings for the %s profile, which doesn't share any settings with other profiles.\ndb: %s\n", name, p.Defaults(name).DB)
+	if err = os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		return fmt.Errorf("writing profile config file: %w", err)
+	}
+
+	return nil
+}
+
+// Active returns the profile chosen with Use, or the default profile if none has been.
+func (p Profiles) Active() (string, error) {
+	contents, err := os.ReadFile(filepath.Join(p.configDir, activeProfileFile))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return DefaultProfile, nil
+	case err != nil:
+		return "", fmt.Errorf("reading active profile: %w", err)
+	}
+
+	name := strings.TrimSpace(string(contents))
+	if name == "" {
+		return DefaultProfile, nil
+	}
+	return name, nil
+}
+
+// Use makes the profile the active one, used whenever a profile isn't given. It returns ErrProfileNotFound if the
+// profile hasn't been created.
+func (p Profiles) Use(name string) error {
+	exists, err := p.Exists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("using profile [%s]: %w", name, ErrProfileNotFound)
+	}
+
+	if err = os.MkdirAll(p.configDir, os.ModePerm); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(p.configDir, activeProfileFile), []byte(name+"\n"), 0o600); err != nil {
+		return fmt.Errorf("writing active profile: %w", err)
+	}
+
+	return nil
+}
+
+// ValidateProfileName checks that a profile name can be used as a file name. Names start with a letter or digit,
+// followed by letters, digits, - or _.
+func ValidateProfileName(name string) error {
+	if !profileName.MatchString(name) {
+		return fmt.Errorf("invalid profile name [%s], use letters, digits, - and _", name)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package config_test
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	sut := config.NewProfiles(filepath.Join(dir, "config.yaml"), "/home/me")
+
+	active, err := sut.Active()
+	assert.NoError(t, err)
+	assert.Equal(t, config.DefaultProfile, active, "the default profile should be active until another is used")
+
+	assert.NoError(t, sut.Create("work"))
+	assert.NoError(t, sut.Create("personal"))
+	assert.ErrorIs(t, sut.Create("work"), config.ErrProfileExists)
+	assert.ErrorIs(t, sut.Create(config.DefaultProfile), config.ErrProfileExists)
+
+	names, err := sut.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{config.DefaultProfile, "personal", "work"}, names)
+
+	assert.NoError(t, sut.Use("work"))
+	active, err = sut.Active()
+	assert.NoError(t, err)
+	assert.Equal(t, "work", active)
+	assert.ErrorIs(t, sut.Use("unknown"), config.ErrProfileNotFound)
+
+	work, err := config.Load(sut.ConfigPath("work"), sut.Defaults("work"), getenv(nil), "/home/me")
+	assert.NoError(t, err)
+	personal, err := config.Load(sut.ConfigPath("personal"), sut.Defaults("personal"), getenv(nil), "/home/me")
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/me/.time-tracker/profiles/work.db", work.DB)
+	assert.NotEqual(t, work.DB, personal.DB, "profiles should have separate event stores")
+	assert.NotEqual(t, work.DB, config.Default("/home/me").DB)
+}
+
+func TestProfiles_Getenv(t *testing.T) {
+	env := getenv(map[string]string{
+		"TIME_TRACKER_CONFIG":    "/tmp/config.yaml",
+		"TIME_TRACKER_DB":        "/tmp/events.db",
+		"TIME_TRACKER_TIME_ZONE": "Europe/Paris",
+	})
+	sut := config.NewProfiles(filepath.Join(t.TempDir(), "config.yaml"), "/home/me")
+
+	defaults := sut.Getenv(config.DefaultProfile, env)
+	assert.Equal(t, "/tmp/config.yaml", defaults("TIME_TRACKER_CONFIG"))
+	assert.Equal(t, "/tmp/events.db", defaults("TIME_TRACKER_DB"))
+
+	work := sut.Getenv("work", env)
+	assert.Empty(t, work("TIME_TRACKER_CONFIG"), "profiles should use their own config file")
+	assert.Empty(t, work("TIME_TRACKER_DB"), "profiles should use their own event store")
+	assert.Equal(t, "Europe/Paris", work("TIME_TRACKER_TIME_ZONE"), "other settings should still be overridden")
+
+	loaded, err := config.Load(sut.ConfigPath("work"), sut.Defaults("work"), work, "/home/me")
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/me/.time-tracker/profiles/work.db", loaded.DB)
+}
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "work", wantErr: assert.NoError},
+		{name: "client_1-side", wantErr: assert.NoError},
+		{name: "", wantErr: assert.Error},
+		{name: "../work", wantErr: assert.Error},
+		{name: "-work", wantErr: assert.Error},
+		{name: "my work", wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.name), func(t *testing.T) {
+			tt.wantErr(t, config.ValidateProfileName(tt.name))
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package durationfmt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// StyleHMS shows hours, minutes and seconds, e.g. 01:23:45.
+	StyleHMS = Style("hms")
+	// StyleDecimal shows decimal hours to two places, e.g. 1.40h, as timesheets are usually filled in.
+	StyleDecimal = Style("decimal")
+	// StyleHuman shows hours and minutes in words, e.g. 1 hour 24 minutes.
+	StyleHuman = Style("human")
+	// StyleISO8601 shows an ISO 8601 duration, e.g. PT1H23M45S.
+	StyleISO8601 = Style("iso8601")
+)
+
+// Style is a way of showing durations.
+type Style string
+
+// Styles lists every supported style.
+var Styles = []Style{StyleHMS, StyleDecimal, StyleHuman, StyleISO8601}
+
+func ParseStyle(s string) (Style, error) {
+	for _, style := range Styles {
+		if string(style) == strings.ToLower(s) {
+			return style, nil
+		}
+	}
+
+	names := make([]string, len(Styles))
+	for i, style := range Styles {
+		names[i] = string(style)
+	}
+	return "", fmt.Errorf("unknown duration format [%s], must be one of %s", s, strings.Join(names, ", "))
+}
+
+// Format shows the duration in the style. Durations are rounded to the nearest second, or minute in the human style,
+// and shown in hours rather than days, however long they are.
+func (s Style) Format(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+
+	switch s {
+	case StyleDecimal:
+		return sign + strconv.FormatFloat(d.Hours(), 'f', 2, 64) + "h"
+	case StyleHuman:
+		return sign + human(d)
+	case StyleISO8601:
+		return sign + iso8601(d)
+	default:
+		h, m, sec := split(d.Round(time.Second))
+		return fmt.Sprintf("%s%02d:%02d:%02d", sign, h, m, sec)
+	}
+}
+
+func human(d time.Duration) string {
+	if d < time.Minute {
+		return plural(int64(d.Round(time.Second)/time.Second), "second")
+	}
+
+	h, m, _ := split(d.Round(time.Minute))
+	var parts []string
+	if h > 0 {
+		parts = append(parts, plural(h, "hour"))
+	}
+	if m > 0 {
+		parts = append(parts, plural(m, "minute"))
+	}
+	return strings.Join(parts, " ")
+}
+
+func plural(n int64, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
+
+func iso8601(d time.Duration) string {
+	h, m, s := split(d.Round(time.Second))
+	if h == 0 && m == 0 && s == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	b.WriteString("PT")
+	for _, part := range []struct {
+		n    int64
+		unit string
+	}{{h, "H"}, {m, "M"}, {s, "S"}} {
+		if part.n > 0 {
+			b.WriteString(strconv.FormatInt(part.n, 10) + part.unit)
+		}
+	}
+	return b.String()
+}
+
+// split returns the whole hours, minutes and seconds in d.
+func split(d time.Duration) (hours, minutes, seconds int64) {
+	total := int64(d / time.Second)
+	return total / 3600, total % 3600 / 60, total % 60
+}
@@ -0,0 +1,51 @@
+package durationfmt_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/pkg/durationfmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStyle_Format(t *testing.T) {
+	d := time.Hour + 23*time.Minute + 45*time.Second + 123456789
+	tests := []struct {
+		name     string
+		style    durationfmt.Style
+		duration time.Duration
+		want     string
+	}{
+		{name: "hms", style: durationfmt.StyleHMS, duration: d, want: "01:23:45"},
+		{name: "hms over a day", style: durationfmt.StyleHMS, duration: 26*time.Hour + 500*time.Millisecond, want: "26:00:01"},
+		{name: "hms negative", style: durationfmt.StyleHMS, duration: -90 * time.Minute, want: "-01:30:00"},
+		{name: "decimal", style: durationfmt.StyleDecimal, duration: d, want: "1.40h"},
+		{name: "decimal zero", style: durationfmt.StyleDecimal, duration: 0, want: "0.00h"},
+		{name: "human", style: durationfmt.StyleHuman, duration: d, want: "1 hour 24 minutes"},
+		{name: "human hours", style: durationfmt.StyleHuman, duration: 2 * time.Hour, want: "2 hours"},
+		{name: "human minutes", style: durationfmt.StyleHuman, duration: time.Minute + 10*time.Second, want: "1 minute"},
+		{name: "human seconds", style: durationfmt.StyleHuman, duration: 45 * time.Second, want: "45 seconds"},
+		{name: "iso8601", style: durationfmt.StyleISO8601, duration: d, want: "PT1H23M45S"},
+		{name: "iso8601 minutes", style: durationfmt.StyleISO8601, duration: 90 * time.Minute, want: "PT1H30M"},
+		{name: "iso8601 zero", style: durationfmt.StyleISO8601, duration: 0, want: "PT0S"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.style.Format(tt.duration))
+		})
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	for _, style := range durationfmt.Styles {
+		got, err := durationfmt.ParseStyle(string(style))
+		assert.NoError(t, err)
+		assert.Equal(t, style, got)
+	}
+
+	got, err := durationfmt.ParseStyle("ISO8601")
+	assert.NoError(t, err)
+	assert.Equal(t, durationfmt.StyleISO8601, got)
+
+	_, err = durationfmt.ParseStyle("go")
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"strings"
+)
+
+const (
+	// KeySize is the length of keys used by Cipher. The first half is used for encryption and the second half for
+	// deriving nonces.
+	KeySize = 64
+
+	prefix = "enc:v1:"
+
+	ErrWrongPassphrase = app.Error("wrong passphrase")
+)
+
+// Cipher encrypts individual values with AES-256-GCM. Encryption is deterministic: the nonce is derived from an HMAC
+// of the value, so the same value always encrypts to the same ciphertext. This allows encrypted values to be looked
+// up by equality, at the cost of revealing which stored values are equal.
+type Cipher struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+func NewCipher(key []byte) (Cipher, error) {
+	if len(key) != KeySize {
+		return Cipher{}, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return Cipher{}, fmt.Errorf("creating block cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return Cipher{}, fmt.Errorf("creating gcm: %w", err)
+	}
+
+	return Cipher{aead: aead, macKey: key[32:]}, nil
+}
+
+// Encrypt encrypts the value of the named field. The field name is authenticated along with the value, so a
+// ciphertext can't be moved to a different field.
+func (c Cipher) Encrypt(field, value string) string {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write([]byte(field))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	nonce := mac.Sum(nil)[:c.aead.NonceSize()]
+
+	sealed := c.aead.Seal(nonce, nonce, []byte(value), []byte(field))
+	return prefix + base64.RawURLEncoding.EncodeToString(sealed)
+}
+
+// Decrypt decrypts a value produced by Encrypt for the same field. Values which were never encrypted, such as those
+// stored before encryption was enabled, are returned unchanged.
+func (c Cipher) Decrypt(field, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(value, prefix))
+	if err != nil {
+		return "", fmt.Errorf("decoding %s: %w", field, err)
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", fmt.Errorf("decoding %s: ciphertext too short", field)
+	}
+
+	plain, err := c.aead.Open(nil, sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():], []byte(field))
+	if err != nil {
+		return "", fmt.Errorf("decrypting %s: %w", field, ErrWrongPassphrase)
+	}
+
+	return string(plain), nil
+}
+
+// IsEncrypted reports whether the value was produced by Cipher.Encrypt.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,63 @@
+package encryption_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCipher_EncryptDecrypt(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, encryption.KeySize)
+	otherKey := bytes.Repeat([]byte{8}, encryption.KeySize)
+	tests := []struct {
+		name  string
+		field string
+		value string
+	}{
+		{name: "task name", field: "task_name", value: "acme-website"},
+		{name: "empty value", field: "task_name", value: ""},
+		{name: "unicode value", field: "task_name", value: "café-✨"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sut, err := encryption.NewCipher(key)
+			assert.NoError(t, err)
+
+			encrypted := sut.Encrypt(tt.field, tt.value)
+			assert.True(t, encryption.IsEncrypted(encrypted))
+			if tt.value != "" {
+				assert.NotContains(t, encrypted, tt.value, "ciphertext should not contain the value")
+			}
+			assert.Equal(t, encrypted, sut.Encrypt(tt.field, tt.value), "encryption should be deterministic")
+			assert.NotEqual(t, encrypted, sut.Encrypt(tt.field+"2", tt.value), "ciphertext should depend on the field")
+
+			decrypted, err := sut.Decrypt(tt.field, encrypted)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.value, decrypted)
+
+			_, err = sut.Decrypt("other_field", encrypted)
+			assert.Error(t, err, "ciphertext should not decrypt as a different field")
+
+			other, err := encryption.NewCipher(otherKey)
+			assert.NoError(t, err)
+			_, err = other.Decrypt(tt.field, encrypted)
+			assert.Truef(t, errors.Is(err, encryption.ErrWrongPassphrase), "want err [%s] got [%s]", encryption.ErrWrongPassphrase, err)
+		})
+	}
+}
+
+func TestCipher_DecryptPlaintext(t *testing.T) {
+	sut, err := encryption.NewCipher(bytes.Repeat([]byte{7}, encryption.KeySize))
+	assert.NoError(t, err)
+
+	got, err := sut.Decrypt("task_name", "stored-before-encryption")
+	assert.NoError(t, err)
+	assert.Equal(t, "stored-before-encryption", got)
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	_, err := encryption.NewCipher([]byte("too short"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+const (
+	// iterations is the PBKDF2-HMAC-SHA256 work factor for new key files, as recommended by OWASP.
+	iterations = 600000
+	saltSize   = 16
+	keyCheck   = "time-tracker key check"
+)
+
+// keyFile holds what is needed to derive the same key from a passphrase again, and to check that a passphrase is the
+// right one before anything is encrypted with it. It never contains the key itself.
+type keyFile struct {
+	Salt       []byte `json:"salt"`
+	Iterations int    `json:"iterations"`
+	Check      []byte `json:"check"`
+}
+
+// KeyFileExists reports whether a key file has been created at the path, which means the store it belongs to is
+// encrypted.
+func KeyFileExists(path string) (bool, error) {
+	_, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("checking key file [%s]: %w", path, err)
+	}
+	return true, nil
+}
+
+// LoadOrCreateKey derives the key for a passphrase, using the salt in the key file at the path. If there is no key
+// file, one is created with a new random salt. It returns ErrWrongPassphrase if the passphrase is not the one the key
+// file was created with.
+func LoadOrCreateKey(path, passphrase string) ([]byte, error) {
+	var kf keyFile
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		kf = keyFile{Salt: make([]byte, saltSize), Iterations: iterations}
+		if _, err = rand.Read(kf.Salt); err != nil {
+			return nil, fmt.Errorf("generating salt: %w", err)
+		}
+		key := DeriveKey(passphrase, kf.Salt, kf.Iterations)
+		kf.Check = check(key)
+		if raw, err = json.Marshal(kf); err != nil {
+			return nil, fmt.Errorf("encoding key file: %w", err)
+		}
+		if err = os.WriteFile(path, raw, 0600); err != nil {
+			return nil, fmt.Errorf("writing key file [%s]: %w", path, err)
+		}
+		return key, nil
+	case err != nil:
+		return nil, fmt.Errorf("reading key file [%s]: %w", path, err)
+	}
+
+	if err = json.Unmarshal(raw, &kf); err != nil {
+		return nil, fmt.Errorf("decoding key file [%s]: %w", path, err)
+	}
+	key := DeriveKey(passphrase, kf.Salt, kf.Iterations)
+	if !hmac.Equal(check(key), kf.Check) {
+		return nil, fmt.Errorf("checking key: %w", ErrWrongPassphrase)
+	}
+
+	return key, nil
+}
+
+// DeriveKey derives a KeySize key from a passphrase with PBKDF2-HMAC-SHA256 (RFC 8018).
+func DeriveKey(passphrase string, salt []byte, iterations int) []byte {
+	prf := hmac.New(sha256.New, []byte(passphrase))
+	key := make([]byte, 0, KeySize)
+	for block := uint32(1); len(key) < KeySize; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		var counter [4]byte
+		binary.BigEndian.PutUint32(counter[:], block)
+		prf.Write(counter[:])
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+
+	return key[:KeySize]
+}
+
+func check(key []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(keyCheck))
+	return mac.Sum(nil)
+}
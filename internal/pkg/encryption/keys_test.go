@@ -0,0 +1,70 @@
+package encryption_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeriveKey(t *testing.T) {
+	// Test vectors from RFC 7914 section 11.
+	tests := []struct {
+		name       string
+		passphrase string
+		salt       string
+		iterations int
+		want       string
+	}{
+		{
+			name:       "1 iteration",
+			passphrase: "passwd",
+			salt:       "salt",
+			iterations: 1,
+			want:       "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783",
+		},
+		{
+			name:       "80000 iterations",
+			passphrase: "Password",
+			salt:       "NaCl",
+			iterations: 80000,
+			want:       "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := encryption.DeriveKey(tt.passphrase, []byte(tt.salt), tt.iterations)
+			assert.Equal(t, tt.want, hex.EncodeToString(got))
+		})
+	}
+}
+
+func TestLoadOrCreateKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "time-tracker.db.key")
+
+	exists, err := encryption.KeyFileExists(path)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	created, err := encryption.LoadOrCreateKey(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Len(t, created, encryption.KeySize)
+
+	exists, err = encryption.KeyFileExists(path)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	raw, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(raw), hex.EncodeToString(created), "key file should not contain the key")
+
+	loaded, err := encryption.LoadOrCreateKey(path, "correct horse")
+	assert.NoError(t, err)
+	assert.Equal(t, created, loaded)
+
+	_, err = encryption.LoadOrCreateKey(path, "battery staple")
+	assert.Truef(t, errors.Is(err, encryption.ErrWrongPassphrase), "want err [%s] got [%s]", encryption.ErrWrongPassphrase, err)
+}
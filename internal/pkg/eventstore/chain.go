@@ -0,0 +1,52 @@
+package eventstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"time"
+)
+
+// chainedEvent is the canonical form of an event used to calculate its hash. Fields added to events later must be
+// tagged omitempty, so that the hashes of events stored before they existed still verify.
+type chainedEvent struct {
+	PrevHash  string            `json:"prev_hash"`
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	TaskName  string            `json:"task_name"`
+	CreatedAt string            `json:"created_at"`
+	Tags      []string          `json:"tags,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+	UTCOffset int               `json:"utc_offset,omitempty"`
+}
+
+// newChainedEvent builds the canonical form of the event. The UTC offset of its time is only included once the store
+// has been migrated to hash offsets, so that chains stored before then can still be verified.
+func newChainedEvent(prevHash string, e app.Event, withOffset bool) chainedEvent {
+	c := chainedEvent{
+		PrevHash:  prevHash,
+		ID:        e.ID.String(),
+		Type:      string(e.Type),
+		TaskName:  e.TaskName,
+		CreatedAt: e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		Tags:      e.Tags,
+		Data:      e.Data,
+	}
+	if withOffset {
+		_, c.UTCOffset = e.CreatedAt.Zone()
+	}
+	return c
+}
+
+// hash calculates the SHA-256 hash of the event, which is chained to the event stored before it by PrevHash.
+func (c chainedEvent) hash() (string, error) {
+	canonical, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("encoding canonical event: %w", err)
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
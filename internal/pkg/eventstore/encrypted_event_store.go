@@ -0,0 +1,152 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+)
+
+var (
+	_ app.EventStore         = (*EncryptedEventStore)(nil)
+	_ app.EventFinder        = (*EncryptedEventStore)(nil)
+	_ app.EventLister        = (*EncryptedEventStore)(nil)
+	_ app.EventChainVerifier = (*EncryptedEventStore)(nil)
+)
+
+const (
+	fieldTaskName = "task_name"
+	fieldTag      = "tag"
+	// fieldData prefixes the key of each data value, e.g. data.amount.
+	fieldData = "data."
+)
+
+// encryptable is an event store which an EncryptedEventStore can decorate.
+type encryptable interface {
+	app.EventStore
+	app.EventFinder
+	app.EventLister
+}
+
+// rewritable is an event store whose existing events can be rewritten, e.g. SQLEventStore.
+type rewritable interface {
+	Rewrite(ctx context.Context, rewrite func(e app.Event) (app.Event, error)) error
+}
+
+// EncryptedEventStore encrypts the confidential fields of events before they reach the decorated event store, and
+// decrypts them again when they are found. Task names are encrypted deterministically, so that events can still be
+// found by name, and tags and data values are encrypted one by one in the same way. Event types, times and data keys
+// are left in plaintext so that the decorated store can filter and order them.
+type EncryptedEventStore struct {
+	store  encryptable
+	cipher encryption.Cipher
+}
+
+func NewEncryptedEventStore(store encryptable, cipher encryption.Cipher) EncryptedEventStore {
+	return EncryptedEventStore{store: store, cipher: cipher}
+}
+
+func (s EncryptedEventStore) Store(ctx context.Context, e app.Event) error {
+	return s.store.Store(ctx, s.encrypt(e))
+}
+
+// EncryptExisting encrypts the events stored before encryption was enabled, so that they can still be found by name
+// and their plaintext is no longer kept.
+func (s EncryptedEventStore) EncryptExisting(ctx context.Context) error {
+	store, ok := s.store.(rewritable)
+	if !ok {
+		return fmt.Errorf("event store does not support rewriting events")
+	}
+	return store.Rewrite(ctx, func(e app.Event) (app.Event, error) {
+		// Values which are already encrypted are decrypted first, so that they aren't encrypted twice.
+		e, err := s.decrypt(e)
+		if err != nil {
+			return app.Event{}, err
+		}
+		return s.encrypt(e), nil
+	})
+}
+
+func (s EncryptedEventStore) LatestByName(ctx context.Context, taskName string) (app.Event, error) {
+	event, err := s.store.LatestByName(ctx, s.cipher.Encrypt(fieldTaskName, taskName))
+	if err != nil {
+		return event, err
+	}
+	return s.decrypt(event)
+}
+
+func (s EncryptedEventStore) LatestByNameType(ctx context.Context, taskName string, eventType app.EventType) (app.Event, error) {
+	event, err := s.store.LatestByNameType(ctx, s.cipher.Encrypt(fieldTaskName, taskName), eventType)
+	if err != nil {
+		return event, err
+	}
+	return s.decrypt(event)
+}
+
+func (s EncryptedEventStore) FetchAll(ctx context.Context) ([]app.Event, error) {
+	events, err := s.store.FetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range events {
+		if events[i], err = s.decrypt(events[i]); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// VerifyChain verifies the decorated store's hash chain, which is calculated over the encrypted events.
+func (s EncryptedEventStore) VerifyChain(ctx context.Context) (app.ChainVerification, error) {
+	verifier, ok := s.store.(app.EventChainVerifier)
+	if !ok {
+		return app.ChainVerification{}, fmt.Errorf("event store does not support verification")
+	}
+	return verifier.VerifyChain(ctx)
+}
+
+func (s EncryptedEventStore) encrypt(e app.Event) app.Event {
+	e.TaskName = s.cipher.Encrypt(fieldTaskName, e.TaskName)
+	if e.Tags != nil {
+		tags := make([]string, len(e.Tags))
+		for i, tag := range e.Tags {
+			tags[i] = s.cipher.Encrypt(fieldTag, tag)
+		}
+		e.Tags = tags
+	}
+	if e.Data != nil {
+		data := make(map[string]string, len(e.Data))
+		for key, value := range e.Data {
+			data[key] = s.cipher.Encrypt(fieldData+key, value)
+		}
+		e.Data = data
+	}
+	return e
+}
+
+func (s EncryptedEventStore) decrypt(e app.Event) (app.Event, error) {
+	taskName, err := s.cipher.Decrypt(fieldTaskName, e.TaskName)
+	if err != nil {
+		return app.Event{}, fmt.Errorf("decrypting event [%s]: %w", e.ID, err)
+	}
+	e.TaskName = taskName
+	if e.Tags != nil {
+		tags := make([]string, len(e.Tags))
+		for i, tag := range e.Tags {
+			if tags[i], err = s.cipher.Decrypt(fieldTag, tag); err != nil {
+				return app.Event{}, fmt.Errorf("decrypting event [%s] tags: %w", e.ID, err)
+			}
+		}
+		e.Tags = tags
+	}
+	if e.Data != nil {
+		data := make(map[string]string, len(e.Data))
+		for key, value := range e.Data {
+			if data[key], err = s.cipher.Decrypt(fieldData+key, value); err != nil {
+				return app.Event{}, fmt.Errorf("decrypting event [%s] data: %w", e.ID, err)
+			}
+		}
+		e.Data = data
+	}
+	return e, nil
+}
@@ -0,0 +1,199 @@
+package eventstore_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEncryptedEventStore_StoresCiphertext(t *testing.T) {
+	ctx := context.Background()
+	inner := eventstore.NewMemoryEventStore()
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{7}, encryption.KeySize))
+	assert.NoError(t, err)
+	sut := eventstore.NewEncryptedEventStore(inner, cipher)
+
+	event := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "acme-website",
+		CreatedAt: time.Now().Truncate(time.Second).UTC(),
+		Tags:      []string{"acme"},
+	}
+	assert.NoError(t, sut.Store(ctx, event))
+
+	stored, err := inner.FetchAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, stored, 1) {
+		assert.NotContains(t, stored[0].TaskName, "acme")
+		assert.True(t, encryption.IsEncrypted(stored[0].TaskName))
+		if assert.Len(t, stored[0].Tags, 1) {
+			assert.True(t, encryption.IsEncrypted(stored[0].Tags[0]), "tags should be encrypted")
+		}
+		assert.Equal(t, event.Type, stored[0].Type, "event types should not be encrypted")
+		assert.Equal(t, event.CreatedAt, stored[0].CreatedAt, "event times should not be encrypted")
+	}
+
+	got, err := sut.LatestByName(ctx, "acme-website")
+	assert.NoError(t, err)
+	assert.Equal(t, event, got)
+}
+
+func TestEncryptedEventStore_EncryptsData(t *testing.T) {
+	ctx := context.Background()
+	inner := eventstore.NewMemoryEventStore()
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{7}, encryption.KeySize))
+	assert.NoError(t, err)
+	sut := eventstore.NewEncryptedEventStore(inner, cipher)
+
+	event := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeRateSet,
+		CreatedAt: time.Now().Truncate(time.Second).UTC(),
+		Data:      map[string]string{"task": "acme-*", "amount": "120"},
+	}
+	assert.NoError(t, sut.Store(ctx, event))
+
+	stored, err := inner.FetchAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, stored, 1) {
+		assert.Len(t, stored[0].Data, 2, "data keys should not be encrypted")
+		assert.True(t, encryption.IsEncrypted(stored[0].Data["task"]))
+		assert.True(t, encryption.IsEncrypted(stored[0].Data["amount"]))
+	}
+
+	got, err := sut.FetchAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []app.Event{event}, got)
+}
+
+func TestEncryptedEventStore_ReadsPlaintextEvents(t *testing.T) {
+	ctx := context.Background()
+	inner := eventstore.NewMemoryEventStore()
+	event := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "stored-before-encryption",
+		CreatedAt: time.Now().Truncate(time.Second).UTC(),
+	}
+	assert.NoError(t, inner.Store(ctx, event))
+
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{7}, encryption.KeySize))
+	assert.NoError(t, err)
+	got, err := eventstore.NewEncryptedEventStore(inner, cipher).FetchAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []app.Event{event}, got)
+}
+
+func TestEncryptedEventStore_EncryptExisting(t *testing.T) {
+	started := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "acme-website",
+		CreatedAt: time.Now().Add(-time.Hour).Truncate(time.Second).UTC(),
+		Tags:      []string{"acme"},
+	}
+	rate := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeRateSet,
+		CreatedAt: time.Now().Add(-30 * time.Minute).Truncate(time.Second).UTC(),
+		Data:      map[string]string{"tag": "acme", "amount": "120", "currency": "EUR"},
+	}
+	tests := []struct {
+		name    string
+		tamper  string
+		wantErr error
+	}{
+		{
+			name: "plaintext store",
+		},
+		{
+			name:    "tampered plaintext store",
+			tamper:  "UPDATE event_store SET task_name = 'other-task' WHERE id = '" + started.ID.String() + "';",
+			wantErr: app.ErrEventChainBroken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := newMemorySqliteDB(t)
+			defer db.Close()
+			plaintext, err := eventstore.NewSQLEventStore(ctx, db)
+			assert.NoError(t, err)
+			assert.NoError(t, plaintext.Store(ctx, started))
+			assert.NoError(t, plaintext.Store(ctx, rate))
+			if tt.tamper != "" {
+				_, err = db.ExecContext(ctx, tt.tamper)
+				assert.NoError(t, err, "tampering with stored test data")
+			}
+
+			inner, err := eventstore.NewSQLEventStore(ctx, db)
+			assert.NoError(t, err)
+			cipher, err := encryption.NewCipher(bytes.Repeat([]byte{7}, encryption.KeySize))
+			assert.NoError(t, err)
+			sut := eventstore.NewEncryptedEventStore(inner, cipher)
+
+			err = sut.EncryptExisting(ctx)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected error [%v], got [%v]", tt.wantErr, err)
+				var taskName string
+				assert.NoError(t, db.QueryRowContext(ctx, "SELECT task_name FROM event_store WHERE id = ?;", started.ID.String()).Scan(&taskName))
+				assert.Equal(t, "other-task", taskName, "events should not be rewritten")
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, sut.EncryptExisting(ctx), "encrypting again should change nothing")
+
+			got, err := sut.LatestByName(ctx, "acme-website")
+			assert.NoError(t, err, "existing events should be found by name")
+			assert.Equal(t, started, got)
+			all, err := sut.FetchAll(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, []app.Event{rate, started}, all)
+
+			stored, err := inner.FetchAll(ctx)
+			assert.NoError(t, err)
+			for _, e := range stored {
+				if e.ID == started.ID {
+					assert.True(t, encryption.IsEncrypted(e.TaskName), "task names should be encrypted")
+					assert.True(t, encryption.IsEncrypted(e.Tags[0]), "tags should be encrypted")
+				} else {
+					assert.True(t, encryption.IsEncrypted(e.Data["amount"]), "data should be encrypted")
+				}
+			}
+
+			reopened, err := eventstore.NewSQLEventStore(ctx, db)
+			assert.NoError(t, err)
+			verification, err := reopened.VerifyChain(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, 2, verification.Events)
+			assert.Nil(t, verification.Break, "the chain should be rebuilt over the encrypted events")
+		})
+	}
+}
+
+func TestEncryptedEventStore_WrongKey(t *testing.T) {
+	ctx := context.Background()
+	inner := eventstore.NewMemoryEventStore()
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{7}, encryption.KeySize))
+	assert.NoError(t, err)
+	otherCipher, err := encryption.NewCipher(bytes.Repeat([]byte{8}, encryption.KeySize))
+	assert.NoError(t, err)
+
+	assert.NoError(t, eventstore.NewEncryptedEventStore(inner, cipher).Store(ctx, app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "acme-website",
+		CreatedAt: time.Now().UTC(),
+	}))
+
+	_, err = eventstore.NewEncryptedEventStore(inner, otherCipher).FetchAll(ctx)
+	assert.Truef(t, errors.Is(err, encryption.ErrWrongPassphrase), "want err [%s] got [%s]", encryption.ErrWrongPassphrase, err)
+}
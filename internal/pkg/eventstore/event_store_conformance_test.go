@@ -0,0 +1,516 @@
+package eventstore_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/encryption"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEventStore_StoreFetchAll(t *testing.T) {
+	event1 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-10 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	event2 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskFinished,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-5 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	event3 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-2",
+		CreatedAt: time.Now().Add(-2 * time.Minute).Truncate(time.Second).UTC(),
+		Tags:      []string{"acme", "billable"},
+	}
+	event4 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeRateSet,
+		CreatedAt: time.Now().Add(-1 * time.Minute).Truncate(time.Second).UTC(),
+		Data:      map[string]string{"task": "my-task-*", "amount": "120", "currency": "EUR"},
+	}
+	type args struct {
+		store []app.Event
+	}
+	tests := []struct {
+		name string
+		args args
+		want []app.Event
+	}{
+		{
+			name: "3 events",
+			args: args{
+				store: []app.Event{event1, event2, event3},
+			},
+			want: []app.Event{event1, event2, event3},
+		},
+		{
+			name: "event with data",
+			args: args{
+				store: []app.Event{event1, event4},
+			},
+			want: []app.Event{event1, event4},
+		},
+		{
+			name: "1 event",
+			args: args{
+				store: []app.Event{event1},
+			},
+			want: []app.Event{event1},
+		},
+		{
+			name: "0 events",
+			args: args{
+				store: []app.Event{},
+			},
+			want: nil,
+		},
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				sut := backend.new(t)
+
+				for _, event := range tt.args.store {
+					assert.NoError(t, sut.Store(ctx, event))
+				}
+
+				got, err := sut.FetchAll(ctx)
+				assert.NoError(t, err)
+				assert.ElementsMatch(t, tt.want, got)
+			})
+		}
+	}
+}
+
+func TestEventStore_FetchAllOrder(t *testing.T) {
+	older := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-10 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	newer := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskFinished,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-5 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			sut := backend.new(t)
+			assert.NoError(t, sut.Store(ctx, older))
+			assert.NoError(t, sut.Store(ctx, newer))
+
+			got, err := sut.FetchAll(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, []app.Event{newer, older}, got, "events should be returned newest first")
+		})
+	}
+}
+
+func TestEventStore_StoreDuplicateID(t *testing.T) {
+	event := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Truncate(time.Second).UTC(),
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			sut := backend.new(t)
+			assert.NoError(t, sut.Store(ctx, event))
+			assert.Error(t, sut.Store(ctx, event))
+		})
+	}
+}
+
+func TestEventStore_StoreTimes(t *testing.T) {
+	now := time.Now()
+	_, offset := now.Zone()
+	local := time.FixedZone("", offset)
+	if offset == 0 {
+		local = time.UTC
+	}
+	ist := time.FixedZone("IST", 5*60*60+30*60)
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		want      time.Time
+	}{
+		{
+			name:      "utc with nanoseconds",
+			createdAt: time.Date(2022, 6, 1, 4, 30, 0, 123456789, time.UTC),
+			want:      time.Date(2022, 6, 1, 4, 30, 0, 123456789, time.UTC),
+		},
+		{
+			name:      "named zone with nanoseconds",
+			createdAt: time.Date(2022, 6, 1, 10, 0, 0, 123456789, ist),
+			want:      time.Date(2022, 6, 1, 10, 0, 0, 123456789, time.FixedZone("", 5*60*60+30*60)),
+		},
+		{
+			name:      "local time with a monotonic clock reading",
+			createdAt: now,
+			want:      now.In(local),
+		},
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				sut := backend.new(t)
+				event := app.Event{ID: uuid.New(), Type: app.EventTypeTaskStarted, TaskName: "my-task-1", CreatedAt: tt.createdAt}
+				assert.NoError(t, sut.Store(ctx, event))
+
+				want := event
+				want.CreatedAt = tt.want
+
+				all, err := sut.FetchAll(ctx)
+				assert.NoError(t, err)
+				assert.Equal(t, []app.Event{want}, all)
+
+				latest, err := sut.LatestByName(ctx, event.TaskName)
+				assert.NoError(t, err)
+				assert.Equal(t, want, latest)
+			})
+		}
+	}
+}
+
+func TestEventStore_StoreCopiesEvents(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			sut := backend.new(t)
+			event := app.Event{
+				ID:        uuid.New(),
+				Type:      app.EventTypeTaskStarted,
+				TaskName:  "my-task-1",
+				CreatedAt: time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC),
+				Tags:      []string{"acme"},
+				Data:      map[string]string{"note": "kick-off"},
+			}
+			want := app.Event{
+				ID:        event.ID,
+				Type:      event.Type,
+				TaskName:  event.TaskName,
+				CreatedAt: event.CreatedAt,
+				Tags:      []string{"acme"},
+				Data:      map[string]string{"note": "kick-off"},
+			}
+			assert.NoError(t, sut.Store(ctx, event))
+			event.Tags[0] = "globex"
+			event.Data["note"] = "changed after storing"
+
+			all, err := sut.FetchAll(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, []app.Event{want}, all)
+			all[0].Tags[0] = "globex"
+			all[0].Data["note"] = "changed after fetching"
+
+			latest, err := sut.LatestByName(ctx, event.TaskName)
+			assert.NoError(t, err)
+			assert.Equal(t, want, latest)
+			latest.Tags[0] = "globex"
+			latest.Data["note"] = "changed after finding"
+
+			all, err = sut.FetchAll(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, []app.Event{want}, all)
+		})
+	}
+}
+
+func TestEventStore_StoreEmptyTagsAndData(t *testing.T) {
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			ctx := context.Background()
+			sut := backend.new(t)
+			event := app.Event{
+				ID:        uuid.New(),
+				Type:      app.EventTypeTaskStarted,
+				TaskName:  "my-task-1",
+				CreatedAt: time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC),
+				Tags:      []string{},
+				Data:      map[string]string{},
+			}
+			assert.NoError(t, sut.Store(ctx, event))
+
+			got, err := sut.LatestByName(ctx, event.TaskName)
+			assert.NoError(t, err)
+			assert.Nil(t, got.Tags, "empty tags should be read back as nil")
+			assert.Nil(t, got.Data, "empty data should be read back as nil")
+		})
+	}
+}
+
+func TestEventStore_LatestByName(t *testing.T) {
+	event1 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-10 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	event2 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskFinished,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-5 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	event3 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-2",
+		CreatedAt: time.Now().Add(-2 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	type args struct {
+		store []app.Event
+		name  string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    app.Event
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "1 event stored",
+			args: args{
+				store: []app.Event{event1},
+				name:  event1.TaskName,
+			},
+			want:    event1,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "2 events stored; it returns the latest",
+			args: args{
+				store: []app.Event{event1, event2},
+				name:  event1.TaskName,
+			},
+			want:    event2,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "2 events stored out of order; it returns the latest by time",
+			args: args{
+				store: []app.Event{event2, event1},
+				name:  event1.TaskName,
+			},
+			want:    event2,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "no events stored matching task name",
+			args: args{
+				store: []app.Event{event1, event2},
+				name:  event3.TaskName,
+			},
+			want: app.Event{},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t,
+					errors.Is(err, app.ErrEventNotFound),
+					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
+				)
+			},
+		},
+		{
+			name: "empty db",
+			args: args{
+				store: []app.Event{},
+				name:  event1.TaskName,
+			},
+			want: app.Event{},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t,
+					errors.Is(err, app.ErrEventNotFound),
+					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
+				)
+			},
+		},
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				sut := backend.new(t)
+
+				for _, event := range tt.args.store {
+					assert.NoError(t, sut.Store(ctx, event), "preparing stored test data")
+				}
+
+				got, err := sut.LatestByName(ctx, tt.args.name)
+				tt.wantErr(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	}
+}
+
+func TestEventStore_LatestByNameType(t *testing.T) {
+	event1 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-10 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	event2 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskFinished,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Now().Add(-5 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	event3 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskStarted,
+		TaskName:  "my-task-2",
+		CreatedAt: time.Now().Add(-2 * time.Minute).Truncate(time.Second).UTC(),
+	}
+	type args struct {
+		store     []app.Event
+		name      string
+		eventType app.EventType
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    app.Event
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "1 completed task, get the started event",
+			args: args{
+				store:     []app.Event{event1, event2},
+				name:      event1.TaskName,
+				eventType: app.EventTypeTaskStarted,
+			},
+			want:    event1,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "1 completed task, get the finished event",
+			args: args{
+				store:     []app.Event{event1, event2},
+				name:      event1.TaskName,
+				eventType: app.EventTypeTaskFinished,
+			},
+			want:    event2,
+			wantErr: assert.NoError,
+		},
+		{
+			name: "1 started task, try to get the finished event",
+			args: args{
+				store:     []app.Event{event3},
+				name:      event3.TaskName,
+				eventType: app.EventTypeTaskFinished,
+			},
+			want: app.Event{},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t,
+					errors.Is(err, app.ErrEventNotFound),
+					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
+				)
+			},
+		},
+		{
+			name: "empty db",
+			args: args{
+				store: []app.Event{},
+				name:  event1.TaskName,
+			},
+			want: app.Event{},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t,
+					errors.Is(err, app.ErrEventNotFound),
+					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
+				)
+			},
+		},
+	}
+	for _, backend := range backends {
+		for _, tt := range tests {
+			t.Run(backend.name+"/"+tt.name, func(t *testing.T) {
+				ctx := context.Background()
+				sut := backend.new(t)
+
+				for _, event := range tt.args.store {
+					assert.NoError(t, sut.Store(ctx, event), "preparing stored test data")
+				}
+
+				got, err := sut.LatestByNameType(ctx, tt.args.name, tt.args.eventType)
+				tt.wantErr(t, err)
+				assert.Equal(t, tt.want, got)
+			})
+		}
+	}
+}
+
+// eventBackend is implemented by every event store, each of which must behave identically.
+type eventBackend interface {
+	app.EventStore
+	app.EventFinder
+	app.EventLister
+}
+
+var backends = []struct {
+	name string
+	new  func(t *testing.T) eventBackend
+}{
+	{
+		name: "sql",
+		new: func(t *testing.T) eventBackend {
+			db := newMemorySqliteDB(t)
+			t.Cleanup(func() { db.Close() })
+			s, err := eventstore.NewSQLEventStore(context.Background(), db)
+			if err != nil {
+				t.Errorf("creating sql event store: %s", err)
+				t.FailNow()
+			}
+			return s
+		},
+	},
+	{
+		name: "memory",
+		new: func(t *testing.T) eventBackend {
+			return eventstore.NewMemoryEventStore()
+		},
+	},
+	{
+		name: "encrypted",
+		new: func(t *testing.T) eventBackend {
+			cipher, err := encryption.NewCipher(bytes.Repeat([]byte{7}, encryption.KeySize))
+			if err != nil {
+				t.Errorf("creating cipher: %s", err)
+				t.FailNow()
+			}
+			return eventstore.NewEncryptedEventStore(eventstore.NewMemoryEventStore(), cipher)
+		},
+	},
+}
+
+func newMemorySqliteDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Errorf("opening in memory sqlite database: %s", err)
+		t.FailNow()
+	}
+	// Each connection to an in memory database sees a different database, so only ever use one.
+	db.SetMaxOpenConns(1)
+	return db
+}
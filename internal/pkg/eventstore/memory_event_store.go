@@ -0,0 +1,114 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"sort"
+	"sync"
+)
+
+var (
+	_ app.EventStore  = (*MemoryEventStore)(nil)
+	_ app.EventFinder = (*MemoryEventStore)(nil)
+	_ app.EventLister = (*MemoryEventStore)(nil)
+)
+
+// MemoryEventStore keeps events in memory for the lifetime of the process. It behaves in the same way as
+// SQLEventStore, which makes it useful for ephemeral sessions and fast tests. Events are copied in and out, and their
+// times kept in the zone SQLEventStore reads them back in, so they look the same whichever store they came from.
+type MemoryEventStore struct {
+	mu     *sync.RWMutex
+	events *[]app.Event
+}
+
+func NewMemoryEventStore() MemoryEventStore {
+	return MemoryEventStore{mu: &sync.RWMutex{}, events: &[]app.Event{}}
+}
+
+func (s MemoryEventStore) Store(_ context.Context, e app.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range *s.events {
+		if existing.ID == e.ID {
+			return fmt.Errorf("inserting into memory: duplicate event ID [%s]", e.ID)
+		}
+	}
+	*s.events = append(*s.events, stored(e))
+
+	return nil
+}
+
+func (s MemoryEventStore) FetchAll(_ context.Context) ([]app.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []app.Event
+	for _, e := range *s.events {
+		events = append(events, stored(e))
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	return events, nil
+}
+
+func (s MemoryEventStore) LatestByName(ctx context.Context, taskName string) (app.Event, error) {
+	return s.findLatest(ctx, func(e app.Event) bool {
+		return e.TaskName == taskName
+	})
+}
+
+func (s MemoryEventStore) LatestByNameType(ctx context.Context, taskName string, eventType app.EventType) (app.Event, error) {
+	return s.findLatest(ctx, func(e app.Event) bool {
+		return e.TaskName == taskName && e.Type == eventType
+	})
+}
+
+func (s MemoryEventStore) findLatest(_ context.Context, match func(e app.Event) bool) (event app.Event, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	found := false
+	for _, e := range *s.events {
+		if !match(e) {
+			continue
+		}
+		if !found || !e.CreatedAt.Before(event.CreatedAt) {
+			event = e
+			found = true
+		}
+	}
+	if !found {
+		return app.Event{}, fmt.Errorf("finding latest event: %w", app.ErrEventNotFound)
+	}
+
+	return stored(event), nil
+}
+
+// stored returns a copy of the event as SQLEventStore would read it back: its time in a zone with only its UTC offset
+// and no monotonic clock reading, and its own tags and data, which are nil when there are none.
+func stored(e app.Event) app.Event {
+	_, offset := e.CreatedAt.Zone()
+	e.CreatedAt = e.CreatedAt.In(zone(offset))
+
+	if len(e.Tags) == 0 {
+		e.Tags = nil
+	} else {
+		e.Tags = append([]string(nil), e.Tags...)
+	}
+
+	if len(e.Data) == 0 {
+		e.Data = nil
+	} else {
+		data := make(map[string]string, len(e.Data))
+		for k, v := range e.Data {
+			data[k] = v
+		}
+		e.Data = data
+	}
+
+	return e
+}
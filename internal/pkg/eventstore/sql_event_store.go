@@ -3,15 +3,19 @@ package eventstore
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/danmurf/time-tracker/internal/app"
 	"github.com/google/uuid"
+	"time"
 )
 
 var (
-	_ app.EventStore  = (*SQLEventStore)(nil)
-	_ app.EventFinder = (*SQLEventStore)(nil)
+	_ app.EventStore         = (*SQLEventStore)(nil)
+	_ app.EventFinder        = (*SQLEventStore)(nil)
+	_ app.EventLister        = (*SQLEventStore)(nil)
+	_ app.EventChainVerifier = (*SQLEventStore)(nil)
 )
 
 const (
@@ -21,34 +25,109 @@ CREATE TABLE IF NOT EXISTS "event_store" (
 	"type" varchar NOT NULL DEFAULT NULL,
 	"task_name" varchar NOT NULL DEFAULT NULL, 
 	"created_at" datetime NOT NULL,
+	"sequence" integer DEFAULT NULL,
+	"prev_hash" varchar DEFAULT NULL,
+	"hash" varchar DEFAULT NULL,
+	"tags" varchar DEFAULT NULL,
+	"utc_offset" integer DEFAULT NULL,
+	"data" varchar DEFAULT NULL,
 	PRIMARY KEY (id)
 );
 `
+	sequenceIndexCreation = `CREATE UNIQUE INDEX IF NOT EXISTS "event_store_sequence" ON "event_store" ("sequence");`
 )
 
+// ErrSpaceNotReclaimed is returned by Rewrite when the events were rewritten, but the space used by their old values
+// couldn't be reclaimed, so the old values may remain in the database file.
+const ErrSpaceNotReclaimed = app.Error("space not reclaimed")
+
+// Schema versions are recorded in the database's user_version once the migration to them has run, so that each
+// migration runs exactly once.
+const (
+	// versionLegacyChained is reached once events stored before the hash chain existed have been chained.
+	versionLegacyChained = 1
+	// versionOffsetsHashed is reached once the UTC offsets of events are included in their hashes.
+	versionOffsetsHashed = 2
+)
+
+// columnMigrations adds columns to event stores created by earlier versions, in the order they were introduced.
+var columnMigrations = []struct {
+	name       string
+	definition string
+}{
+	{name: "sequence", definition: `"sequence" integer DEFAULT NULL`},
+	{name: "prev_hash", definition: `"prev_hash" varchar DEFAULT NULL`},
+	{name: "hash", definition: `"hash" varchar DEFAULT NULL`},
+	{name: "tags", definition: `"tags" varchar DEFAULT NULL`},
+	{name: "utc_offset", definition: `"utc_offset" integer DEFAULT NULL`},
+	{name: "data", definition: `"data" varchar DEFAULT NULL`},
+}
+
+// eventColumns are the columns read by scanEvent, in order.
+const eventColumns = "id, type, task_name, created_at, tags, utc_offset, data"
+
 func NewSQLEventStore(ctx context.Context, db *sql.DB) (SQLEventStore, error) {
 	s := SQLEventStore{db: db}
-	if err := s.bootstrap(ctx); err != nil {
+	version, err := s.bootstrap(ctx)
+	if err != nil {
 		return SQLEventStore{}, fmt.Errorf("bootstrapping sql event store: %w", err)
 	}
+	s.hashesOffsets = version >= versionOffsetsHashed
 	return s, nil
 }
 
 type SQLEventStore struct {
 	db *sql.DB
+	// hashesOffsets is false only while the chain was already broken when offsets began to be hashed.
+	hashesOffsets bool
 }
 
+// Store appends the event to the end of the hash chain. The time of the event is stored in UTC, so that events are
+// ordered correctly whichever time zone they were recorded in, and the original UTC offset is kept alongside it.
 func (s SQLEventStore) Store(ctx context.Context, e app.Event) error {
-	if _, err := s.db.ExecContext(ctx, "INSERT INTO `event_store` VALUES(?, ?, ?, ?);", e.ID, e.Type, e.TaskName, e.CreatedAt); err != nil {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	sequence, prevHash, err := chainTail(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	hash, err := newChainedEvent(prevHash, e, s.hashesOffsets).hash()
+	if err != nil {
+		return fmt.Errorf("hashing event: %w", err)
+	}
+
+	tags, err := encodeTags(e.Tags)
+	if err != nil {
+		return err
+	}
+	data, err := encodeData(e.Data)
+	if err != nil {
+		return err
+	}
+
+	_, offset := e.CreatedAt.Zone()
+	if _, err = tx.ExecContext(ctx,
+		"INSERT INTO `event_store` (id, type, task_name, created_at, tags, utc_offset, data, sequence, prev_hash, hash) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?);",
+		e.ID, e.Type, e.TaskName, e.CreatedAt.UTC(), tags, offset, data, sequence+1, prevHash, hash,
+	); err != nil {
 		return fmt.Errorf("inserting into db: %w", err)
 	}
 
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
 	return nil
 }
 
 func (s SQLEventStore) FetchAll(ctx context.Context) ([]app.Event, error) {
 	var events []app.Event
-	rows, err := s.db.QueryContext(ctx, "SELECT id, type, task_name, created_at FROM `event_store` ORDER BY created_at DESC;")
+	rows, err := s.db.QueryContext(ctx, "SELECT "+eventColumns+" FROM `event_store` ORDER BY created_at DESC;")
 	if err != nil {
 		return events, fmt.Errorf("querying db: %w", err)
 	}
@@ -58,9 +137,9 @@ func (s SQLEventStore) FetchAll(ctx context.Context) ([]app.Event, error) {
 		return events, fmt.Errorf("reading rows: %w", err)
 	}
 	for rows.Next() {
-		var event app.Event
-		if err = rows.Scan(&event.ID, &event.Type, &event.TaskName, &event.CreatedAt); err != nil {
-			return events, fmt.Errorf("scanning row: %w", err)
+		event, err := scanEvent(rows)
+		if err != nil {
+			return events, err
 		}
 		events = append(events, event)
 	}
@@ -69,12 +148,12 @@ func (s SQLEventStore) FetchAll(ctx context.Context) ([]app.Event, error) {
 }
 
 func (s SQLEventStore) LatestByName(ctx context.Context, taskName string) (event app.Event, err error) {
-	query := `SELECT e.id, e.type, e.task_name, e.created_at FROM event_store e WHERE e.task_name = ? ORDER BY e.created_at DESC LIMIT 1;`
+	query := "SELECT " + eventColumns + " FROM event_store WHERE task_name = ? ORDER BY created_at DESC LIMIT 1;"
 	return s.findOneQuery(ctx, query, taskName)
 }
 
 func (s SQLEventStore) LatestByNameType(ctx context.Context, taskName string, eventType app.EventType) (event app.Event, err error) {
-	query := `SELECT e.id, e.type, e.task_name, e.created_at FROM event_store e WHERE e.task_name = ? AND e.type = ? ORDER BY e.created_at DESC LIMIT 1;`
+	query := "SELECT " + eventColumns + " FROM event_store WHERE task_name = ? AND type = ? ORDER BY created_at DESC LIMIT 1;"
 	return s.findOneQuery(ctx, query, taskName, eventType)
 }
 
@@ -84,29 +163,551 @@ func (s SQLEventStore) findOneQuery(ctx context.Context, query string, args ...a
 		return event, fmt.Errorf("querying db: %w", row.Err())
 	}
 
-	var id string
-	err = row.Scan(&id, &event.Type, &event.TaskName, &event.CreatedAt)
-	switch {
-	case !errors.Is(err, sql.ErrNoRows) && err != nil:
-		return event, fmt.Errorf("scanning row: %w", err)
-	case errors.Is(err, sql.ErrNoRows):
+	event, err = scanEvent(row)
+	if errors.Is(err, sql.ErrNoRows) {
 		return event, fmt.Errorf("finding latest event: %w", app.ErrEventNotFound)
 	}
 
-	taskID, err := uuid.Parse(id)
+	return event, err
+}
+
+// VerifyChain walks the hash chain in the order events were stored, and reports the first event which has been
+// edited, deleted, reordered or inserted directly since it was stored.
+func (s SQLEventStore) VerifyChain(ctx context.Context) (v app.ChainVerification, err error) {
+	links, err := readChain(ctx, s.db)
+	if err != nil {
+		return v, err
+	}
+	return verifyChain(links, s.hashesOffsets)
+}
+
+// Rewrite replaces the task name, tags and data of every event with those returned by rewrite, and rebuilds the hash
+// chain over the rewritten events. Rebuilding the chain would hide any tampering, so it must verify first. The space
+// used by the old values is then reclaimed, so that they don't remain in the database file. If that fails, the events
+// have still been rewritten, and ErrSpaceNotReclaimed is returned.
+func (s SQLEventStore) Rewrite(ctx context.Context, rewrite func(e app.Event) (app.Event, error)) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	links, err := readChain(ctx, tx)
+	if err != nil {
+		return err
+	}
+	v, err := verifyChain(links, s.hashesOffsets)
+	if err != nil {
+		return err
+	}
+	if b := v.Break; b != nil {
+		return fmt.Errorf("event %d (%s) failed verification: %s: %w", b.Sequence, b.EventID, b.Reason, app.ErrEventChainBroken)
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	prevHash := ""
+	for _, l := range links {
+		if l.event, err = rewrite(l.event); err != nil {
+			return fmt.Errorf("rewriting event [%s]: %w", l.id, err)
+		}
+		l.storedPrev.String = prevHash
+		hash, err := l.hash(s.hashesOffsets)
+		if err != nil {
+			return err
+		}
+		tags, err := encodeTags(l.event.Tags)
+		if err != nil {
+			return err
+		}
+		data, err := encodeData(l.event.Data)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx,
+			"UPDATE `event_store` SET task_name = ?, tags = ?, data = ?, prev_hash = ?, hash = ? WHERE id = ?;",
+			l.event.TaskName, tags, data, prevHash, hash, l.id,
+		); err != nil {
+			return fmt.Errorf("updating event [%s]: %w", l.id, err)
+		}
+		prevHash = hash
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, "VACUUM;"); err != nil {
+		return fmt.Errorf("%s: %w", err, ErrSpaceNotReclaimed)
+	}
+
+	return nil
+}
+
+// chainLink is an event as it is stored in the hash chain.
+type chainLink struct {
+	id                 string
+	event              app.Event
+	sequence           sql.NullInt64
+	storedPrev, stored sql.NullString
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// readChain reads every event in the order it was chained, followed by any events which are not part of the chain.
+func readChain(ctx context.Context, q querier) ([]chainLink, error) {
+	rows, err := q.QueryContext(ctx, "SELECT id, type, task_name, created_at, tags, utc_offset, data, sequence, prev_hash, hash FROM `event_store` ORDER BY sequence IS NULL, sequence ASC;")
+	if err != nil {
+		return nil, fmt.Errorf("querying db: %w", err)
+	}
+	defer rows.Close()
+
+	var links []chainLink
+	for rows.Next() {
+		var (
+			l          chainLink
+			tags, data sql.NullString
+			offset     sql.NullInt64
+		)
+		if err = rows.Scan(&l.id, &l.event.Type, &l.event.TaskName, &l.event.CreatedAt, &tags, &offset, &data, &l.sequence, &l.storedPrev, &l.stored); err != nil {
+			return nil, fmt.Errorf("scanning row: %w", err)
+		}
+		if l.event.Tags, err = decodeTags(tags); err != nil {
+			return nil, fmt.Errorf("event [%s]: %w", l.id, err)
+		}
+		if l.event.Data, err = decodeData(data); err != nil {
+			return nil, fmt.Errorf("event [%s]: %w", l.id, err)
+		}
+		l.event.CreatedAt = l.event.CreatedAt.In(zone(int(offset.Int64)))
+		links = append(links, l)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading rows: %w", err)
+	}
+
+	return links, nil
+}
+
+// hash calculates the hash the event should have been stored with.
+func (l chainLink) hash(withOffset bool) (string, error) {
+	chained := newChainedEvent(l.storedPrev.String, l.event, withOffset)
+	chained.ID = l.id
+	hash, err := chained.hash()
 	if err != nil {
+		return "", fmt.Errorf("hashing event [%s]: %w", l.id, err)
+	}
+	return hash, nil
+}
+
+func verifyChain(links []chainLink, withOffsets bool) (v app.ChainVerification, err error) {
+	var prevSequence int64
+	prevHash := ""
+	for _, l := range links {
+		v.Events++
+		hash, err := l.hash(withOffsets)
+		if err != nil {
+			return v, err
+		}
+
+		reason := ""
+		switch {
+		case !l.sequence.Valid || !l.stored.Valid:
+			reason = "event is not part of the chain, it was inserted directly"
+		case l.sequence.Int64 > prevSequence+1:
+			reason = fmt.Sprintf("expected sequence %d but found %d, preceding events were deleted", prevSequence+1, l.sequence.Int64)
+		case l.storedPrev.String != prevHash:
+			reason = "event does not follow the previous event, events were reordered or deleted"
+		case hash != l.stored.String:
+			reason = "event does not match its hash, it was edited"
+		}
+		if reason != "" {
+			v.Break = &app.ChainBreak{Sequence: l.sequence.Int64, EventID: l.id, Reason: reason}
+			return v, nil
+		}
+
+		prevSequence = l.sequence.Int64
+		prevHash = l.stored.String
+		v.Head = prevHash
+	}
+
+	return v, nil
+}
+
+// bootstrap creates or migrates the event store, and returns the schema version it is at.
+func (s SQLEventStore) bootstrap(ctx context.Context) (version int, err error) {
+	if err = s.db.QueryRowContext(ctx, "PRAGMA user_version;").Scan(&version); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, eventStoreCreation); err != nil {
+		return 0, fmt.Errorf("creating event store: %w", err)
+	}
+
+	existing, err := s.migrateColumns(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("migrating event store: %w", err)
+	}
+
+	if _, err = s.db.ExecContext(ctx, sequenceIndexCreation); err != nil {
+		return 0, fmt.Errorf("creating sequence index: %w", err)
+	}
+
+	if err = s.normaliseTimes(ctx); err != nil {
+		return 0, fmt.Errorf("normalising event times: %w", err)
+	}
+
+	if version < versionLegacyChained {
+		// Only a store created before the hash chain existed holds legacy events. In any other store, events
+		// without a hash were inserted directly, and are left for VerifyChain to report.
+		if err = s.chainLegacy(ctx, !existing["hash"]); err != nil {
+			return 0, fmt.Errorf("chaining existing events: %w", err)
+		}
+		version = versionLegacyChained
+	}
+
+	if version < versionOffsetsHashed {
+		hashed, err := s.hashOffsets(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("hashing utc offsets: %w", err)
+		}
+		if hashed {
+			version = versionOffsetsHashed
+		}
+	}
+
+	return version, nil
+}
+
+// migrateColumns adds any columns missing from an event store created by an earlier version, and returns the columns
+// the store had before.
+func (s SQLEventStore) migrateColumns(ctx context.Context) (map[string]bool, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name FROM pragma_table_info('event_store');`)
+	if err != nil {
+		return nil, fmt.Errorf("querying table info: %w", err)
+	}
+	defer rows.Close()
+
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scanning table info: %w", err)
+		}
+		existing[name] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading table info: %w", err)
+	}
+
+	for _, column := range columnMigrations {
+		if existing[column.name] {
+			continue
+		}
+		if _, err = s.db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE "event_store" ADD COLUMN %s;`, column.definition)); err != nil {
+			return nil, fmt.Errorf("adding column [%s]: %w", column.name, err)
+		}
+	}
+
+	return existing, nil
+}
+
+// normaliseTimes converts the times of events stored before times were normalised to UTC, keeping their original UTC
+// offsets. Event hashes are calculated from the UTC time, so they are unaffected.
+func (s SQLEventStore) normaliseTimes(ctx context.Context) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id, created_at FROM `event_store` WHERE utc_offset IS NULL;")
+	if err != nil {
+		return fmt.Errorf("querying db: %w", err)
+	}
+	type unnormalised struct {
+		id        string
+		createdAt time.Time
+	}
+	var events []unnormalised
+	for rows.Next() {
+		var u unnormalised
+		if err = rows.Scan(&u.id, &u.createdAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning row: %w", err)
+		}
+		events = append(events, u)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("reading rows: %w", err)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	for _, u := range events {
+		_, offset := u.createdAt.Zone()
+		if _, err = tx.ExecContext(ctx,
+			"UPDATE `event_store` SET created_at = ?, utc_offset = ? WHERE id = ?;",
+			u.createdAt.UTC(), offset, u.id,
+		); err != nil {
+			return fmt.Errorf("updating event [%s]: %w", u.id, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// chainLegacy migrates the store to versionLegacyChained. When legacy is set, events stored before the hash chain
+// existed are appended to the chain, oldest first.
+func (s SQLEventStore) chainLegacy(ctx context.Context, legacy bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if legacy {
+		if err = chainUnchained(ctx, tx); err != nil {
+			return err
+		}
+	}
+	if err = setVersion(ctx, tx, versionLegacyChained); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// chainUnchained appends events which are not part of the chain to it, oldest first.
+func chainUnchained(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "SELECT id, type, task_name, created_at, tags, data FROM `event_store` WHERE hash IS NULL ORDER BY created_at ASC, rowid ASC;")
+	if err != nil {
+		return fmt.Errorf("querying db: %w", err)
+	}
+	type unchained struct {
+		id    string
+		event app.Event
+	}
+	var events []unchained
+	for rows.Next() {
+		var (
+			u          unchained
+			tags, data sql.NullString
+		)
+		if err = rows.Scan(&u.id, &u.event.Type, &u.event.TaskName, &u.event.CreatedAt, &tags, &data); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning row: %w", err)
+		}
+		if u.event.Tags, err = decodeTags(tags); err != nil {
+			rows.Close()
+			return fmt.Errorf("event [%s]: %w", u.id, err)
+		}
+		if u.event.Data, err = decodeData(data); err != nil {
+			rows.Close()
+			return fmt.Errorf("event [%s]: %w", u.id, err)
+		}
+		events = append(events, u)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("reading rows: %w", err)
+	}
+
+	sequence, prevHash, err := chainTail(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, u := range events {
+		chained := newChainedEvent(prevHash, u.event, false)
+		chained.ID = u.id
+		hash, err := chained.hash()
+		if err != nil {
+			return fmt.Errorf("hashing event [%s]: %w", u.id, err)
+		}
+		sequence++
+		if _, err = tx.ExecContext(ctx,
+			"UPDATE `event_store` SET sequence = ?, prev_hash = ?, hash = ? WHERE id = ?;",
+			sequence, prevHash, hash, u.id,
+		); err != nil {
+			return fmt.Errorf("updating event [%s]: %w", u.id, err)
+		}
+		prevHash = hash
+	}
+
+	return nil
+}
+
+// hashOffsets migrates the store to versionOffsetsHashed, by rehashing the chain with the UTC offset of each event.
+// Rehashing would hide any tampering, so the chain is only rehashed if it verifies as it was; otherwise the store is
+// left as it is, and reports false.
+func (s SQLEventStore) hashOffsets(ctx context.Context) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	links, err := readChain(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	v, err := verifyChain(links, false)
+	if err != nil {
+		return false, err
+	}
+	if v.Break != nil {
+		return false, nil
+	}
+
+	prevHash := ""
+	for _, l := range links {
+		l.storedPrev.String = prevHash
+		hash, err := l.hash(true)
+		if err != nil {
+			return false, err
+		}
+		if _, err = tx.ExecContext(ctx,
+			"UPDATE `event_store` SET prev_hash = ?, hash = ? WHERE id = ?;",
+			prevHash, hash, l.id,
+		); err != nil {
+			return false, fmt.Errorf("updating event [%s]: %w", l.id, err)
+		}
+		prevHash = hash
+	}
+	if err = setVersion(ctx, tx, versionOffsetsHashed); err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return true, nil
+}
+
+// setVersion records that the store has been migrated to the schema version.
+func setVersion(ctx context.Context, tx *sql.Tx, version int) error {
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d;", version)); err != nil {
+		return fmt.Errorf("recording schema version %d: %w", version, err)
+	}
+	return nil
+}
+
+// chainTail finds the sequence number and hash of the last event in the chain.
+func chainTail(ctx context.Context, tx *sql.Tx) (sequence int64, hash string, err error) {
+	err = tx.QueryRowContext(ctx, "SELECT sequence, hash FROM `event_store` WHERE hash IS NOT NULL ORDER BY sequence DESC LIMIT 1;").Scan(&sequence, &hash)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, "", nil
+	case err != nil:
+		return 0, "", fmt.Errorf("finding last chained event: %w", err)
+	}
+
+	return sequence, hash, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanEvent scans a row selected with eventColumns. The event's time is returned with its original UTC offset. It
+// returns sql.ErrNoRows unwrapped, so that callers can tell when no event was found.
+func scanEvent(row rowScanner) (app.Event, error) {
+	var (
+		event  app.Event
+		id     string
+		tags   sql.NullString
+		offset sql.NullInt64
+		data   sql.NullString
+	)
+	err := row.Scan(&id, &event.Type, &event.TaskName, &event.CreatedAt, &tags, &offset, &data)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return event, err
+	case err != nil:
+		return event, fmt.Errorf("scanning row: %w", err)
+	}
+
+	if event.ID, err = uuid.Parse(id); err != nil {
 		return event, fmt.Errorf("parsing task ID: %w", err)
 	}
-	event.ID = taskID
+	if event.Tags, err = decodeTags(tags); err != nil {
+		return event, fmt.Errorf("event [%s]: %w", id, err)
+	}
+	if event.Data, err = decodeData(data); err != nil {
+		return event, fmt.Errorf("event [%s]: %w", id, err)
+	}
+	if offset.Valid {
+		event.CreatedAt = event.CreatedAt.In(zone(int(offset.Int64)))
+	}
 
 	return event, nil
 }
 
-func (s SQLEventStore) bootstrap(ctx context.Context) error {
-	_, err := s.db.ExecContext(ctx, eventStoreCreation)
+// zone returns a location with the given UTC offset in seconds.
+func zone(offset int) *time.Location {
+	if offset == 0 {
+		return time.UTC
+	}
+	return time.FixedZone("", offset)
+}
+
+// encodeTags stores tags as a JSON array, or NULL if there are none.
+func encodeTags(tags []string) (sql.NullString, error) {
+	if len(tags) == 0 {
+		return sql.NullString{}, nil
+	}
+	encoded, err := json.Marshal(tags)
 	if err != nil {
-		return fmt.Errorf("creating event store: %w", err)
+		return sql.NullString{}, fmt.Errorf("encoding tags: %w", err)
 	}
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
 
-	return nil
+func decodeTags(tags sql.NullString) ([]string, error) {
+	if !tags.Valid || tags.String == "" {
+		return nil, nil
+	}
+	var decoded []string
+	if err := json.Unmarshal([]byte(tags.String), &decoded); err != nil {
+		return nil, fmt.Errorf("decoding tags: %w", err)
+	}
+	return decoded, nil
+}
+
+// encodeData stores data as a JSON object, or NULL if there is none.
+func encodeData(data map[string]string) (sql.NullString, error) {
+	if len(data) == 0 {
+		return sql.NullString{}, nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("encoding data: %w", err)
+	}
+	return sql.NullString{String: string(encoded), Valid: true}, nil
+}
+
+func decodeData(data sql.NullString) (map[string]string, error) {
+	if !data.Valid || data.String == "" {
+		return nil, nil
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(data.String), &decoded); err != nil {
+		return nil, fmt.Errorf("decoding data: %w", err)
+	}
+	return decoded, nil
 }
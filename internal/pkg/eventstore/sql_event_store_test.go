@@ -2,19 +2,15 @@ package eventstore_test
 
 import (
 	"context"
-	"database/sql"
-	"errors"
-	"fmt"
 	"github.com/danmurf/time-tracker/internal/app"
 	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
 	"github.com/google/uuid"
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 	"testing"
 	"time"
 )
 
-func TestSQLEventStore_StoreFetchAll(t *testing.T) {
+func TestSQLEventStore_VerifyChain(t *testing.T) {
 	event1 := app.Event{
 		ID:        uuid.New(),
 		Type:      app.EventTypeTaskStarted,
@@ -32,130 +28,128 @@ func TestSQLEventStore_StoreFetchAll(t *testing.T) {
 		Type:      app.EventTypeTaskStarted,
 		TaskName:  "my-task-2",
 		CreatedAt: time.Now().Add(-2 * time.Minute).Truncate(time.Second).UTC(),
+		Tags:      []string{"acme"},
+	}
+	event4 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeRateSet,
+		CreatedAt: time.Now().Add(-1 * time.Minute).Truncate(time.Second).UTC(),
+		Data:      map[string]string{"tag": "acme", "amount": "120", "currency": "EUR"},
+	}
+	event5 := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskFinished,
+		TaskName:  "my-task-2",
+		CreatedAt: time.Now().Truncate(time.Second).In(time.FixedZone("IST", 5*3600+1800)),
 	}
 	type args struct {
-		store []app.Event
+		store  []app.Event
+		tamper []string
 	}
 	tests := []struct {
-		name string
-		args args
-		want []app.Event
+		name       string
+		args       args
+		wantEvents int
+		wantBreak  *app.ChainBreak
+		wantReason string
 	}{
 		{
-			name: "3 events",
+			name: "untouched chain",
 			args: args{
 				store: []app.Event{event1, event2, event3},
 			},
-			want: []app.Event{event1, event2, event3},
+			wantEvents: 3,
+		},
+		{
+			name:       "empty db",
+			args:       args{},
+			wantEvents: 0,
 		},
 		{
-			name: "1 event",
+			name: "task name edited",
 			args: args{
-				store: []app.Event{event1},
+				store:  []app.Event{event1, event2, event3},
+				tamper: []string{"UPDATE event_store SET task_name = 'other-task' WHERE id = '" + event2.ID.String() + "';"},
 			},
-			want: []app.Event{event1},
+			wantEvents: 2,
+			wantBreak:  &app.ChainBreak{Sequence: 2, EventID: event2.ID.String()},
+			wantReason: "edited",
 		},
 		{
-			name: "0 events",
+			name: "tags edited",
 			args: args{
-				store: []app.Event{},
+				store:  []app.Event{event1, event2, event3},
+				tamper: []string{`UPDATE event_store SET tags = '["other-client"]' WHERE id = '` + event3.ID.String() + "';"},
 			},
-			want: nil,
+			wantEvents: 3,
+			wantBreak:  &app.ChainBreak{Sequence: 3, EventID: event3.ID.String()},
+			wantReason: "edited",
 		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
-			db := newMemorySqliteDB(t)
-			defer db.Close()
-			sut, err := eventstore.NewSQLEventStore(context.Background(), db)
-			assert.NoError(t, err)
-
-			for _, event := range tt.args.store {
-				assert.NoError(t, sut.Store(ctx, event))
-			}
-
-			got, err := sut.FetchAll(ctx)
-			assert.NoError(t, err)
-			assert.ElementsMatch(t, tt.want, got)
-		})
-	}
-}
-
-func TestSQLEventStore_LatestByName(t *testing.T) {
-	event1 := app.Event{
-		ID:        uuid.New(),
-		Type:      app.EventTypeTaskStarted,
-		TaskName:  "my-task-1",
-		CreatedAt: time.Now().Add(-10 * time.Minute).Truncate(time.Second).UTC(),
-	}
-	event2 := app.Event{
-		ID:        uuid.New(),
-		Type:      app.EventTypeTaskFinished,
-		TaskName:  "my-task-1",
-		CreatedAt: time.Now().Add(-5 * time.Minute).Truncate(time.Second).UTC(),
-	}
-	event3 := app.Event{
-		ID:        uuid.New(),
-		Type:      app.EventTypeTaskStarted,
-		TaskName:  "my-task-2",
-		CreatedAt: time.Now().Add(-2 * time.Minute).Truncate(time.Second).UTC(),
-	}
-	type args struct {
-		store []app.Event
-		name  string
-	}
-	tests := []struct {
-		name    string
-		args    args
-		want    app.Event
-		wantErr assert.ErrorAssertionFunc
-	}{
 		{
-			name: "1 event stored",
+			name: "data edited",
 			args: args{
-				store: []app.Event{event1},
-				name:  event1.TaskName,
+				store:  []app.Event{event1, event2, event3, event4},
+				tamper: []string{`UPDATE event_store SET data = '{"tag":"acme","amount":"240","currency":"EUR"}' WHERE id = '` + event4.ID.String() + "';"},
 			},
-			want:    event1,
-			wantErr: assert.NoError,
+			wantEvents: 4,
+			wantBreak:  &app.ChainBreak{Sequence: 4, EventID: event4.ID.String()},
+			wantReason: "edited",
 		},
 		{
-			name: "2 events stored; it returns the latest",
+			name: "created at edited",
 			args: args{
-				store: []app.Event{event1, event2},
-				name:  event1.TaskName,
+				store:  []app.Event{event1, event2, event3},
+				tamper: []string{"UPDATE event_store SET created_at = '2020-01-01 09:00:00+00:00' WHERE id = '" + event1.ID.String() + "';"},
 			},
-			want:    event2,
-			wantErr: assert.NoError,
+			wantEvents: 1,
+			wantBreak:  &app.ChainBreak{Sequence: 1, EventID: event1.ID.String()},
+			wantReason: "edited",
 		},
 		{
-			name: "no events stored matching task name",
+			name: "utc offset edited",
 			args: args{
-				store: []app.Event{event1, event2},
-				name:  event3.TaskName,
+				store:  []app.Event{event1, event3, event5},
+				tamper: []string{"UPDATE event_store SET utc_offset = 0 WHERE id = '" + event5.ID.String() + "';"},
 			},
-			want: app.Event{},
-			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.True(t,
-					errors.Is(err, app.ErrEventNotFound),
-					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
-				)
+			wantEvents: 3,
+			wantBreak:  &app.ChainBreak{Sequence: 3, EventID: event5.ID.String()},
+			wantReason: "edited",
+		},
+		{
+			name: "event deleted",
+			args: args{
+				store:  []app.Event{event1, event2, event3},
+				tamper: []string{"DELETE FROM event_store WHERE id = '" + event2.ID.String() + "';"},
 			},
+			wantEvents: 2,
+			wantBreak:  &app.ChainBreak{Sequence: 3, EventID: event3.ID.String()},
+			wantReason: "deleted",
 		},
 		{
-			name: "empty db",
+			name: "events reordered",
 			args: args{
-				store: []app.Event{},
-				name:  event1.TaskName,
+				store: []app.Event{event1, event2, event3},
+				tamper: []string{
+					"UPDATE event_store SET sequence = 99 WHERE id = '" + event2.ID.String() + "';",
+					"UPDATE event_store SET sequence = 2 WHERE id = '" + event3.ID.String() + "';",
+					"UPDATE event_store SET sequence = 3 WHERE id = '" + event2.ID.String() + "';",
+				},
 			},
-			want: app.Event{},
-			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.True(t,
-					errors.Is(err, app.ErrEventNotFound),
-					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
-				)
+			wantEvents: 2,
+			wantBreak:  &app.ChainBreak{Sequence: 2, EventID: event3.ID.String()},
+			wantReason: "reordered",
+		},
+		{
+			name: "event inserted directly",
+			args: args{
+				store: []app.Event{event1, event2},
+				tamper: []string{
+					"INSERT INTO event_store (id, type, task_name, created_at) VALUES('" + event3.ID.String() + "', 'task-started', 'my-task-2', '2020-01-01 09:00:00+00:00');",
+				},
 			},
+			wantEvents: 3,
+			wantBreak:  &app.ChainBreak{Sequence: 0, EventID: event3.ID.String()},
+			wantReason: "inserted directly",
 		},
 	}
 	for _, tt := range tests {
@@ -163,124 +157,207 @@ func TestSQLEventStore_LatestByName(t *testing.T) {
 			ctx := context.Background()
 			db := newMemorySqliteDB(t)
 			defer db.Close()
-			sut, err := eventstore.NewSQLEventStore(context.Background(), db)
+			sut, err := eventstore.NewSQLEventStore(ctx, db)
 			assert.NoError(t, err)
 
 			for _, event := range tt.args.store {
 				assert.NoError(t, sut.Store(ctx, event), "preparing stored test data")
 			}
+			for _, query := range tt.args.tamper {
+				_, err = db.ExecContext(ctx, query)
+				assert.NoError(t, err, "tampering with stored test data")
+			}
 
-			got, err := sut.LatestByName(ctx, tt.args.name)
-			tt.wantErr(t, err)
-			assert.Equal(t, tt.want, got)
+			// Reopen the store, as verifying does, so that opening it can't repair the tampering.
+			sut, err = eventstore.NewSQLEventStore(ctx, db)
+			assert.NoError(t, err)
+			got, err := sut.VerifyChain(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantEvents, got.Events)
+			if tt.wantBreak == nil {
+				assert.Nil(t, got.Break)
+				return
+			}
+			if assert.NotNil(t, got.Break) {
+				assert.Equal(t, tt.wantBreak.Sequence, got.Break.Sequence)
+				assert.Equal(t, tt.wantBreak.EventID, got.Break.EventID)
+				assert.Contains(t, got.Break.Reason, tt.wantReason)
+			}
 		})
 	}
 }
 
-func TestSQLEventStore_LatestByNameType(t *testing.T) {
-	event1 := app.Event{
+func TestSQLEventStore_ChainsEventsStoredBeforeChaining(t *testing.T) {
+	ctx := context.Background()
+	db := newMemorySqliteDB(t)
+	defer db.Close()
+	legacy := []string{
+		`CREATE TABLE "event_store" ("id" varchar NOT NULL, "type" varchar NOT NULL DEFAULT NULL, "task_name" varchar NOT NULL DEFAULT NULL, "created_at" datetime NOT NULL, PRIMARY KEY (id));`,
+		"INSERT INTO event_store VALUES('" + uuid.NewString() + "', 'task-finished', 'my-task-1', '2022-06-01 10:00:00+01:00');",
+		"INSERT INTO event_store VALUES('" + uuid.NewString() + "', 'task-started', 'my-task-1', '2022-06-01 09:00:00+01:00');",
+	}
+	for _, query := range legacy {
+		_, err := db.ExecContext(ctx, query)
+		assert.NoError(t, err, "preparing legacy event store")
+	}
+
+	sut, err := eventstore.NewSQLEventStore(ctx, db)
+	assert.NoError(t, err)
+	assert.NoError(t, sut.Store(ctx, app.Event{
 		ID:        uuid.New(),
 		Type:      app.EventTypeTaskStarted,
 		TaskName:  "my-task-1",
-		CreatedAt: time.Now().Add(-10 * time.Minute).Truncate(time.Second).UTC(),
-	}
-	event2 := app.Event{
+		CreatedAt: time.Now().UTC(),
+	}))
+
+	got, err := sut.VerifyChain(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, got.Events)
+	assert.Nil(t, got.Break)
+	assert.NotEmpty(t, got.Head)
+
+	var firstType string
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT type FROM event_store WHERE sequence = 1;").Scan(&firstType))
+	assert.Equal(t, string(app.EventTypeTaskStarted), firstType, "existing events should be chained oldest first")
+}
+
+func TestSQLEventStore_DoesNotChainEventsInsertedDirectly(t *testing.T) {
+	ctx := context.Background()
+	db := newMemorySqliteDB(t)
+	defer db.Close()
+	sut, err := eventstore.NewSQLEventStore(ctx, db)
+	assert.NoError(t, err)
+	assert.NoError(t, sut.Store(ctx, app.Event{
 		ID:        uuid.New(),
-		Type:      app.EventTypeTaskFinished,
+		Type:      app.EventTypeTaskStarted,
 		TaskName:  "my-task-1",
-		CreatedAt: time.Now().Add(-5 * time.Minute).Truncate(time.Second).UTC(),
+		CreatedAt: time.Now().Truncate(time.Second).UTC(),
+	}))
+
+	// A store chained by a version which didn't record its schema version, with an event inserted directly since.
+	forged := uuid.NewString()
+	for _, query := range []string{
+		"INSERT INTO event_store (id, type, task_name, created_at) VALUES('" + forged + "', 'task-finished', 'my-task-1', '2020-01-01 09:00:00+00:00');",
+		"PRAGMA user_version = 0;",
+	} {
+		_, err = db.ExecContext(ctx, query)
+		assert.NoError(t, err, "preparing tampered event store")
 	}
-	event3 := app.Event{
+
+	for i := 0; i < 2; i++ {
+		sut, err = eventstore.NewSQLEventStore(ctx, db)
+		assert.NoError(t, err)
+		got, err := sut.VerifyChain(ctx)
+		assert.NoError(t, err)
+		if assert.NotNil(t, got.Break, "reopening the store should not chain the forged event") {
+			assert.Equal(t, forged, got.Break.EventID)
+			assert.Contains(t, got.Break.Reason, "inserted directly")
+		}
+	}
+}
+
+func TestSQLEventStore_ReopenKeepsChain(t *testing.T) {
+	ctx := context.Background()
+	db := newMemorySqliteDB(t)
+	defer db.Close()
+
+	for i := 0; i < 2; i++ {
+		sut, err := eventstore.NewSQLEventStore(ctx, db)
+		assert.NoError(t, err)
+		assert.NoError(t, sut.Store(ctx, app.Event{
+			ID:        uuid.New(),
+			Type:      app.EventTypeTaskStarted,
+			TaskName:  "my-task-1",
+			CreatedAt: time.Now().UTC(),
+		}))
+	}
+
+	sut, err := eventstore.NewSQLEventStore(ctx, db)
+	assert.NoError(t, err)
+	got, err := sut.VerifyChain(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, got.Events)
+	assert.Nil(t, got.Break)
+}
+
+func TestSQLEventStore_OrdersEventsAcrossTimeZones(t *testing.T) {
+	ctx := context.Background()
+	db := newMemorySqliteDB(t)
+	defer db.Close()
+	sut, err := eventstore.NewSQLEventStore(ctx, db)
+	assert.NoError(t, err)
+
+	// Started in India, then finished 30 minutes later in London. Compared as local times, the finish looks earlier.
+	started := app.Event{
 		ID:        uuid.New(),
 		Type:      app.EventTypeTaskStarted,
-		TaskName:  "my-task-2",
-		CreatedAt: time.Now().Add(-2 * time.Minute).Truncate(time.Second).UTC(),
-	}
-	type args struct {
-		store     []app.Event
-		name      string
-		eventType app.EventType
+		TaskName:  "my-task-1",
+		CreatedAt: time.Date(2022, 6, 1, 10, 0, 0, 0, time.FixedZone("IST", 5*3600+1800)),
 	}
-	tests := []struct {
-		name    string
-		args    args
-		want    app.Event
-		wantErr assert.ErrorAssertionFunc
-	}{
-		{
-			name: "1 completed task, get the started event",
-			args: args{
-				store:     []app.Event{event1, event2},
-				name:      event1.TaskName,
-				eventType: app.EventTypeTaskStarted,
-			},
-			want:    event1,
-			wantErr: assert.NoError,
-		},
-		{
-			name: "1 completed task, get the finished event",
-			args: args{
-				store:     []app.Event{event1, event2},
-				name:      event1.TaskName,
-				eventType: app.EventTypeTaskFinished,
-			},
-			want:    event2,
-			wantErr: assert.NoError,
-		},
-		{
-			name: "1 started task, try to get the finished event",
-			args: args{
-				store:     []app.Event{event3},
-				name:      event3.TaskName,
-				eventType: app.EventTypeTaskFinished,
-			},
-			want: app.Event{},
-			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.True(t,
-					errors.Is(err, app.ErrEventNotFound),
-					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
-				)
-			},
-		},
-		{
-			name: "empty db",
-			args: args{
-				store: []app.Event{},
-				name:  event1.TaskName,
-			},
-			want: app.Event{},
-			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.True(t,
-					errors.Is(err, app.ErrEventNotFound),
-					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventNotFound, err),
-				)
-			},
-		},
+	finished := app.Event{
+		ID:        uuid.New(),
+		Type:      app.EventTypeTaskFinished,
+		TaskName:  "my-task-1",
+		CreatedAt: time.Date(2022, 6, 1, 6, 0, 0, 0, time.FixedZone("BST", 3600)),
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
-			db := newMemorySqliteDB(t)
-			defer db.Close()
-			sut, err := eventstore.NewSQLEventStore(context.Background(), db)
-			assert.NoError(t, err)
+	assert.NoError(t, sut.Store(ctx, started))
+	assert.NoError(t, sut.Store(ctx, finished))
 
-			for _, event := range tt.args.store {
-				assert.NoError(t, sut.Store(ctx, event), "preparing stored test data")
-			}
+	latest, err := sut.LatestByName(ctx, "my-task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, finished.ID, latest.ID)
 
-			got, err := sut.LatestByNameType(ctx, tt.args.name, tt.args.eventType)
-			tt.wantErr(t, err)
-			assert.Equal(t, tt.want, got)
-		})
+	all, err := sut.FetchAll(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, all, 2) {
+		assert.Equal(t, finished.ID, all[0].ID)
+		assert.True(t, started.CreatedAt.Equal(all[1].CreatedAt))
+		_, offset := all[1].CreatedAt.Zone()
+		assert.Equal(t, 5*3600+1800, offset, "the original UTC offset should be kept")
 	}
+
+	var stored string
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT created_at || '' FROM event_store WHERE id = ?;", started.ID.String()).Scan(&stored))
+	assert.Equal(t, "2022-06-01 04:30:00+00:00", stored, "times should be stored in UTC")
 }
 
-func newMemorySqliteDB(t *testing.T) *sql.DB {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Errorf("opening in memory sqlite database: %s", err)
-		t.FailNow()
+func TestSQLEventStore_NormalisesTimesStoredBeforeUTC(t *testing.T) {
+	ctx := context.Background()
+	db := newMemorySqliteDB(t)
+	defer db.Close()
+	started, finished := uuid.NewString(), uuid.NewString()
+	legacy := []string{
+		`CREATE TABLE "event_store" ("id" varchar NOT NULL, "type" varchar NOT NULL DEFAULT NULL, "task_name" varchar NOT NULL DEFAULT NULL, "created_at" datetime NOT NULL, PRIMARY KEY (id));`,
+		"INSERT INTO event_store VALUES('" + started + "', 'task-started', 'my-task-1', '2022-06-01 10:00:00+05:30');",
+		"INSERT INTO event_store VALUES('" + finished + "', 'task-finished', 'my-task-1', '2022-06-01 06:00:00+01:00');",
+	}
+	for _, query := range legacy {
+		_, err := db.ExecContext(ctx, query)
+		assert.NoError(t, err, "preparing legacy event store")
+	}
+
+	sut, err := eventstore.NewSQLEventStore(ctx, db)
+	assert.NoError(t, err)
+
+	latest, err := sut.LatestByName(ctx, "my-task-1")
+	assert.NoError(t, err)
+	assert.Equal(t, finished, latest.ID.String())
+	_, offset := latest.CreatedAt.Zone()
+	assert.Equal(t, 3600, offset, "the original UTC offset should be kept")
+
+	var firstID string
+	assert.NoError(t, db.QueryRowContext(ctx, "SELECT id FROM event_store WHERE sequence = 1;").Scan(&firstID))
+	assert.Equal(t, started, firstID, "existing events should be chained in the order they happened")
+
+	verification, err := sut.VerifyChain(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, verification.Break)
+
+	_, err = db.ExecContext(ctx, "UPDATE event_store SET utc_offset = 0 WHERE id = ?;", started)
+	assert.NoError(t, err, "tampering with stored test data")
+	verification, err = sut.VerifyChain(ctx)
+	assert.NoError(t, err)
+	if assert.NotNil(t, verification.Break, "utc offsets of existing events should be hashed") {
+		assert.Equal(t, started, verification.Break.EventID)
 	}
-	return db
 }
@@ -0,0 +1,185 @@
+package gitsync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/ndjson"
+	"github.com/google/uuid"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var _ app.SyncableEventStore = (*Repository)(nil)
+
+const (
+	eventsDir = "events"
+	// attributes makes git merge event files by keeping the lines from both sides, rather than conflicting when two
+	// clones add events on the same day. Any duplicated events are ignored when the files are read.
+	attributes = "*.ndjson merge=union\n"
+)
+
+// Repository stores events as newline delimited JSON in a git working tree, in one file per (UTC) day. Events from
+// other clones are brought in by Pull, and local events are shared by Commit and Push.
+type Repository struct {
+	dir string
+}
+
+// Open opens the git repository at dir, creating it if needed.
+func Open(ctx context.Context, dir string) (Repository, error) {
+	r := Repository{dir: dir}
+	if err := os.MkdirAll(filepath.Join(dir, eventsDir), os.ModePerm); err != nil {
+		return r, fmt.Errorf("creating events directory: %w", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err = r.git(ctx, "init"); err != nil {
+			return r, fmt.Errorf("initialising repository: %w", err)
+		}
+	}
+
+	return r, nil
+}
+
+// Store appends the event to the file for the day it was created.
+func (r Repository) Store(_ context.Context, e app.Event) error {
+	path := filepath.Join(r.dir, eventsDir, e.CreatedAt.UTC().Format("2006-01-02")+".ndjson")
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening [%s]: %w", path, err)
+	}
+	defer f.Close()
+
+	if err = ndjson.WriteEvents(f, []app.Event{e}); err != nil {
+		return fmt.Errorf("writing [%s]: %w", path, err)
+	}
+
+	return nil
+}
+
+// FetchAll reads the events from every file, newest first. Events which appear more than once are only returned once.
+func (r Repository) FetchAll(_ context.Context) ([]app.Event, error) {
+	paths, err := filepath.Glob(filepath.Join(r.dir, eventsDir, "*.ndjson"))
+	if err != nil {
+		return nil, fmt.Errorf("listing event files: %w", err)
+	}
+
+	var events []app.Event
+	seen := map[uuid.UUID]bool{}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening [%s]: %w", path, err)
+		}
+		fileEvents, err := ndjson.ReadEvents(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading [%s]: %w", path, err)
+		}
+		for _, e := range fileEvents {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				events = append(events, e)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	return events, nil
+}
+
+// HasRemote reports whether the repository has an origin remote to pull from and push to.
+func (r Repository) HasRemote(ctx context.Context) (bool, error) {
+	out, err := r.git(ctx, "remote")
+	if err != nil {
+		return false, fmt.Errorf("listing remotes: %w", err)
+	}
+	for _, remote := range strings.Fields(out) {
+		if remote == "origin" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Pull fetches from origin and merges in the current branch's counterpart, if origin has one yet. Clones which each
+// committed events before either of them pushed have unrelated histories, which are merged too.
+func (r Repository) Pull(ctx context.Context) error {
+	if _, err := r.git(ctx, "fetch", "origin"); err != nil {
+		return fmt.Errorf("fetching: %w", err)
+	}
+
+	branch, err := r.git(ctx, "symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return fmt.Errorf("finding current branch: %w", err)
+	}
+	upstream := "origin/" + strings.TrimSpace(branch)
+	if _, err = r.git(ctx, "rev-parse", "--verify", "--quiet", upstream); err != nil {
+		// Nothing has been pushed to this branch yet
+		return nil
+	}
+
+	if _, err = r.git(ctx, append(r.identity(ctx), "merge", "--no-edit", "--allow-unrelated-histories", upstream)...); err != nil {
+		return fmt.Errorf("merging [%s]: %w", upstream, err)
+	}
+
+	return nil
+}
+
+// Commit commits every change to the event files, if there are any. It returns whether a commit was made.
+func (r Repository) Commit(ctx context.Context, message string) (bool, error) {
+	// The attributes are only written when committing, so that they can't get in the way of pulling them from origin
+	attributesPath := filepath.Join(r.dir, ".gitattributes")
+	if _, err := os.Stat(attributesPath); os.IsNotExist(err) {
+		if err = os.WriteFile(attributesPath, []byte(attributes), 0644); err != nil {
+			return false, fmt.Errorf("writing git attributes: %w", err)
+		}
+	}
+
+	if _, err := r.git(ctx, "add", "--", ".gitattributes", eventsDir); err != nil {
+		return false, fmt.Errorf("staging events: %w", err)
+	}
+
+	if _, err := r.git(ctx, "diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+
+	if _, err := r.git(ctx, append(r.identity(ctx), "commit", "--quiet", "-m", message)...); err != nil {
+		return false, fmt.Errorf("committing events: %w", err)
+	}
+
+	return true, nil
+}
+
+// Push pushes the current branch to origin.
+func (r Repository) Push(ctx context.Context) error {
+	if _, err := r.git(ctx, "push", "--quiet", "--set-upstream", "origin", "HEAD"); err != nil {
+		return fmt.Errorf("pushing: %w", err)
+	}
+	return nil
+}
+
+// identity returns the options needed to give commits an author when git hasn't been configured with one.
+func (r Repository) identity(ctx context.Context) []string {
+	if email, _ := r.git(ctx, "config", "user.email"); strings.TrimSpace(email) != "" {
+		return nil
+	}
+	return []string{"-c", "user.name=time-tracker", "-c", "user.email=time-tracker@localhost"}
+}
+
+func (r Repository) git(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, "git", append([]string{"-C", r.dir}, args...)...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		return stdout.String(), fmt.Errorf("git %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,112 @@
+package gitsync_test
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/gitsync"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRepository_StoreFetchAll(t *testing.T) {
+	requireGit(t)
+	ctx := context.Background()
+	sut, err := gitsync.Open(ctx, t.TempDir())
+	assert.NoError(t, err)
+
+	day1 := newEvent(app.EventTypeTaskStarted, "my-task-1", time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC))
+	day2 := newEvent(app.EventTypeTaskFinished, "my-task-1", time.Date(2022, 6, 2, 9, 0, 0, 0, time.UTC))
+	assert.NoError(t, sut.Store(ctx, day1))
+	assert.NoError(t, sut.Store(ctx, day2))
+	assert.NoError(t, sut.Store(ctx, day1), "storing a duplicate")
+
+	got, err := sut.FetchAll(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []app.Event{day2, day1}, got)
+
+	committed, err := sut.Commit(ctx, "events")
+	assert.NoError(t, err)
+	assert.True(t, committed)
+	committed, err = sut.Commit(ctx, "nothing new")
+	assert.NoError(t, err)
+	assert.False(t, committed)
+}
+
+func TestRepository_SyncBetweenClones(t *testing.T) {
+	requireGit(t)
+	ctx := context.Background()
+	bare := filepath.Join(t.TempDir(), "events.git")
+	gitRun(t, "", "init", "--quiet", "--bare", bare)
+	laptopDir := filepath.Join(t.TempDir(), "laptop")
+	desktopDir := filepath.Join(t.TempDir(), "desktop")
+	gitRun(t, "", "clone", "--quiet", bare, laptopDir)
+	gitRun(t, "", "clone", "--quiet", bare, desktopDir)
+
+	laptop, err := gitsync.Open(ctx, laptopDir)
+	assert.NoError(t, err)
+	desktop, err := gitsync.Open(ctx, desktopDir)
+	assert.NoError(t, err)
+
+	// Both clones add events to the same day's file.
+	day := time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC)
+	laptopEvent := newEvent(app.EventTypeTaskStarted, "laptop-task", day)
+	desktopEvent := newEvent(app.EventTypeTaskStarted, "desktop-task", day.Add(time.Hour))
+
+	hasRemote, err := laptop.HasRemote(ctx)
+	assert.NoError(t, err)
+	assert.True(t, hasRemote)
+
+	assert.NoError(t, laptop.Pull(ctx), "pulling before anything was pushed")
+	assert.NoError(t, laptop.Store(ctx, laptopEvent))
+	_, err = laptop.Commit(ctx, "laptop events")
+	assert.NoError(t, err)
+	assert.NoError(t, laptop.Push(ctx))
+
+	assert.NoError(t, desktop.Store(ctx, desktopEvent))
+	_, err = desktop.Commit(ctx, "desktop events")
+	assert.NoError(t, err)
+	assert.NoError(t, desktop.Pull(ctx), "merging the same day's file")
+	assert.NoError(t, desktop.Push(ctx))
+
+	assert.NoError(t, laptop.Pull(ctx))
+
+	for name, repo := range map[string]gitsync.Repository{"laptop": laptop, "desktop": desktop} {
+		got, err := repo.FetchAll(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []app.Event{desktopEvent, laptopEvent}, got, name)
+	}
+}
+
+func TestRepository_HasRemote(t *testing.T) {
+	requireGit(t)
+	ctx := context.Background()
+	sut, err := gitsync.Open(ctx, t.TempDir())
+	assert.NoError(t, err)
+
+	got, err := sut.HasRemote(ctx)
+	assert.NoError(t, err)
+	assert.False(t, got)
+}
+
+func newEvent(eventType app.EventType, taskName string, createdAt time.Time) app.Event {
+	return app.Event{ID: uuid.New(), Type: eventType, TaskName: taskName, CreatedAt: createdAt}
+}
+
+func requireGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	c := exec.Command("git", args...)
+	c.Dir = dir
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Errorf("git %v: %s: %s", args, err, out)
+		t.FailNow()
+	}
+}
@@ -0,0 +1,144 @@
+// Package ical reads the events in iCalendar (RFC 5545) files, such as the lists of public holidays published for
+// each country, as days.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is an event in a calendar, from the day it starts up to but not including the day it ends. Days are at
+// midnight UTC, as holidays are the same calendar days wherever they are seen from.
+type Event struct {
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Days returns each day of the event.
+func (e Event) Days() []time.Time {
+	var days []time.Time
+	for day := e.Start; day.Before(e.End); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// ReadEvents reads every event in a calendar. Events which start and end at a time, rather than on a date, are taken
+// to be for the whole of the days they fall on. Recurring events are read as their first occurrence only.
+func ReadEvents(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []Event
+		current *Event
+		hasEnd  bool
+		// nested is how deep inside components within the event, such as alarms, the line is.
+		nested int
+	)
+	for i, line := range lines {
+		name, value, ok := split(line)
+		if !ok {
+			return nil, fmt.Errorf("line %d isn't a property: [%s]", i+1, line)
+		}
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			current, hasEnd, nested = &Event{}, false, 0
+		case current == nil:
+		case name == "BEGIN":
+			nested++
+		case name == "END" && nested > 0:
+			nested--
+		case nested > 0:
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if current.Start.IsZero() {
+				return nil, fmt.Errorf("event [%s] ending on line %d has no DTSTART", current.Summary, i+1)
+			}
+			if !hasEnd || !current.End.After(current.Start) {
+				current.End = current.Start.AddDate(0, 0, 1)
+			}
+			events = append(events, *current)
+			current = nil
+		case name == "SUMMARY":
+			current.Summary = unescape(value)
+		case name == "DTSTART":
+			if current.Start, err = parseDate(value); err != nil {
+				return nil, fmt.Errorf("parsing DTSTART on line %d: %w", i+1, err)
+			}
+		case name == "DTEND":
+			end, err := parseDate(value)
+			if err != nil {
+				return nil, fmt.Errorf("parsing DTEND on line %d: %w", i+1, err)
+			}
+			// An end date is already exclusive, but an end time falls on a day of the event unless it's midnight.
+			if len(value) > 9 && strings.TrimSuffix(value[9:], "Z") != "000000" {
+				end = end.AddDate(0, 0, 1)
+			}
+			current.End, hasEnd = end, true
+		}
+	}
+	if current != nil {
+		return nil, fmt.Errorf("event [%s] has no END:VEVENT", current.Summary)
+	}
+
+	return events, nil
+}
+
+// unfold reads the lines of a calendar, joining lines which were folded by starting the rest of them with a space
+// or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case line == "":
+		case (line[0] == ' ' || line[0] == '\t') && len(lines) > 0:
+			lines[len(lines)-1] += line[1:]
+		default:
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// split splits a content line such as `DTSTART;VALUE=DATE:20261225` into its name and value, leaving out any
+// parameters.
+func split(line string) (name, value string, ok bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", "", false
+	}
+	name, value = line[:colon], line[colon+1:]
+	if semicolon := strings.Index(name, ";"); semicolon >= 0 {
+		name = name[:semicolon]
+	}
+	return strings.ToUpper(name), value, true
+}
+
+// parseDate parses a date such as 20261225, or the date of a date and time such as 20261225T090000Z.
+func parseDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date [%s]", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date [%s]: %w", value, err)
+	}
+	return date, nil
+}
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, `;`, `\,`, `,`, `\n`, "\n", `\N`, "\n")
+
+func unescape(text string) string {
+	return unescaper.Replace(text)
+}
@@ -0,0 +1,49 @@
+package ical_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/pkg/ical"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestReadEvents(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "holidays.ics"))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	got, err := ical.ReadEvents(f)
+	assert.NoError(t, err)
+	assert.Equal(t, []ical.Event{
+		{Summary: "Christmas Day", Start: date(2026, 12, 25), End: date(2026, 12, 26)},
+		{Summary: "Boxing Day, or St Stephen's Day", Start: date(2026, 12, 26), End: date(2026, 12, 27)},
+		{Summary: "New Year's Eve and Day", Start: date(2026, 12, 31), End: date(2027, 1, 2)},
+		{Summary: "Summer bank holiday", Start: date(2026, 8, 31), End: date(2026, 9, 1)},
+	}, got)
+	assert.Equal(t, []time.Time{date(2026, 12, 31), date(2027, 1, 1)}, got[2].Days())
+}
+
+func TestReadEvents_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		calendar string
+	}{
+		{name: "not a calendar", calendar: "hello\n"},
+		{name: "no start", calendar: "BEGIN:VEVENT\nSUMMARY:Holiday\nEND:VEVENT\n"},
+		{name: "invalid start", calendar: "BEGIN:VEVENT\nDTSTART:2026-12-25\nEND:VEVENT\n"},
+		{name: "unfinished event", calendar: "BEGIN:VEVENT\nDTSTART:20261225\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ical.ReadEvents(strings.NewReader(tt.calendar))
+			assert.Error(t, err)
+		})
+	}
+}
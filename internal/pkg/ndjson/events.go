@@ -0,0 +1,77 @@
+package ndjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"io"
+	"time"
+)
+
+// record is the stable representation of an event in newline delimited JSON. It is deliberately independent of
+// app.Event and of the event store schema, so that exported events can be read back by future versions.
+type record struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	TaskName  string            `json:"task_name"`
+	CreatedAt time.Time         `json:"created_at"`
+	Tags      []string          `json:"tags,omitempty"`
+	Data      map[string]string `json:"data,omitempty"`
+}
+
+// WriteEvents writes each event to w as a single line of JSON.
+func WriteEvents(w io.Writer, events []app.Event) error {
+	encoder := json.NewEncoder(w)
+	for _, e := range events {
+		if err := encoder.Encode(record{
+			ID:        e.ID.String(),
+			Type:      string(e.Type),
+			TaskName:  e.TaskName,
+			CreatedAt: e.CreatedAt,
+			Tags:      e.Tags,
+			Data:      e.Data,
+		}); err != nil {
+			return fmt.Errorf("encoding event [%s]: %w", e.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// ReadEvents reads events written by WriteEvents, in the order they were written. Blank lines are skipped.
+func ReadEvents(r io.Reader) ([]app.Event, error) {
+	var events []app.Event
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("decoding line %d: %w", line, err)
+		}
+		id, err := uuid.Parse(rec.ID)
+		if err != nil {
+			return nil, fmt.Errorf("parsing event ID on line %d: %w", line, err)
+		}
+		events = append(events, app.Event{
+			ID:        id,
+			Type:      app.EventType(rec.Type),
+			TaskName:  rec.TaskName,
+			CreatedAt: rec.CreatedAt,
+			Tags:      rec.Tags,
+			Data:      rec.Data,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading lines: %w", err)
+	}
+
+	return events, nil
+}
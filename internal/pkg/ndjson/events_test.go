@@ -0,0 +1,99 @@
+package ndjson_test
+
+import (
+	"bytes"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/ndjson"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteReadEvents(t *testing.T) {
+	events := []app.Event{
+		{
+			ID:        uuid.New(),
+			Type:      app.EventTypeTaskStarted,
+			TaskName:  "my-task-1",
+			CreatedAt: time.Date(2022, 6, 1, 9, 0, 0, 123456789, time.UTC),
+			Tags:      []string{"acme"},
+		},
+		{
+			ID:        uuid.New(),
+			Type:      app.EventTypeTaskFinished,
+			TaskName:  "my-task-1",
+			CreatedAt: time.Date(2022, 6, 1, 10, 30, 0, 0, time.FixedZone("", 3600)),
+		},
+		{
+			ID:        uuid.New(),
+			Type:      app.EventTypeRateSet,
+			CreatedAt: time.Date(2022, 6, 1, 11, 0, 0, 0, time.UTC),
+			Data:      map[string]string{"tag": "acme", "amount": "120", "currency": "EUR"},
+		},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, ndjson.WriteEvents(&buf, events))
+	assert.Equal(t, 3, strings.Count(buf.String(), "\n"), "each event should be written on its own line")
+
+	got, err := ndjson.ReadEvents(&buf)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 3) {
+		for i := range events {
+			assert.Equal(t, events[i].ID, got[i].ID)
+			assert.Equal(t, events[i].Type, got[i].Type)
+			assert.Equal(t, events[i].TaskName, got[i].TaskName)
+			assert.Equal(t, events[i].Tags, got[i].Tags)
+			assert.Equal(t, events[i].Data, got[i].Data)
+			assert.True(t, events[i].CreatedAt.Equal(got[i].CreatedAt))
+		}
+	}
+}
+
+func TestReadEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []app.Event
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "stable format",
+			input: `{"id":"1b4e28ba-2fa1-11d2-883f-0016d3cca427","type":"task-started","task_name":"my-task-1","created_at":"2022-06-01T09:00:00Z"}` + "\n",
+			want: []app.Event{{
+				ID:        uuid.MustParse("1b4e28ba-2fa1-11d2-883f-0016d3cca427"),
+				Type:      app.EventTypeTaskStarted,
+				TaskName:  "my-task-1",
+				CreatedAt: time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC),
+			}},
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "blank lines",
+			input:   "\n\n",
+			want:    nil,
+			wantErr: assert.NoError,
+		},
+		{
+			name:    "invalid json",
+			input:   "{not json\n",
+			want:    nil,
+			wantErr: assert.Error,
+		},
+		{
+			name:    "invalid id",
+			input:   `{"id":"nope","type":"task-started","task_name":"my-task-1","created_at":"2022-06-01T09:00:00Z"}` + "\n",
+			want:    nil,
+			wantErr: assert.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ndjson.ReadEvents(strings.NewReader(tt.input))
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
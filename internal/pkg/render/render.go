@@ -0,0 +1,160 @@
+package render
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"html"
+	"io"
+	"strings"
+)
+
+const (
+	FormatText     = Format("text")
+	FormatJSON     = Format("json")
+	FormatCSV      = Format("csv")
+	FormatYAML     = Format("yaml")
+	FormatMarkdown = Format("markdown")
+	FormatHTML     = Format("html")
+)
+
+// Format is an output format that views can be rendered in.
+type Format string
+
+// Formats lists every supported format.
+var Formats = []Format{FormatText, FormatJSON, FormatCSV, FormatYAML, FormatMarkdown, FormatHTML}
+
+// View is the output of a command. Views are rendered as JSON and YAML using their struct tags, so they should only
+// contain plain data, with times as time.Time (RFC 3339) and durations as seconds.
+type View interface {
+	// Text returns the human readable version of the view.
+	Text() string
+	// Header returns the CSV and Markdown column names, which must line up with each of the Rows.
+	Header() []string
+	// Rows returns the CSV records and Markdown table rows.
+	Rows() [][]string
+}
+
+// Document is implemented by views which are documents, such as invoices, rather than tables. Their Markdown and HTML
+// are rendered as they are, instead of as a table of the Rows.
+type Document interface {
+	Markdown() string
+	HTML() string
+}
+
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown output format [%s], must be one of %s", s, strings.Join(names, ", "))
+}
+
+// Render writes the view to w in the given format.
+func Render(w io.Writer, format Format, view View) error {
+	switch format {
+	case FormatText:
+		text := view.Text()
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if _, err := io.WriteString(w, text); err != nil {
+			return fmt.Errorf("writing text: %w", err)
+		}
+	case FormatJSON:
+		if err := json.NewEncoder(w).Encode(view); err != nil {
+			return fmt.Errorf("encoding json: %w", err)
+		}
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(view.Header()); err != nil {
+			return fmt.Errorf("writing csv header: %w", err)
+		}
+		if err := writer.WriteAll(view.Rows()); err != nil {
+			return fmt.Errorf("writing csv rows: %w", err)
+		}
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		if err := encoder.Encode(view); err != nil {
+			return fmt.Errorf("encoding yaml: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("encoding yaml: %w", err)
+		}
+	case FormatMarkdown:
+		markdown := markdownTable(view.Header(), view.Rows())
+		if document, ok := view.(Document); ok {
+			markdown = document.Markdown()
+		}
+		if _, err := io.WriteString(w, markdown); err != nil {
+			return fmt.Errorf("writing markdown: %w", err)
+		}
+	case FormatHTML:
+		html := htmlTable(view.Header(), view.Rows())
+		if document, ok := view.(Document); ok {
+			html = document.HTML()
+		}
+		if _, err := io.WriteString(w, html); err != nil {
+			return fmt.Errorf("writing html: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown output format [%s]", format)
+	}
+
+	return nil
+}
+
+// markdownTable formats a GitHub flavoured Markdown table.
+func markdownTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, cell := range cells {
+			cell = strings.ReplaceAll(cell, "|", `\|`)
+			cell = strings.ReplaceAll(cell, "\n", " ")
+			b.WriteString(" " + cell + " |")
+		}
+		b.WriteString("\n")
+	}
+
+	writeRow(header)
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeRow(separator)
+	for _, row := range rows {
+		writeRow(row)
+	}
+
+	return b.String()
+}
+
+// htmlTable formats an HTML table, with the header in the table head.
+func htmlTable(header []string, rows [][]string) string {
+	var b strings.Builder
+	writeRow := func(cell string, cells []string) {
+		b.WriteString("<tr>")
+		for _, c := range cells {
+			b.WriteString("<" + cell + ">" + html.EscapeString(c) + "</" + cell + ">")
+		}
+		b.WriteString("</tr>\n")
+	}
+
+	b.WriteString("<table>\n<thead>\n")
+	writeRow("th", header)
+	b.WriteString("</thead>\n<tbody>\n")
+	for _, row := range rows {
+		writeRow("td", row)
+	}
+	b.WriteString("</tbody>\n</table>\n")
+
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package render_test
+
+import (
+	"bytes"
+	"github.com/danmurf/time-tracker/internal/pkg/render"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type testView struct {
+	Task            string    `json:"task" yaml:"task"`
+	StartedAt       time.Time `json:"started_at" yaml:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds" yaml:"duration_seconds"`
+}
+
+func (v testView) Text() string {
+	return "⏱  " + v.Task + " took a while"
+}
+
+func (v testView) Header() []string {
+	return []string{"task", "started_at", "duration_seconds"}
+}
+
+func (v testView) Rows() [][]string {
+	return [][]string{{v.Task, v.StartedAt.Format(time.RFC3339), "90"}}
+}
+
+func TestRender(t *testing.T) {
+	view := testView{
+		Task:            "my, task",
+		StartedAt:       time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC),
+		DurationSeconds: 90,
+	}
+	tests := []struct {
+		name   string
+		format render.Format
+		want   string
+	}{
+		{
+			name:   "text",
+			format: render.FormatText,
+			want:   "⏱  my, task took a while\n",
+		},
+		{
+			name:   "json",
+			format: render.FormatJSON,
+			want:   `{"task":"my, task","started_at":"2022-06-01T09:00:00Z","duration_seconds":90}` + "\n",
+		},
+		{
+			name:   "csv",
+			format: render.FormatCSV,
+			want:   "task,started_at,duration_seconds\n\"my, task\",2022-06-01T09:00:00Z,90\n",
+		},
+		{
+			name:   "yaml",
+			format: render.FormatYAML,
+			want:   "task: my, task\nstarted_at: 2022-06-01T09:00:00Z\nduration_seconds: 90\n",
+		},
+		{
+			name:   "markdown",
+			format: render.FormatMarkdown,
+			want:   "| task | started_at | duration_seconds |\n| --- | --- | --- |\n| my, task | 2022-06-01T09:00:00Z | 90 |\n",
+		},
+		{
+			name:   "html",
+			format: render.FormatHTML,
+			want:   "<table>\n<thead>\n<tr><th>task</th><th>started_at</th><th>duration_seconds</th></tr>\n</thead>\n<tbody>\n<tr><td>my, task</td><td>2022-06-01T09:00:00Z</td><td>90</td></tr>\n</tbody>\n</table>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, render.Render(&buf, tt.format, view))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+type testDocument struct {
+	testView
+}
+
+func (d testDocument) Markdown() string {
+	return "# Invoice <1>\n"
+}
+
+func (d testDocument) HTML() string {
+	return "<h1>Invoice &lt;1&gt;</h1>\n"
+}
+
+func TestRender_Document(t *testing.T) {
+	document := testDocument{testView{Task: "my-task"}}
+
+	var markdown bytes.Buffer
+	assert.NoError(t, render.Render(&markdown, render.FormatMarkdown, document))
+	assert.Equal(t, "# Invoice <1>\n", markdown.String())
+
+	var html bytes.Buffer
+	assert.NoError(t, render.Render(&html, render.FormatHTML, document))
+	assert.Equal(t, "<h1>Invoice &lt;1&gt;</h1>\n", html.String())
+
+	var csv bytes.Buffer
+	assert.NoError(t, render.Render(&csv, render.FormatCSV, document))
+	assert.Contains(t, csv.String(), "my-task", "documents should still have rows for csv")
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range render.Formats {
+		got, err := render.ParseFormat(string(format))
+		assert.NoError(t, err)
+		assert.Equal(t, format, got)
+	}
+
+	_, err := render.ParseFormat("xml")
+	assert.Error(t, err)
+}
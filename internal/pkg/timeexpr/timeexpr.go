@@ -0,0 +1,210 @@
+package timeexpr
+
+import (
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const ErrInvalidTime = app.Error("invalid time")
+
+var (
+	dateLayouts = []string{
+		"2006-01-02T15:04:05",
+		"2006-01-02T15:04",
+		"2006-01-02 15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+	}
+	signedDuration = regexp.MustCompile(`^([+-])\s*((?:\d+(?:\.\d+)?(?:h|m|s))+)$`)
+	durationAgo    = regexp.MustCompile(`^((?:\d+(?:\.\d+)?(?:h|m|s))+) ago$`)
+	unitsAgo       = regexp.MustCompile(`^(\d+) (second|minute|hour|day|week|month)s? ago$`)
+	clock          = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?\s*(am|pm)?$`)
+	period         = regexp.MustCompile(`^(this|last|next) (week|month)$`)
+)
+
+// Parse resolves a time expression relative to now, in now's location. Weeks start on firstDay. It accepts:
+//
+//   - now, today, yesterday and tomorrow, the last three optionally followed by a time, e.g. yesterday 17:30
+//   - a time of day today, e.g. 17:30, 9am or 5:30pm
+//   - a day of the week, meaning the most recent one (today, if it's that day), or the one before that with last,
+//     e.g. monday, last fri 9:00
+//   - a date, optionally with a time, e.g. 2026-10-01 or 2026-10-01 17:30, or an RFC 3339 timestamp
+//   - a time relative to now, e.g. -1h30m, +15m, 90m ago or 2 days ago
+//   - the start of a week or month, e.g. this week, last week or last month
+//
+// Days start at midnight, and "at" may separate a day from a time, e.g. yesterday at 5pm.
+func Parse(expr string, now time.Time, firstDay time.Weekday) (time.Time, error) {
+	s := strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty time: %w", ErrInvalidTime)
+	}
+	if s == "now" {
+		return now, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, strings.ToUpper(s)); err == nil {
+		return t, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, strings.ToUpper(s), now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if t, ok, err := parseRelative(s, now); ok || err != nil {
+		return t, err
+	}
+
+	if m := period.FindStringSubmatch(s); m != nil {
+		offset := map[string]int{"this": 0, "last": -1, "next": 1}[m[1]]
+		if m[2] == "week" {
+			return app.WeekPeriod(now, firstDay, offset).From, nil
+		}
+		return app.MonthPeriod(now, offset).From, nil
+	}
+
+	day, rest, err := parseDay(s, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w [%s]: %s", ErrInvalidTime, expr, err)
+	}
+	if rest == "" {
+		return day, nil
+	}
+	hour, minute, second, err := parseClock(rest)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w [%s]: %s", ErrInvalidTime, expr, err)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, now.Location()), nil
+}
+
+// parseRelative parses a time relative to now. The second return value is false if s isn't a relative time.
+func parseRelative(s string, now time.Time) (time.Time, bool, error) {
+	if m := signedDuration.FindStringSubmatch(s); m != nil {
+		d, err := time.ParseDuration(m[2])
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("%w [%s]: %s", ErrInvalidTime, s, err)
+		}
+		if m[1] == "-" {
+			d = -d
+		}
+		return now.Add(d), true, nil
+	}
+	if m := durationAgo.FindStringSubmatch(s); m != nil {
+		d, err := time.ParseDuration(m[1])
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("%w [%s]: %s", ErrInvalidTime, s, err)
+		}
+		return now.Add(-d), true, nil
+	}
+	if m := unitsAgo.FindStringSubmatch(s); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("%w [%s]: %s", ErrInvalidTime, s, err)
+		}
+		switch m[2] {
+		case "second":
+			return now.Add(-time.Duration(n) * time.Second), true, nil
+		case "minute":
+			return now.Add(-time.Duration(n) * time.Minute), true, nil
+		case "hour":
+			return now.Add(-time.Duration(n) * time.Hour), true, nil
+		case "day":
+			return now.AddDate(0, 0, -n), true, nil
+		case "week":
+			return now.AddDate(0, 0, -7*n), true, nil
+		default:
+			return now.AddDate(0, -n, 0), true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+// parseDay parses the day at the start of s, returning midnight on that day and the rest of s. If s doesn't start
+// with a day, the day is today.
+func parseDay(s string, now time.Time) (time.Time, string, error) {
+	today := app.DayPeriod(now, 0).From
+	words := strings.SplitN(s, " ", 3)
+
+	day, used := today, 0
+	switch {
+	case words[0] == "today":
+		used = 1
+	case words[0] == "yesterday":
+		day, used = app.DayPeriod(now, -1).From, 1
+	case words[0] == "tomorrow":
+		day, used = app.DayPeriod(now, 1).From, 1
+	case words[0] == "last" && len(words) > 1:
+		weekday, ok := parseWeekday(words[1])
+		if !ok {
+			return time.Time{}, "", fmt.Errorf("unknown day [%s]", words[1])
+		}
+		day, used = app.DayPeriod(now, -daysSince(now.Weekday(), weekday, true)).From, 2
+	default:
+		if weekday, ok := parseWeekday(words[0]); ok {
+			day, used = app.DayPeriod(now, -daysSince(now.Weekday(), weekday, false)).From, 1
+		} else if d, err := time.ParseInLocation("2006-01-02", words[0], now.Location()); err == nil {
+			day, used = d, 1
+		}
+	}
+
+	rest := strings.Join(words[used:], " ")
+	rest = strings.TrimPrefix(rest, "at ")
+	if used > 0 && rest == "at" {
+		return time.Time{}, "", fmt.Errorf("missing time after at")
+	}
+	return day, rest, nil
+}
+
+// daysSince returns how many days ago the most recent weekday was. If strictlyBefore is true, today doesn't count.
+func daysSince(today, weekday time.Weekday, strictlyBefore bool) int {
+	days := (int(today) - int(weekday) + 7) % 7
+	if days == 0 && strictlyBefore {
+		days = 7
+	}
+	return days
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := strings.ToLower(day.String())
+		if s == name || s == name[:3] {
+			return day, true
+		}
+	}
+	return time.Sunday, false
+}
+
+// parseClock parses a time of day, such as 17:30, 17:30:15, 9am or 5:30pm.
+func parseClock(s string) (hour, minute, second int, err error) {
+	m := clock.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[4] == "") {
+		return 0, 0, 0, fmt.Errorf("unknown time of day [%s]", s)
+	}
+
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		second, _ = strconv.Atoi(m[3])
+	}
+	switch m[4] {
+	case "am", "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, 0, fmt.Errorf("hour out of range [%s]", s)
+		}
+		hour %= 12
+		if m[4] == "pm" {
+			hour += 12
+		}
+	}
+	if hour > 23 || minute > 59 || second > 59 {
+		return 0, 0, 0, fmt.Errorf("time of day out of range [%s]", s)
+	}
+
+	return hour, minute, second, nil
+}
@@ -0,0 +1,70 @@
+package timeexpr_test
+
+import (
+	"github.com/danmurf/time-tracker/internal/pkg/timeexpr"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	zone := time.FixedZone("CEST", 2*3600)
+	// Wednesday
+	now := time.Date(2026, 10, 7, 15, 4, 5, 0, zone)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, zone)
+	}
+
+	tests := []struct {
+		expr    string
+		want    time.Time
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{expr: "now", want: now, wantErr: assert.NoError},
+		{expr: "today", want: at(7, 0, 0), wantErr: assert.NoError},
+		{expr: "yesterday", want: at(6, 0, 0), wantErr: assert.NoError},
+		{expr: "yesterday 17:30", want: at(6, 17, 30), wantErr: assert.NoError},
+		{expr: "Yesterday at 5:30pm", want: at(6, 17, 30), wantErr: assert.NoError},
+		{expr: "tomorrow 9am", want: at(8, 9, 0), wantErr: assert.NoError},
+		{expr: "17:30", want: at(7, 17, 30), wantErr: assert.NoError},
+		{expr: "12am", want: at(7, 0, 0), wantErr: assert.NoError},
+		{expr: "12pm", want: at(7, 12, 0), wantErr: assert.NoError},
+		{expr: "9:05:30", want: at(7, 9, 5).Add(30 * time.Second), wantErr: assert.NoError},
+		{expr: "monday", want: at(5, 0, 0), wantErr: assert.NoError},
+		{expr: "mon 9:00", want: at(5, 9, 0), wantErr: assert.NoError},
+		{expr: "wednesday", want: at(7, 0, 0), wantErr: assert.NoError},
+		{expr: "last wednesday", want: time.Date(2026, 9, 30, 0, 0, 0, 0, zone), wantErr: assert.NoError},
+		{expr: "last fri 16:00", want: at(2, 16, 0), wantErr: assert.NoError},
+		{expr: "thursday", want: at(1, 0, 0), wantErr: assert.NoError},
+		{expr: "2026-10-01", want: at(1, 0, 0), wantErr: assert.NoError},
+		{expr: "2026-10-01 17:30", want: at(1, 17, 30), wantErr: assert.NoError},
+		{expr: "2026-10-01 at 5pm", want: at(1, 17, 0), wantErr: assert.NoError},
+		{expr: "2026-10-01T17:30:00Z", want: time.Date(2026, 10, 1, 17, 30, 0, 0, time.UTC), wantErr: assert.NoError},
+		{expr: "-1h30m", want: now.Add(-90 * time.Minute), wantErr: assert.NoError},
+		{expr: "+15m", want: now.Add(15 * time.Minute), wantErr: assert.NoError},
+		{expr: "90m ago", want: now.Add(-90 * time.Minute), wantErr: assert.NoError},
+		{expr: "2 hours ago", want: now.Add(-2 * time.Hour), wantErr: assert.NoError},
+		{expr: "3 days ago", want: now.AddDate(0, 0, -3), wantErr: assert.NoError},
+		{expr: "this week", want: at(5, 0, 0), wantErr: assert.NoError},
+		{expr: "last week", want: time.Date(2026, 9, 28, 0, 0, 0, 0, zone), wantErr: assert.NoError},
+		{expr: "last month", want: time.Date(2026, 9, 1, 0, 0, 0, 0, zone), wantErr: assert.NoError},
+		{expr: "", wantErr: assert.Error},
+		{expr: "someday", wantErr: assert.Error},
+		{expr: "last someday", wantErr: assert.Error},
+		{expr: "yesterday at", wantErr: assert.Error},
+		{expr: "25:00", wantErr: assert.Error},
+		{expr: "13pm", wantErr: assert.Error},
+		{expr: "5", wantErr: assert.Error},
+		{expr: "1h30m", wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := timeexpr.Parse(tt.expr, now, time.Monday)
+			if !tt.wantErr(t, err) || err != nil {
+				assert.ErrorIs(t, err, timeexpr.ErrInvalidTime)
+				return
+			}
+			assert.True(t, tt.want.Equal(got), "want %s, got %s", tt.want, got)
+		})
+	}
+}
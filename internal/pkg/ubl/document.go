@@ -0,0 +1,117 @@
+package ubl
+
+import "encoding/xml"
+
+// The types below are the parts of a UBL 2.1 invoice that are written, in the order the schema requires. Elements
+// in the aggregate (cac) and basic (cbc) component namespaces are written with those prefixes, which is how UBL
+// documents are usually written, and how validators expect them.
+
+type document struct {
+	XMLName                 xml.Name      `xml:"Invoice"`
+	XMLNS                   string        `xml:"xmlns,attr"`
+	CAC                     string        `xml:"xmlns:cac,attr"`
+	CBC                     string        `xml:"xmlns:cbc,attr"`
+	CustomizationID         string        `xml:"cbc:CustomizationID"`
+	ID                      string        `xml:"cbc:ID"`
+	IssueDate               string        `xml:"cbc:IssueDate"`
+	DueDate                 string        `xml:"cbc:DueDate"`
+	InvoiceTypeCode         string        `xml:"cbc:InvoiceTypeCode"`
+	DocumentCurrencyCode    string        `xml:"cbc:DocumentCurrencyCode"`
+	InvoicePeriod           period        `xml:"cac:InvoicePeriod"`
+	AccountingSupplierParty partyWrapper  `xml:"cac:AccountingSupplierParty"`
+	AccountingCustomerParty partyWrapper  `xml:"cac:AccountingCustomerParty"`
+	TaxTotal                taxTotal      `xml:"cac:TaxTotal"`
+	LegalMonetaryTotal      monetaryTotal `xml:"cac:LegalMonetaryTotal"`
+	InvoiceLines            []invoiceLine `xml:"cac:InvoiceLine"`
+}
+
+type period struct {
+	StartDate string `xml:"cbc:StartDate"`
+	EndDate   string `xml:"cbc:EndDate"`
+}
+
+type partyWrapper struct {
+	Party party `xml:"cac:Party"`
+}
+
+type party struct {
+	PostalAddress    address         `xml:"cac:PostalAddress"`
+	PartyTaxScheme   *partyTaxScheme `xml:"cac:PartyTaxScheme"`
+	PartyLegalEntity legalEntity     `xml:"cac:PartyLegalEntity"`
+}
+
+type address struct {
+	StreetName string  `xml:"cbc:StreetName,omitempty"`
+	CityName   string  `xml:"cbc:CityName,omitempty"`
+	PostalZone string  `xml:"cbc:PostalZone,omitempty"`
+	Country    country `xml:"cac:Country"`
+}
+
+type country struct {
+	IdentificationCode string `xml:"cbc:IdentificationCode"`
+}
+
+type partyTaxScheme struct {
+	CompanyID string    `xml:"cbc:CompanyID"`
+	TaxScheme taxScheme `xml:"cac:TaxScheme"`
+}
+
+type taxScheme struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type legalEntity struct {
+	RegistrationName string `xml:"cbc:RegistrationName"`
+}
+
+type taxTotal struct {
+	TaxAmount   amount      `xml:"cbc:TaxAmount"`
+	TaxSubtotal taxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type taxSubtotal struct {
+	TaxableAmount amount      `xml:"cbc:TaxableAmount"`
+	TaxAmount     amount      `xml:"cbc:TaxAmount"`
+	TaxCategory   taxCategory `xml:"cac:TaxCategory"`
+}
+
+type taxCategory struct {
+	ID                 string    `xml:"cbc:ID"`
+	Percent            string    `xml:"cbc:Percent,omitempty"`
+	TaxExemptionReason string    `xml:"cbc:TaxExemptionReason,omitempty"`
+	TaxScheme          taxScheme `xml:"cac:TaxScheme"`
+}
+
+type monetaryTotal struct {
+	LineExtensionAmount amount `xml:"cbc:LineExtensionAmount"`
+	TaxExclusiveAmount  amount `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusiveAmount  amount `xml:"cbc:TaxInclusiveAmount"`
+	PayableAmount       amount `xml:"cbc:PayableAmount"`
+}
+
+type invoiceLine struct {
+	ID                  string   `xml:"cbc:ID"`
+	InvoicedQuantity    quantity `xml:"cbc:InvoicedQuantity"`
+	LineExtensionAmount amount   `xml:"cbc:LineExtensionAmount"`
+	Item                item     `xml:"cac:Item"`
+	Price               price    `xml:"cac:Price"`
+}
+
+type quantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type amount struct {
+	CurrencyID string `xml:"currencyID,attr"`
+	Value      string `xml:",chardata"`
+}
+
+type item struct {
+	Name                  string      `xml:"cbc:Name"`
+	ClassifiedTaxCategory taxCategory `xml:"cac:ClassifiedTaxCategory"`
+}
+
+type price struct {
+	PriceAmount amount `xml:"cbc:PriceAmount"`
+}
@@ -0,0 +1,184 @@
+// Package ubl writes invoices as OASIS Universal Business Language (UBL) 2.1 documents, following the European
+// e-invoicing standard EN 16931, so that they can be sent to clients who require structured e-invoices.
+package ubl
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"io"
+	"strconv"
+)
+
+const (
+	namespace          = "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2"
+	namespaceAggregate = "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2"
+	namespaceBasic     = "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2"
+
+	// customization is the specification that documents conform to.
+	customization = "urn:cen.eu:en16931:2017"
+	// commercialInvoice is the UNCL 1001 code for an invoice.
+	commercialInvoice = "380"
+	// hour is the UN/ECE recommendation 20 code for quantities in hours.
+	hour = "HUR"
+	vat  = "VAT"
+)
+
+// TaxCategories lists the UNCL 5305 VAT category codes, e.g. S for the standard rate and AE for reverse charge.
+var TaxCategories = []string{"S", "Z", "E", "AE", "K", "G", "O", "L", "M"}
+
+// Party is the seller or the buyer on an invoice. Country is an ISO 3166-1 alpha-2 code, e.g. GB.
+type Party struct {
+	Name       string
+	Street     string
+	City       string
+	PostalCode string
+	Country    string
+	VATID      string
+}
+
+// Tax is the VAT charged on invoiced time: a UNCL 5305 category code and a rate as a percentage. Categories which
+// aren't taxed, such as E for exempt, need a reason.
+type Tax struct {
+	Category        string
+	Percent         float64
+	ExemptionReason string
+}
+
+// Details are what an e-invoice needs beyond the invoiced time: who it's from and to, the VAT charged on the time,
+// and how many days after it's issued it must be paid.
+type Details struct {
+	Seller      Party
+	Buyer       Party
+	Tax         Tax
+	PaymentDays int
+}
+
+// Write writes the invoice as a UBL 2.1 invoice document, with a line for each of its lines, in hours. Dates are in
+// the location of the invoice's times. Nothing is written if the details aren't valid.
+func Write(w io.Writer, invoice app.Invoice, details Details) error {
+	if err := Validate(details); err != nil {
+		return err
+	}
+	tax := details.Tax
+
+	taxable := invoice.Total
+	taxAmount := app.RoundCents(taxable * tax.Percent / 100)
+	category := taxCategory{ID: tax.Category, TaxScheme: taxScheme{ID: vat}}
+	// Time which is outside the scope of VAT has no rate at all.
+	if tax.Category != "O" {
+		category.Percent = decimal(tax.Percent)
+	}
+	subtotalCategory := category
+	subtotalCategory.TaxExemptionReason = tax.ExemptionReason
+
+	doc := document{
+		XMLNS:                namespace,
+		CAC:                  namespaceAggregate,
+		CBC:                  namespaceBasic,
+		CustomizationID:      customization,
+		ID:                   invoice.Number,
+		IssueDate:            invoice.IssuedAt.Format("2006-01-02"),
+		DueDate:              invoice.IssuedAt.AddDate(0, 0, details.PaymentDays).Format("2006-01-02"),
+		InvoiceTypeCode:      commercialInvoice,
+		DocumentCurrencyCode: invoice.Currency,
+		InvoicePeriod: period{
+			StartDate: invoice.Period.From.Format("2006-01-02"),
+			EndDate:   invoice.Period.To.AddDate(0, 0, -1).Format("2006-01-02"),
+		},
+		AccountingSupplierParty: partyWrapper{Party: newParty(details.Seller)},
+		AccountingCustomerParty: partyWrapper{Party: newParty(details.Buyer)},
+		TaxTotal: taxTotal{
+			TaxAmount: newAmount(taxAmount, invoice.Currency),
+			TaxSubtotal: taxSubtotal{
+				TaxableAmount: newAmount(taxable, invoice.Currency),
+				TaxAmount:     newAmount(taxAmount, invoice.Currency),
+				TaxCategory:   subtotalCategory,
+			},
+		},
+		LegalMonetaryTotal: monetaryTotal{
+			LineExtensionAmount: newAmount(taxable, invoice.Currency),
+			TaxExclusiveAmount:  newAmount(taxable, invoice.Currency),
+			TaxInclusiveAmount:  newAmount(taxable+taxAmount, invoice.Currency),
+			PayableAmount:       newAmount(taxable+taxAmount, invoice.Currency),
+		},
+	}
+	for i, l := range invoice.Lines {
+		doc.InvoiceLines = append(doc.InvoiceLines, invoiceLine{
+			ID:                  strconv.Itoa(i + 1),
+			InvoicedQuantity:    quantity{UnitCode: hour, Value: decimal(l.Quantity)},
+			LineExtensionAmount: newAmount(l.Amount, invoice.Currency),
+			Item:                item{Name: l.TaskName, ClassifiedTaxCategory: category},
+			Price:               price{PriceAmount: newAmount(l.Rate.Amount, invoice.Currency)},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("writing xml header: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("encoding invoice: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("writing invoice: %w", err)
+	}
+	return nil
+}
+
+// Validate checks that the details are enough for an e-invoice, and that the tax category and rate agree.
+func Validate(details Details) error {
+	for _, p := range []struct {
+		role  string
+		party Party
+	}{{"seller", details.Seller}, {"buyer", details.Buyer}} {
+		if p.party.Name == "" || p.party.Country == "" {
+			return fmt.Errorf("the %s's name and country must be given", p.role)
+		}
+	}
+	if details.PaymentDays < 0 {
+		return fmt.Errorf("payment days [%d] must not be negative", details.PaymentDays)
+	}
+	tax := details.Tax
+	known := false
+	for _, c := range TaxCategories {
+		known = known || c == tax.Category
+	}
+	switch {
+	case !known:
+		return fmt.Errorf("unknown tax category [%s], must be one of %v", tax.Category, TaxCategories)
+	case tax.Category == "S" && tax.Percent <= 0:
+		return fmt.Errorf("tax category S must have a rate above zero")
+	case tax.Category != "S" && tax.Category != "L" && tax.Category != "M" && tax.Percent != 0:
+		return fmt.Errorf("tax category %s must have a rate of zero", tax.Category)
+	case (tax.Category == "E" || tax.Category == "AE" || tax.Category == "K" || tax.Category == "G" || tax.Category == "O") && tax.ExemptionReason == "":
+		return fmt.Errorf("tax category %s must have an exemption reason", tax.Category)
+	}
+	return nil
+}
+
+func newParty(p Party) party {
+	doc := party{
+		PostalAddress: address{
+			StreetName: p.Street,
+			CityName:   p.City,
+			PostalZone: p.PostalCode,
+			Country:    country{IdentificationCode: p.Country},
+		},
+		PartyLegalEntity: legalEntity{RegistrationName: p.Name},
+	}
+	if p.VATID != "" {
+		doc.PartyTaxScheme = &partyTaxScheme{CompanyID: p.VATID, TaxScheme: taxScheme{ID: vat}}
+	}
+	return doc
+}
+
+// decimal formats a number with up to two decimal places, as UBL amounts and quantities are decimals.
+func decimal(f float64) string {
+	return strconv.FormatFloat(app.RoundCents(f), 'f', 2, 64)
+}
+
+func newAmount(f float64, currency string) amount {
+	return amount{CurrencyID: currency, Value: decimal(f)}
+}
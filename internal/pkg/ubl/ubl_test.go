@@ -0,0 +1,185 @@
+package ubl_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/ubl"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	seller = ubl.Party{Name: "Seller Ltd", Street: "High Street 2", City: "London", PostalCode: "N1 9GU", Country: "GB", VATID: "GB123456789"}
+	buyer  = ubl.Party{Name: "Buyer Trading BV", Street: "Harbour Road 5", City: "Port Town", PostalCode: "1234 AB", Country: "NL", VATID: "NL987654321B01"}
+)
+
+func newInvoice(lines ...app.InvoiceLine) app.Invoice {
+	invoice := app.Invoice{
+		Number:   "2022-0001",
+		Client:   "Buyer Trading BV",
+		Period:   app.MonthPeriod(time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC), 0),
+		IssuedAt: time.Date(2022, 7, 1, 9, 0, 0, 0, time.UTC),
+		Currency: "EUR",
+		Lines:    lines,
+	}
+	for _, l := range lines {
+		invoice.Total += l.Amount
+	}
+	return invoice
+}
+
+func TestWrite_MatchesSampleStructure(t *testing.T) {
+	tests := []struct {
+		name    string
+		sample  string
+		invoice app.Invoice
+		details ubl.Details
+	}{
+		{
+			name:   "standard rate",
+			sample: "sample-invoice.xml",
+			invoice: newInvoice(
+				app.InvoiceLine{TaskName: "acme-api", Rate: app.Rate{Amount: 150.5}, Quantity: 1, Amount: 150.5},
+				app.InvoiceLine{TaskName: "acme-web", Rate: app.Rate{Amount: 100}, Quantity: 2.25, Amount: 225},
+			),
+			details: ubl.Details{Seller: seller, Buyer: buyer, Tax: ubl.Tax{Category: "S", Percent: 20}, PaymentDays: 30},
+		},
+		{
+			name:    "reverse charge",
+			sample:  "sample-reverse-charge.xml",
+			invoice: newInvoice(app.InvoiceLine{TaskName: "acme-web", Rate: app.Rate{Amount: 100}, Quantity: 5.5, Amount: 550}),
+			details: ubl.Details{
+				Seller: seller,
+				Buyer:  ubl.Party{Name: "Acheteur SARL", Country: "FR", VATID: "FR12345678901"},
+				Tax:    ubl.Tax{Category: "AE", ExemptionReason: "Reverse charge"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample, err := os.ReadFile(filepath.Join("testdata", tt.sample))
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+			assert.NoError(t, ubl.Write(&buf, tt.invoice, tt.details))
+
+			assert.Equal(t, elements(t, bytes.NewReader(sample)), elements(t, &buf))
+		})
+	}
+}
+
+func TestWrite_Values(t *testing.T) {
+	invoice := newInvoice(
+		app.InvoiceLine{TaskName: "acme-api", Rate: app.Rate{Amount: 150.5}, Quantity: 1, Amount: 150.5},
+		app.InvoiceLine{TaskName: "acme-web", Rate: app.Rate{Amount: 100}, Quantity: 2.25, Amount: 225},
+	)
+	var buf bytes.Buffer
+	assert.NoError(t, ubl.Write(&buf, invoice, ubl.Details{Seller: seller, Buyer: buyer, Tax: ubl.Tax{Category: "S", Percent: 20}, PaymentDays: 30}))
+	got := values(t, &buf)
+
+	for path, want := range map[string][]string{
+		"Invoice/cbc:ID":                                               {"2022-0001"},
+		"Invoice/cbc:IssueDate":                                        {"2022-07-01"},
+		"Invoice/cbc:DueDate":                                          {"2022-07-31"},
+		"Invoice/cac:InvoicePeriod/cbc:EndDate":                        {"2022-06-30"},
+		"Invoice/cac:TaxTotal/cbc:TaxAmount":                           {"75.10"},
+		"Invoice/cac:LegalMonetaryTotal/cbc:TaxExclusiveAmount":        {"375.50"},
+		"Invoice/cac:LegalMonetaryTotal/cbc:PayableAmount":             {"450.60"},
+		"Invoice/cac:InvoiceLine/cbc:InvoicedQuantity":                 {"1.00", "2.25"},
+		"Invoice/cac:InvoiceLine/cbc:InvoicedQuantity@unitCode":        {"HUR", "HUR"},
+		"Invoice/cac:InvoiceLine/cbc:LineExtensionAmount":              {"150.50", "225.00"},
+		"Invoice/cac:InvoiceLine/cac:Item/cbc:Name":                    {"acme-api", "acme-web"},
+		"Invoice/cac:InvoiceLine/cac:Price/cbc:PriceAmount":            {"150.50", "100.00"},
+		"Invoice/cac:InvoiceLine/cac:Price/cbc:PriceAmount@currencyID": {"EUR", "EUR"},
+	} {
+		assert.Equal(t, want, got[path], path)
+	}
+}
+
+func TestWrite_InvalidDetails(t *testing.T) {
+	tests := []struct {
+		name    string
+		details ubl.Details
+	}{
+		{name: "no seller", details: ubl.Details{Buyer: buyer, Tax: ubl.Tax{Category: "S", Percent: 20}}},
+		{name: "buyer without a country", details: ubl.Details{Seller: seller, Buyer: ubl.Party{Name: "Buyer"}, Tax: ubl.Tax{Category: "S", Percent: 20}}},
+		{name: "unknown tax category", details: ubl.Details{Seller: seller, Buyer: buyer, Tax: ubl.Tax{Category: "X"}}},
+		{name: "standard rate of zero", details: ubl.Details{Seller: seller, Buyer: buyer, Tax: ubl.Tax{Category: "S"}}},
+		{name: "exempt with a rate", details: ubl.Details{Seller: seller, Buyer: buyer, Tax: ubl.Tax{Category: "E", Percent: 20, ExemptionReason: "Exempt"}}},
+		{name: "exempt without a reason", details: ubl.Details{Seller: seller, Buyer: buyer, Tax: ubl.Tax{Category: "E"}}},
+		{name: "negative payment days", details: ubl.Details{Seller: seller, Buyer: buyer, Tax: ubl.Tax{Category: "S", Percent: 20}, PaymentDays: -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.Error(t, ubl.Write(&buf, newInvoice(), tt.details))
+			assert.Empty(t, buf.String(), "nothing should be written for an invalid invoice")
+		})
+	}
+}
+
+// prefixes are the usual prefixes of the UBL namespaces, so that paths are the same however a document declares them.
+var prefixes = map[string]string{
+	"urn:oasis:names:specification:ubl:schema:xsd:Invoice-2":                   "",
+	"urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2": "cac:",
+	"urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2":     "cbc:",
+}
+
+// walk calls visit with the path of each element and attribute in a document, in order, and the text or value of it.
+func walk(t *testing.T, r io.Reader, visit func(path, value string)) {
+	decoder := xml.NewDecoder(r)
+	var path []string
+	var text strings.Builder
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			prefix, ok := prefixes[token.Name.Space]
+			assert.True(t, ok, "unknown namespace [%s]", token.Name.Space)
+			path = append(path, prefix+token.Name.Local)
+			text.Reset()
+			for _, attr := range token.Attr {
+				if attr.Name.Space != "xmlns" && attr.Name.Local != "xmlns" {
+					visit(strings.Join(path, "/")+"@"+attr.Name.Local, attr.Value)
+				}
+			}
+		case xml.CharData:
+			text.Write(token)
+		case xml.EndElement:
+			visit(strings.Join(path, "/"), strings.TrimSpace(text.String()))
+			text.Reset()
+			path = path[:len(path)-1]
+		}
+	}
+}
+
+// elements lists the paths of the elements and attributes in a document, in order.
+func elements(t *testing.T, r io.Reader) []string {
+	var paths []string
+	walk(t, r, func(path, _ string) {
+		paths = append(paths, path)
+	})
+	return paths
+}
+
+// values maps the paths of the elements and attributes in a document to their values, in order.
+func values(t *testing.T, r io.Reader) map[string][]string {
+	values := map[string][]string{}
+	walk(t, r, func(path, value string) {
+		values[path] = append(values[path], value)
+	})
+	return values
+}
@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	_ app.AbsenceAdder   = (*Absences)(nil)
+	_ app.AbsenceRemover = (*Absences)(nil)
+	_ app.AbsenceLister  = (*Absences)(nil)
+)
+
+// The keys of the data in absence events.
+const (
+	absenceDate = "date"
+	absenceKind = "kind"
+	absenceHalf = "half"
+	absenceNote = "note"
+)
+
+// Absences keeps the days which weren't worked as events.
+type Absences struct {
+	eventStore  app.EventStore
+	eventLister app.EventLister
+	now         func() time.Time
+	newUUID     func() uuid.UUID
+}
+
+func NewAbsences(eventStore app.EventStore, eventLister app.EventLister) Absences {
+	return Absences{eventStore: eventStore, eventLister: eventLister, now: time.Now, newUUID: uuid.New}
+}
+
+// AddAbsences records the absences. None of them are recorded if any is invalid.
+func (a Absences) AddAbsences(ctx context.Context, absences ...app.Absence) error {
+	for _, absence := range absences {
+		if !knownAbsenceKind(absence.Kind) {
+			return fmt.Errorf("unknown kind [%s], must be one of %v: %w", absence.Kind, app.AbsenceKinds, app.ErrInvalidAbsence)
+		}
+		if absence.Date.IsZero() {
+			return fmt.Errorf("an absence must have a date: %w", app.ErrInvalidAbsence)
+		}
+	}
+
+	for _, absence := range absences {
+		data := map[string]string{
+			absenceDate: app.Date(absence.Date).Format("2006-01-02"),
+			absenceKind: string(absence.Kind),
+		}
+		if absence.Half {
+			data[absenceHalf] = strconv.FormatBool(absence.Half)
+		}
+		if absence.Note != "" {
+			data[absenceNote] = absence.Note
+		}
+		if err := a.store(ctx, app.EventTypeAbsenceAdded, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveAbsences records that there were no absences on the days.
+func (a Absences) RemoveAbsences(ctx context.Context, dates ...time.Time) error {
+	for _, date := range dates {
+		if err := a.store(ctx, app.EventTypeAbsenceRemoved, map[string]string{absenceDate: app.Date(date).Format("2006-01-02")}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a Absences) store(ctx context.Context, eventType app.EventType, data map[string]string) error {
+	if err := a.eventStore.Store(ctx, app.Event{
+		ID:        a.newUUID(),
+		Type:      eventType,
+		CreatedAt: a.now(),
+		Data:      data,
+	}); err != nil {
+		return fmt.Errorf("storing event: %w", err)
+	}
+	return nil
+}
+
+// Absences returns the absence added most recently on each day, unless it was removed after that, oldest first.
+func (a Absences) Absences(ctx context.Context) (app.Absences, error) {
+	events, err := a.eventLister.FetchAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching events: %w", err)
+	}
+	events = append([]app.Event(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+
+	latest := map[time.Time]app.Absence{}
+	for _, e := range events {
+		if e.Type != app.EventTypeAbsenceAdded && e.Type != app.EventTypeAbsenceRemoved {
+			continue
+		}
+		absence, err := parseAbsence(e)
+		if err != nil {
+			return nil, err
+		}
+		if e.Type == app.EventTypeAbsenceRemoved {
+			delete(latest, absence.Date)
+			continue
+		}
+		latest[absence.Date] = absence
+	}
+
+	var absences app.Absences
+	for _, absence := range latest {
+		absences = append(absences, absence)
+	}
+	sort.Slice(absences, func(i, j int) bool {
+		return absences[i].Date.Before(absences[j].Date)
+	})
+
+	return absences, nil
+}
+
+func parseAbsence(e app.Event) (app.Absence, error) {
+	date, err := time.Parse("2006-01-02", e.Data[absenceDate])
+	if err != nil {
+		return app.Absence{}, fmt.Errorf("parsing date of absence [%s]: %w", e.ID, err)
+	}
+	return app.Absence{
+		Date:    date,
+		Kind:    app.AbsenceKind(e.Data[absenceKind]),
+		Half:    e.Data[absenceHalf] == "true",
+		Note:    e.Data[absenceNote],
+		AddedAt: e.CreatedAt,
+	}, nil
+}
+
+func knownAbsenceKind(kind app.AbsenceKind) bool {
+	for _, k := range app.AbsenceKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAbsences_AddAbsences(t *testing.T) {
+	date := time.Date(2026, 8, 3, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		absences []app.Absence
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		{name: "whole day", absences: []app.Absence{{Date: date, Kind: app.Vacation}}, wantErr: assert.NoError},
+		{name: "half day", absences: []app.Absence{{Date: date, Kind: app.Sick, Half: true, Note: "flu"}}, wantErr: assert.NoError},
+		{name: "unknown kind", absences: []app.Absence{{Date: date, Kind: app.Vacation}, {Date: date, Kind: "party"}}, wantErr: assert.Error},
+		{name: "no date", absences: []app.Absence{{Kind: app.Holiday}}, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := eventstore.NewMemoryEventStore()
+			sut := NewAbsences(store, store)
+			err := sut.AddAbsences(context.Background(), tt.absences...)
+			events, fetchErr := store.FetchAll(context.Background())
+			assert.NoError(t, fetchErr)
+			if !tt.wantErr(t, err) || err != nil {
+				assert.ErrorIs(t, err, app.ErrInvalidAbsence)
+				assert.Empty(t, events, "no absences should be added if any is invalid")
+				return
+			}
+
+			if assert.Len(t, events, len(tt.absences)) {
+				assert.Equal(t, app.EventTypeAbsenceAdded, events[0].Type)
+				assert.Empty(t, events[0].TaskName, "absences shouldn't be found as tasks")
+			}
+		})
+	}
+}
+
+func TestAbsences_Absences(t *testing.T) {
+	ctx := context.Background()
+	date := func(day int) time.Time {
+		return time.Date(2026, 8, day, 0, 0, 0, 0, time.UTC)
+	}
+	store := eventstore.NewMemoryEventStore()
+	sut := NewAbsences(store, store)
+	now := date(1)
+	sut.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+
+	assert.NoError(t, sut.AddAbsences(ctx,
+		app.Absence{Date: date(4), Kind: app.Vacation},
+		app.Absence{Date: date(3), Kind: app.Vacation},
+		app.Absence{Date: date(5), Kind: app.Vacation},
+	))
+	// A later absence on the same day replaces the earlier one.
+	assert.NoError(t, sut.AddAbsences(ctx, app.Absence{Date: date(4), Kind: app.Sick, Half: true, Note: "flu"}))
+	assert.NoError(t, sut.RemoveAbsences(ctx, date(5), date(6)))
+
+	got, err := sut.Absences(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, app.Absences{
+		{Date: date(3), Kind: app.Vacation, AddedAt: date(1).Add(2 * time.Minute)},
+		{Date: date(4), Kind: app.Sick, Half: true, Note: "flu", AddedAt: date(1).Add(4 * time.Minute)},
+	}, got)
+}
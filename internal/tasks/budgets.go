@@ -0,0 +1,164 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	_ app.BudgetSetter  = (*Budgets)(nil)
+	_ app.BudgetChecker = (*Budgets)(nil)
+)
+
+// The keys of the data in budget set events.
+const (
+	budgetTask     = "task"
+	budgetDuration = "duration"
+)
+
+// Budgets keeps the budgets for tasks and task name prefixes as events, and checks the time spent against them.
+type Budgets struct {
+	eventStore    app.EventStore
+	eventLister   app.EventLister
+	sessionLister app.SessionLister
+	now           func() time.Time
+	newUUID       func() uuid.UUID
+}
+
+func NewBudgets(eventStore app.EventStore, eventLister app.EventLister, sessionLister app.SessionLister) Budgets {
+	return Budgets{
+		eventStore:    eventStore,
+		eventLister:   eventLister,
+		sessionLister: sessionLister,
+		now:           time.Now,
+		newUUID:       uuid.New,
+	}
+}
+
+// SetBudget records the budget for a task or a task name prefix, replacing any budget it had.
+func (b Budgets) SetBudget(ctx context.Context, budget app.Budget) error {
+	switch {
+	case budget.Task == "" || budget.Task == "*":
+		return fmt.Errorf("a budget must be for a task or a task name prefix: %w", app.ErrInvalidBudget)
+	case budget.Duration < 0:
+		return fmt.Errorf("duration [%s] must not be negative: %w", budget.Duration, app.ErrInvalidBudget)
+	}
+
+	if err := b.eventStore.Store(ctx, app.Event{
+		ID:        b.newUUID(),
+		Type:      app.EventTypeBudgetSet,
+		CreatedAt: b.now(),
+		Data: map[string]string{
+			budgetTask:     budget.Task,
+			budgetDuration: budget.Duration.String(),
+		},
+	}); err != nil {
+		return fmt.Errorf("storing event: %w", err)
+	}
+
+	return nil
+}
+
+// Statuses returns the time spent against every budget, sorted by task.
+func (b Budgets) Statuses(ctx context.Context) ([]app.BudgetStatus, error) {
+	return b.statuses(ctx, func(app.Budget) bool { return true })
+}
+
+// StatusesFor returns the time spent against the budgets that a task's time counts towards, the task's own estimate
+// first and then prefixes from the longest.
+func (b Budgets) StatusesFor(ctx context.Context, taskName string) ([]app.BudgetStatus, error) {
+	statuses, err := b.statuses(ctx, func(budget app.Budget) bool { return budget.Matches(taskName) })
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return specificity(statuses[i].Budget) > specificity(statuses[j].Budget)
+	})
+	return statuses, nil
+}
+
+func specificity(budget app.Budget) int {
+	if budget.IsPrefix() {
+		return len(budget.Task)
+	}
+	return 1 << 30
+}
+
+func (b Budgets) statuses(ctx context.Context, include func(app.Budget) bool) ([]app.BudgetStatus, error) {
+	budgets, err := b.budgets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var statuses []app.BudgetStatus
+	for _, budget := range budgets {
+		if include(budget) {
+			statuses = append(statuses, app.BudgetStatus{Budget: budget})
+		}
+	}
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+
+	sessions, err := b.sessionLister.Sessions(ctx, app.Period{To: b.now()})
+	if err != nil {
+		return nil, fmt.Errorf("listing sessions: %w", err)
+	}
+	for i := range statuses {
+		for _, s := range sessions {
+			if statuses[i].Budget.Matches(s.TaskName) {
+				statuses[i].Sessions++
+				statuses[i].Spent += s.Duration()
+			}
+		}
+	}
+
+	return statuses, nil
+}
+
+// budgets returns the latest budget for each task and prefix, leaving out those which have been removed, sorted by
+// task.
+func (b Budgets) budgets(ctx context.Context) ([]app.Budget, error) {
+	events, err := b.eventLister.FetchAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching events: %w", err)
+	}
+
+	latest := map[string]app.Budget{}
+	for _, e := range events {
+		if e.Type != app.EventTypeBudgetSet {
+			continue
+		}
+		budget, err := parseBudget(e)
+		if err != nil {
+			return nil, err
+		}
+		if existing, ok := latest[budget.Task]; !ok || budget.SetAt.After(existing.SetAt) {
+			latest[budget.Task] = budget
+		}
+	}
+
+	var budgets []app.Budget
+	for _, budget := range latest {
+		if budget.Duration > 0 {
+			budgets = append(budgets, budget)
+		}
+	}
+	sort.Slice(budgets, func(i, j int) bool {
+		return strings.TrimSuffix(budgets[i].Task, "*") < strings.TrimSuffix(budgets[j].Task, "*")
+	})
+
+	return budgets, nil
+}
+
+func parseBudget(e app.Event) (app.Budget, error) {
+	duration, err := time.ParseDuration(e.Data[budgetDuration])
+	if err != nil {
+		return app.Budget{}, fmt.Errorf("parsing duration of budget [%s]: %w", e.ID, err)
+	}
+	return app.Budget{Task: e.Data[budgetTask], Duration: duration, SetAt: e.CreatedAt}, nil
+}
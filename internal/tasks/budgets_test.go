@@ -0,0 +1,110 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBudgets_SetBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		budget  app.Budget
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "task", budget: app.Budget{Task: "acme-web", Duration: 8 * time.Hour}, wantErr: assert.NoError},
+		{name: "prefix", budget: app.Budget{Task: "acme-*", Duration: 40 * time.Hour}, wantErr: assert.NoError},
+		{name: "removed", budget: app.Budget{Task: "acme-web"}, wantErr: assert.NoError},
+		{name: "no task", budget: app.Budget{Duration: time.Hour}, wantErr: assert.Error},
+		{name: "every task", budget: app.Budget{Task: "*", Duration: time.Hour}, wantErr: assert.Error},
+		{name: "negative duration", budget: app.Budget{Task: "acme-web", Duration: -time.Hour}, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := eventstore.NewMemoryEventStore()
+			sut := NewBudgets(store, store, NewSessionCollector(store))
+			err := sut.SetBudget(context.Background(), tt.budget)
+			if !tt.wantErr(t, err) || err != nil {
+				assert.ErrorIs(t, err, app.ErrInvalidBudget)
+				return
+			}
+
+			events, err := store.FetchAll(context.Background())
+			assert.NoError(t, err)
+			if assert.Len(t, events, 1) {
+				assert.Equal(t, app.EventTypeBudgetSet, events[0].Type)
+				assert.Empty(t, events[0].TaskName, "budgets shouldn't be found as tasks")
+			}
+		})
+	}
+}
+
+func TestBudgets_Statuses(t *testing.T) {
+	ctx := context.Background()
+	at := func(hour, minute int) time.Time {
+		return time.Date(2022, 6, 1, hour, minute, 0, 0, time.UTC)
+	}
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		at        time.Time
+	}{
+		{app.EventTypeTaskStarted, "acme-web", at(9, 0)},
+		{app.EventTypeTaskFinished, "acme-web", at(11, 0)},
+		{app.EventTypeTaskStarted, "acme-api", at(11, 0)},
+		{app.EventTypeTaskFinished, "acme-api", at(12, 0)},
+		{app.EventTypeTaskStarted, "acme-web", at(13, 0)},
+		{app.EventTypeTaskStarted, "globex", at(13, 0)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, CreatedAt: e.at}))
+	}
+	sut := NewBudgets(store, store, SessionCollector{eventLister: store, now: func() time.Time { return at(14, 30) }})
+	setAt := at(8, 0)
+	sut.now = func() time.Time {
+		setAt = setAt.Add(time.Second)
+		return setAt
+	}
+	assert.NoError(t, sut.SetBudget(ctx, app.Budget{Task: "acme-web", Duration: 2 * time.Hour}))
+	assert.NoError(t, sut.SetBudget(ctx, app.Budget{Task: "acme-web", Duration: 4 * time.Hour}))
+	assert.NoError(t, sut.SetBudget(ctx, app.Budget{Task: "acme-*", Duration: 10 * time.Hour}))
+	assert.NoError(t, sut.SetBudget(ctx, app.Budget{Task: "a*", Duration: time.Hour}))
+	assert.NoError(t, sut.SetBudget(ctx, app.Budget{Task: "globex", Duration: time.Hour}))
+	assert.NoError(t, sut.SetBudget(ctx, app.Budget{Task: "globex"}))
+	sut.now = func() time.Time { return at(14, 30) }
+
+	t.Run("every budget", func(t *testing.T) {
+		got, err := sut.Statuses(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 3, "a removed budget shouldn't be listed") {
+			assert.Equal(t, "a*", got[0].Budget.Task)
+			assert.Equal(t, "acme-*", got[1].Budget.Task)
+			assert.Equal(t, "acme-web", got[2].Budget.Task)
+			assert.Equal(t, 4*time.Hour, got[2].Budget.Duration, "a later budget should replace an earlier one")
+			assert.Equal(t, 3, got[1].Sessions)
+			assert.Equal(t, 4*time.Hour+30*time.Minute, got[1].Spent, "time in progress should be spent")
+		}
+	})
+
+	t.Run("budgets for a task", func(t *testing.T) {
+		got, err := sut.StatusesFor(ctx, "acme-web")
+		assert.NoError(t, err)
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, "acme-web", got[0].Budget.Task, "a task's own estimate should be first")
+			assert.Equal(t, "acme-*", got[1].Budget.Task)
+			assert.Equal(t, "a*", got[2].Budget.Task)
+			assert.Equal(t, 30*time.Minute, got[0].Remaining())
+			assert.False(t, got[0].Over())
+			assert.Equal(t, -3*time.Hour-30*time.Minute, got[2].Remaining())
+			assert.True(t, got[2].Over())
+		}
+
+		got, err = sut.StatusesFor(ctx, "globex")
+		assert.NoError(t, err)
+		assert.Empty(t, got)
+	})
+}
@@ -0,0 +1,107 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"sort"
+	"time"
+)
+
+var _ app.ComplianceChecker = (*ComplianceChecker)(nil)
+
+type ComplianceChecker struct {
+	sessionLister app.SessionLister
+	rules         app.WorkingTimeRules
+}
+
+func NewComplianceChecker(sessionLister app.SessionLister, rules app.WorkingTimeRules) ComplianceChecker {
+	return ComplianceChecker{sessionLister: sessionLister, rules: rules}
+}
+
+// Compliance checks each day worked in the period against the rules. Days are in the location of the period, and the
+// work done the day before the period is used to check the rest before its first day.
+func (c ComplianceChecker) Compliance(ctx context.Context, period app.Period) (app.Compliance, error) {
+	dayBefore := app.DayPeriod(period.From, -1).From
+	sessions, err := c.sessionLister.Sessions(ctx, app.Period{From: dayBefore, To: period.To})
+	if err != nil {
+		return app.Compliance{}, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	compliance := app.Compliance{Period: period, Rules: c.rules}
+	var previous *app.WorkDay
+	for _, day := range workDays(sessions, period.From.Location()) {
+		day := day
+		if day.Day.From.Before(dayBefore) {
+			continue
+		}
+		if previous != nil && !previous.Day.To.Before(day.Day.From) {
+			day.Rest = day.Started.Sub(previous.Finished)
+		}
+		previous = &day
+		if day.Day.From.Before(period.From) || !day.Day.From.Before(period.To) {
+			continue
+		}
+		day.Violations = c.check(day)
+		compliance.Days = append(compliance.Days, day)
+	}
+
+	return compliance, nil
+}
+
+// check returns the rules broken on the day. Only the strictest break rule which was broken is returned.
+func (c ComplianceChecker) check(day app.WorkDay) []app.Violation {
+	var violations []app.Violation
+	var brokenBreak *app.BreakRule
+	for i, rule := range c.rules.Breaks {
+		if day.Worked > rule.After && day.Breaks < rule.Minimum && (brokenBreak == nil || rule.Minimum > brokenBreak.Minimum) {
+			brokenBreak = &c.rules.Breaks[i]
+		}
+	}
+	if brokenBreak != nil {
+		violations = append(violations, app.Violation{Kind: app.ViolationBreak, Actual: day.Breaks, Limit: brokenBreak.Minimum, After: brokenBreak.After})
+	}
+	if c.rules.MaxDaily > 0 && day.Worked > c.rules.MaxDaily {
+		violations = append(violations, app.Violation{Kind: app.ViolationMaxDaily, Actual: day.Worked, Limit: c.rules.MaxDaily})
+	}
+	if c.rules.MinRest > 0 && day.Rest > 0 && day.Rest < c.rules.MinRest {
+		violations = append(violations, app.Violation{Kind: app.ViolationRest, Actual: day.Rest, Limit: c.rules.MinRest})
+	}
+	return violations
+}
+
+// workDays merges overlapping sessions and groups them by the day they started on, in location, oldest first.
+func workDays(sessions []app.Session, location *time.Location) []app.WorkDay {
+	sessions = append([]app.Session(nil), sessions...)
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].Started.Before(sessions[j].Started)
+	})
+
+	var days []app.WorkDay
+	for _, s := range sessions {
+		if len(days) > 0 {
+			last := &days[len(days)-1]
+			switch {
+			case !s.Started.After(last.Finished):
+				// The session overlaps the time already counted, so only the rest of it counts.
+				if s.Finished.After(last.Finished) {
+					last.Worked += s.Finished.Sub(last.Finished)
+					last.Finished = s.Finished
+				}
+				continue
+			case s.Started.Before(last.Day.To):
+				last.Breaks += s.Started.Sub(last.Finished)
+				last.Worked += s.Duration()
+				last.Finished = s.Finished
+				continue
+			}
+		}
+		days = append(days, app.WorkDay{
+			Day:      app.DayPeriod(s.Started.In(location), 0),
+			Started:  s.Started,
+			Finished: s.Finished,
+			Worked:   s.Duration(),
+		})
+	}
+	return days
+}
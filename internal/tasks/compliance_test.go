@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestComplianceChecker_Compliance(t *testing.T) {
+	ctx := context.Background()
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		at        time.Time
+	}{
+		// The day before the period only counts towards the rest before its first day.
+		{app.EventTypeTaskStarted, "a", at(5, 14, 0)},
+		{app.EventTypeTaskFinished, "a", at(5, 23, 0)},
+		// 6 June: 7h with a 20 minute break, after only 9h of rest.
+		{app.EventTypeTaskStarted, "a", at(6, 8, 0)},
+		{app.EventTypeTaskFinished, "a", at(6, 12, 0)},
+		{app.EventTypeTaskStarted, "b", at(6, 12, 20)},
+		{app.EventTypeTaskFinished, "b", at(6, 15, 20)},
+		// 7 June: 11h with a 30 minute break, carrying on past midnight, with an overlapping session.
+		{app.EventTypeTaskStarted, "a", at(7, 14, 0)},
+		{app.EventTypeTaskFinished, "a", at(7, 20, 0)},
+		{app.EventTypeTaskStarted, "b", at(7, 19, 0)},
+		{app.EventTypeTaskFinished, "b", at(7, 20, 0)},
+		{app.EventTypeTaskStarted, "a", at(7, 20, 30)},
+		{app.EventTypeTaskFinished, "a", at(8, 1, 30)},
+		// 8 June: 4h, after 11h30 of rest.
+		{app.EventTypeTaskStarted, "a", at(8, 13, 0)},
+		{app.EventTypeTaskFinished, "a", at(8, 17, 0)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, CreatedAt: e.at}))
+	}
+	rules := app.WorkingTimeRules{
+		Breaks:   []app.BreakRule{{After: 6 * time.Hour, Minimum: 30 * time.Minute}, {After: 9 * time.Hour, Minimum: 45 * time.Minute}},
+		MaxDaily: 10 * time.Hour,
+		MinRest:  11 * time.Hour,
+	}
+	sut := NewComplianceChecker(SessionCollector{eventLister: store, now: func() time.Time { return at(9, 12, 0) }}, rules)
+
+	got, err := sut.Compliance(ctx, app.Period{From: at(6, 0, 0), To: at(9, 0, 0)})
+	assert.NoError(t, err)
+	assert.Equal(t, []app.WorkDay{
+		{
+			Day:      app.DayPeriod(at(6, 0, 0), 0),
+			Started:  at(6, 8, 0),
+			Finished: at(6, 15, 20),
+			Worked:   7 * time.Hour,
+			Breaks:   20 * time.Minute,
+			Rest:     9 * time.Hour,
+			Violations: []app.Violation{
+				{Kind: app.ViolationBreak, Actual: 20 * time.Minute, Limit: 30 * time.Minute, After: 6 * time.Hour},
+				{Kind: app.ViolationRest, Actual: 9 * time.Hour, Limit: 11 * time.Hour},
+			},
+		},
+		{
+			Day:      app.DayPeriod(at(7, 0, 0), 0),
+			Started:  at(7, 14, 0),
+			Finished: at(8, 1, 30),
+			Worked:   11 * time.Hour,
+			Breaks:   30 * time.Minute,
+			Rest:     22*time.Hour + 40*time.Minute,
+			Violations: []app.Violation{
+				{Kind: app.ViolationBreak, Actual: 30 * time.Minute, Limit: 45 * time.Minute, After: 9 * time.Hour},
+				{Kind: app.ViolationMaxDaily, Actual: 11 * time.Hour, Limit: 10 * time.Hour},
+			},
+		},
+		{
+			Day:      app.DayPeriod(at(8, 0, 0), 0),
+			Started:  at(8, 13, 0),
+			Finished: at(8, 17, 0),
+			Worked:   4 * time.Hour,
+			Rest:     11*time.Hour + 30*time.Minute,
+		},
+	}, got.Days)
+	assert.Equal(t, 4, got.Violations())
+}
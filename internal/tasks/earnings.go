@@ -0,0 +1,92 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"sort"
+	"time"
+)
+
+var _ app.EarningsCalculator = (*EarningsCalculator)(nil)
+
+type EarningsCalculator struct {
+	sessionLister app.SessionLister
+	rateLister    app.RateLister
+}
+
+func NewEarningsCalculator(sessionLister app.SessionLister, rateLister app.RateLister) EarningsCalculator {
+	return EarningsCalculator{sessionLister: sessionLister, rateLister: rateLister}
+}
+
+// Earnings multiplies the time spent in each completed session in the period by the rate in effect when the session
+// started, with a line for each task and rate, and a total for each currency.
+func (c EarningsCalculator) Earnings(ctx context.Context, period app.Period) (app.Earnings, error) {
+	sessions, err := c.sessionLister.Sessions(ctx, period)
+	if err != nil {
+		return app.Earnings{}, fmt.Errorf("listing sessions: %w", err)
+	}
+	rates, err := c.rateLister.Rates(ctx)
+	if err != nil {
+		return app.Earnings{}, fmt.Errorf("listing rates: %w", err)
+	}
+
+	earnings := app.Earnings{Period: period}
+	// lines are keyed by task and rate. Rates are identified by what they are for and when they take effect, as
+	// only one of them is listed for each.
+	type lineKey struct {
+		taskName, rateTask, rateTag, effectiveFrom string
+		rated                                      bool
+	}
+	lines := map[lineKey]*app.EarningsLine{}
+	currencies := map[string]*app.CurrencyTotal{}
+	for _, s := range sessions {
+		if s.InProgress {
+			continue
+		}
+		rate, rated := rates.For(s.TaskName, s.Tags, s.Started)
+		within, _ := s.Within(period)
+		duration := within.Duration()
+		amount := duration.Hours() * rate.Amount
+
+		key := lineKey{taskName: s.TaskName, rateTask: rate.Task, rateTag: rate.Tag, effectiveFrom: rate.EffectiveFrom.UTC().Format(time.RFC3339Nano), rated: rated}
+		line, ok := lines[key]
+		if !ok {
+			line = &app.EarningsLine{TaskName: s.TaskName, Rate: rate}
+			lines[key] = line
+		}
+		line.Sessions++
+		line.Duration += duration
+		line.Amount += amount
+
+		if !rated {
+			earnings.Unrated += duration
+			continue
+		}
+		currency, ok := currencies[rate.Currency]
+		if !ok {
+			currency = &app.CurrencyTotal{Currency: rate.Currency}
+			currencies[rate.Currency] = currency
+		}
+		currency.Duration += duration
+		currency.Amount += amount
+	}
+
+	for _, line := range lines {
+		earnings.Lines = append(earnings.Lines, *line)
+	}
+	sort.Slice(earnings.Lines, func(i, j int) bool {
+		if earnings.Lines[i].TaskName != earnings.Lines[j].TaskName {
+			return earnings.Lines[i].TaskName < earnings.Lines[j].TaskName
+		}
+		return earnings.Lines[i].Rate.EffectiveFrom.Before(earnings.Lines[j].Rate.EffectiveFrom)
+	})
+	for _, currency := range currencies {
+		earnings.Currencies = append(earnings.Currencies, *currency)
+	}
+	sort.Slice(earnings.Currencies, func(i, j int) bool {
+		return earnings.Currencies[i].Currency < earnings.Currencies[j].Currency
+	})
+
+	return earnings, nil
+}
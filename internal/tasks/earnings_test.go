@@ -0,0 +1,65 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEarningsCalculator_Earnings(t *testing.T) {
+	ctx := context.Background()
+	at := func(day, hour int) time.Time {
+		return time.Date(2022, 6, day, hour, 0, 0, 0, time.UTC)
+	}
+
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		tags      []string
+		at        time.Time
+	}{
+		{app.EventTypeTaskStarted, "acme-web", nil, at(1, 9)},
+		{app.EventTypeTaskFinished, "acme-web", nil, at(1, 11)},
+		{app.EventTypeTaskStarted, "acme-web", nil, at(20, 9)},
+		{app.EventTypeTaskFinished, "acme-web", nil, at(20, 10)},
+		{app.EventTypeTaskStarted, "support", []string{"globex"}, at(2, 9)},
+		{app.EventTypeTaskFinished, "support", nil, at(2, 12)},
+		{app.EventTypeTaskStarted, "admin", nil, at(3, 9)},
+		{app.EventTypeTaskFinished, "admin", nil, at(3, 10)},
+		{app.EventTypeTaskStarted, "running", []string{"globex"}, at(30, 9)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, Tags: e.tags, CreatedAt: e.at}))
+	}
+
+	rates := NewRates(store, store)
+	assert.NoError(t, rates.SetRate(ctx, app.Rate{Task: "acme-*", Amount: 100, Currency: "EUR"}))
+	assert.NoError(t, rates.SetRate(ctx, app.Rate{Task: "acme-*", Amount: 120, Currency: "EUR", EffectiveFrom: at(15, 0)}))
+	assert.NoError(t, rates.SetRate(ctx, app.Rate{Tag: "globex", Amount: 80.5, Currency: "USD"}))
+
+	month := app.MonthPeriod(at(1, 0), 0)
+	sut := NewEarningsCalculator(SessionCollector{eventLister: store, now: func() time.Time { return at(30, 12) }}, rates)
+	got, err := sut.Earnings(ctx, month)
+	assert.NoError(t, err)
+
+	if assert.Len(t, got.Lines, 4) {
+		assert.Equal(t, "acme-web", got.Lines[0].TaskName)
+		assert.Equal(t, 2*time.Hour, got.Lines[0].Duration)
+		assert.Equal(t, 200.0, got.Lines[0].Amount)
+		assert.Equal(t, "acme-web", got.Lines[1].TaskName)
+		assert.Equal(t, 120.0, got.Lines[1].Amount, "the later rate should apply to later sessions")
+		assert.Equal(t, "admin", got.Lines[2].TaskName)
+		assert.Empty(t, got.Lines[2].Rate.Currency)
+		assert.Equal(t, "support", got.Lines[3].TaskName)
+		assert.Equal(t, 241.5, got.Lines[3].Amount)
+	}
+	assert.Equal(t, []app.CurrencyTotal{
+		{Currency: "EUR", Duration: 3 * time.Hour, Amount: 320},
+		{Currency: "USD", Duration: 3 * time.Hour, Amount: 241.5},
+	}, got.Currencies)
+	assert.Equal(t, time.Hour, got.Unrated)
+}
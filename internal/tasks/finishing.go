@@ -22,6 +22,12 @@ func NewFinisher(eventStore app.EventStore, eventFinder app.EventFinder) Finishe
 	return Finisher{eventStore: eventStore, eventFinder: eventFinder, now: time.Now, newUUID: uuid.New}
 }
 
+// WithClock returns a copy which records events at the time returned by now, e.g. to backdate them.
+func (f Finisher) WithClock(now func() time.Time) Finisher {
+	f.now = now
+	return f
+}
+
 func (f Finisher) Finish(ctx context.Context, taskName string) error {
 	latest, err := f.eventFinder.LatestByName(ctx, taskName)
 	switch {
@@ -33,11 +39,16 @@ func (f Finisher) Finish(ctx context.Context, taskName string) error {
 		return fmt.Errorf("task never started: %w", app.ErrTaskNotStarted)
 	}
 
+	now := f.now()
+	if err == nil && now.Before(latest.CreatedAt) {
+		return fmt.Errorf("task would finish before it started at %s: %w", latest.CreatedAt, app.ErrEventOutOfOrder)
+	}
+
 	if err := f.eventStore.Store(ctx, app.Event{
 		ID:        f.newUUID(),
 		Type:      app.EventTypeTaskFinished,
 		TaskName:  taskName,
-		CreatedAt: f.now(),
+		CreatedAt: now,
 	}); err != nil {
 		return fmt.Errorf("storing event: %w", err)
 	}
@@ -102,6 +102,35 @@ func TestFinisher_Finish(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "unable to finish task before it started",
+			fields: fields{
+				eventFinder: func() *app_mocks.EventFinder {
+					m := &app_mocks.EventFinder{}
+					m.
+						On("LatestByName", mock.Anything, "test").
+						Once().
+						Return(app.Event{
+							ID:        uuid.UUID{},
+							Type:      app.EventTypeTaskStarted,
+							TaskName:  "test",
+							CreatedAt: now.Add(1 * time.Minute),
+						}, nil)
+					return m
+				}(),
+				eventStore: &app_mocks.EventStore{},
+			},
+			args: args{
+				ctx:      context.Background(),
+				taskName: "test",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t,
+					errors.Is(err, app.ErrEventOutOfOrder),
+					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventOutOfOrder, err),
+				)
+			},
+		},
 		{
 			name: "unable to finish task never started",
 			fields: fields{
@@ -0,0 +1,174 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"sort"
+	"time"
+)
+
+var (
+	_ app.GoalSetter      = (*Goals)(nil)
+	_ app.GoalLister      = (*Goals)(nil)
+	_ app.ProgressTracker = (*Goals)(nil)
+)
+
+// The keys of the data in goals set events.
+const (
+	goalsDaily  = "daily"
+	goalsWeekly = "weekly"
+)
+
+// Goals keeps the daily and weekly goals as events, and works out the progress towards them.
+type Goals struct {
+	eventStore    app.EventStore
+	eventLister   app.EventLister
+	sessionLister app.SessionLister
+	absenceLister app.AbsenceLister
+	contract      app.Contract
+	firstDay      time.Weekday
+	now           func() time.Time
+	newUUID       func() uuid.UUID
+}
+
+// NewGoals returns the goals, with weeks starting on firstDay. The contract, which may be zero, sets which days of the
+// week are worked.
+func NewGoals(eventStore app.EventStore, eventLister app.EventLister, sessionLister app.SessionLister, absenceLister app.AbsenceLister, contract app.Contract, firstDay time.Weekday) Goals {
+	return Goals{
+		eventStore:    eventStore,
+		eventLister:   eventLister,
+		sessionLister: sessionLister,
+		absenceLister: absenceLister,
+		contract:      contract,
+		firstDay:      firstDay,
+		now:           time.Now,
+		newUUID:       uuid.New,
+	}
+}
+
+// SetGoals records the daily and weekly goals, replacing both of the goals set before.
+func (g Goals) SetGoals(ctx context.Context, goals app.Goals) error {
+	switch {
+	case goals.Daily < 0 || goals.Weekly < 0:
+		return fmt.Errorf("goals must not be negative: %w", app.ErrInvalidGoal)
+	case goals.Daily > 24*time.Hour:
+		return fmt.Errorf("daily goal [%s] must not be more than 24h: %w", goals.Daily, app.ErrInvalidGoal)
+	case goals.Weekly > 7*24*time.Hour:
+		return fmt.Errorf("weekly goal [%s] must not be more than 168h: %w", goals.Weekly, app.ErrInvalidGoal)
+	}
+
+	if err := g.eventStore.Store(ctx, app.Event{
+		ID:        g.newUUID(),
+		Type:      app.EventTypeGoalsSet,
+		CreatedAt: g.now(),
+		Data: map[string]string{
+			goalsDaily:  goals.Daily.String(),
+			goalsWeekly: goals.Weekly.String(),
+		},
+	}); err != nil {
+		return fmt.Errorf("storing event: %w", err)
+	}
+
+	return nil
+}
+
+// Goals returns the goals set most recently, or no goals if none have been set.
+func (g Goals) Goals(ctx context.Context) (app.Goals, error) {
+	events, err := g.eventLister.FetchAll(ctx)
+	if err != nil {
+		return app.Goals{}, fmt.Errorf("fetching events: %w", err)
+	}
+
+	var latest app.Goals
+	for _, e := range events {
+		if e.Type != app.EventTypeGoalsSet || (!latest.SetAt.IsZero() && !e.CreatedAt.After(latest.SetAt)) {
+			continue
+		}
+		if latest, err = parseGoals(e); err != nil {
+			return app.Goals{}, err
+		}
+	}
+
+	return latest, nil
+}
+
+// Progress adds up the time tracked on the day and in the week containing at, including sessions in progress, and
+// projects when the daily goal will be reached. Time spent on several tasks at once only counts once. An absence
+// excuses the daily goal on its day, and its day's share of the weekly goal, or half as much for half a day.
+func (g Goals) Progress(ctx context.Context, at time.Time) (app.Progress, error) {
+	goals, err := g.Goals(ctx)
+	if err != nil {
+		return app.Progress{}, err
+	}
+
+	progress := app.Progress{
+		At:    at,
+		Goals: goals,
+		Day:   app.GoalProgress{Period: app.DayPeriod(at, 0), Goal: goals.Daily},
+		Week:  app.GoalProgress{Period: app.WeekPeriod(at, g.firstDay, 0), Goal: goals.Weekly},
+	}
+	sessions, err := g.sessionLister.Sessions(ctx, progress.Week.Period)
+	if err != nil {
+		return app.Progress{}, fmt.Errorf("listing sessions: %w", err)
+	}
+	for _, s := range sessions {
+		if s.InProgress {
+			progress.Running = append(progress.Running, s.TaskName)
+		}
+	}
+	for _, s := range mergeOverlapping(sessions) {
+		for _, p := range []*app.GoalProgress{&progress.Day, &progress.Week} {
+			if within, ok := s.Within(p.Period); ok {
+				p.Tracked += within.Duration()
+			}
+		}
+	}
+	sort.Strings(progress.Running)
+
+	absences, err := g.absenceLister.Absences(ctx)
+	if err != nil {
+		return app.Progress{}, fmt.Errorf("listing absences: %w", err)
+	}
+	progress.Day.Excused = absences.Excused(at, goals.Daily)
+	for day := progress.Week.Period.From; day.Before(progress.Week.Period.To); day = day.AddDate(0, 0, 1) {
+		absence, ok := absences.On(day)
+		if !ok {
+			continue
+		}
+		progress.Absences = append(progress.Absences, absence)
+		progress.Week.Excused += time.Duration(float64(g.weeklyShare(day, goals.Weekly)) * absence.Fraction())
+	}
+
+	if remaining := progress.Day.Remaining(); goals.Daily > 0 && remaining > 0 {
+		progress.ProjectedFinish = at.Add(remaining)
+	}
+
+	return progress, nil
+}
+
+// weeklyShare is the part of the weekly goal which falls on the day. With a contract, it's the day's share of the
+// contracted hours, so that a day off counts for more in a shorter working week. Without one, the goal is spread
+// evenly over Monday to Friday.
+func (g Goals) weeklyShare(day time.Time, weekly time.Duration) time.Duration {
+	if contracted := g.contract.Weekly(); !g.contract.IsZero() && contracted > 0 {
+		return time.Duration(float64(weekly) * float64(g.contract.Hours[day.Weekday()]) / float64(contracted))
+	}
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return 0
+	}
+	return weekly / 5
+}
+
+func parseGoals(e app.Event) (app.Goals, error) {
+	goals := app.Goals{SetAt: e.CreatedAt}
+	var err error
+	if goals.Daily, err = time.ParseDuration(e.Data[goalsDaily]); err != nil {
+		return app.Goals{}, fmt.Errorf("parsing daily goal [%s]: %w", e.ID, err)
+	}
+	if goals.Weekly, err = time.ParseDuration(e.Data[goalsWeekly]); err != nil {
+		return app.Goals{}, fmt.Errorf("parsing weekly goal [%s]: %w", e.ID, err)
+	}
+	return goals, nil
+}
@@ -0,0 +1,177 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGoals_SetGoals(t *testing.T) {
+	tests := []struct {
+		name    string
+		goals   app.Goals
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "daily and weekly", goals: app.Goals{Daily: 7*time.Hour + 30*time.Minute, Weekly: 37*time.Hour + 30*time.Minute}, wantErr: assert.NoError},
+		{name: "daily only", goals: app.Goals{Daily: 8 * time.Hour}, wantErr: assert.NoError},
+		{name: "negative", goals: app.Goals{Weekly: -time.Hour}, wantErr: assert.Error},
+		{name: "more than a day", goals: app.Goals{Daily: 25 * time.Hour}, wantErr: assert.Error},
+		{name: "more than a week", goals: app.Goals{Weekly: 169 * time.Hour}, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := eventstore.NewMemoryEventStore()
+			sut := NewGoals(store, store, NewSessionCollector(store), NewAbsences(store, store), app.Contract{}, time.Monday)
+			err := sut.SetGoals(context.Background(), tt.goals)
+			if !tt.wantErr(t, err) || err != nil {
+				assert.ErrorIs(t, err, app.ErrInvalidGoal)
+				return
+			}
+
+			got, err := sut.Goals(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.goals.Daily, got.Daily)
+			assert.Equal(t, tt.goals.Weekly, got.Weekly)
+		})
+	}
+}
+
+func TestGoals_Progress(t *testing.T) {
+	ctx := context.Background()
+	// 1 June 2022 is a Wednesday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+	now := at(1, 14, 0)
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		at        time.Time
+	}{
+		{app.EventTypeTaskStarted, "old", at(1, 9, 0).AddDate(0, 0, -3)},
+		{app.EventTypeTaskFinished, "old", at(1, 17, 0).AddDate(0, 0, -3)},
+		{app.EventTypeTaskStarted, "acme-web", at(1, 22, 0).AddDate(0, 0, -1)},
+		{app.EventTypeTaskFinished, "acme-web", at(1, 1, 0)},
+		{app.EventTypeTaskStarted, "acme-web", at(1, 9, 0)},
+		{app.EventTypeTaskFinished, "acme-web", at(1, 12, 0)},
+		{app.EventTypeTaskStarted, "acme-api", at(1, 13, 0)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, CreatedAt: e.at}))
+	}
+	newGoals := func(contract app.Contract) Goals {
+		goals := NewGoals(store, store, SessionCollector{eventLister: store, now: func() time.Time { return now }}, NewAbsences(store, store), contract, time.Monday)
+		goals.now = func() time.Time { return now }
+		return goals
+	}
+
+	t.Run("without goals", func(t *testing.T) {
+		got, err := newGoals(app.Contract{}).Progress(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 5*time.Hour, got.Day.Tracked)
+		assert.Equal(t, 7*time.Hour, got.Week.Tracked, "only time since the start of the week should count")
+		assert.True(t, got.ProjectedFinish.IsZero())
+	})
+
+	t.Run("with goals", func(t *testing.T) {
+		sut := newGoals(app.Contract{})
+		assert.NoError(t, sut.SetGoals(ctx, app.Goals{Daily: 6 * time.Hour, Weekly: 40 * time.Hour}))
+
+		got, err := sut.Progress(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, app.DayPeriod(now, 0), got.Day.Period)
+		assert.Equal(t, app.Period{From: at(1, 0, 0).AddDate(0, 0, -2), To: at(6, 0, 0)}, got.Week.Period)
+		assert.Equal(t, 6*time.Hour, got.Day.Goal)
+		assert.Equal(t, time.Hour, got.Day.Remaining())
+		assert.Equal(t, 33*time.Hour, got.Week.Remaining())
+		assert.Equal(t, []string{"acme-api"}, got.Running)
+		assert.Equal(t, at(1, 15, 0), got.ProjectedFinish)
+	})
+
+	t.Run("daily goal reached", func(t *testing.T) {
+		sut := newGoals(app.Contract{})
+		now = now.Add(time.Second)
+		assert.NoError(t, sut.SetGoals(ctx, app.Goals{Daily: 4 * time.Hour}))
+
+		got, err := sut.Progress(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Duration(0), got.Day.Remaining())
+		assert.True(t, got.ProjectedFinish.IsZero())
+		assert.Equal(t, time.Duration(0), got.Goals.Weekly, "later goals should replace both earlier ones")
+	})
+
+	t.Run("with absences", func(t *testing.T) {
+		sut := newGoals(app.Contract{})
+		now = now.Add(time.Second)
+		assert.NoError(t, sut.SetGoals(ctx, app.Goals{Daily: 8 * time.Hour, Weekly: 40 * time.Hour}))
+		assert.NoError(t, NewAbsences(store, store).AddAbsences(ctx,
+			app.Absence{Date: at(1, 0, 0), Kind: app.Vacation, Half: true},
+			app.Absence{Date: at(1, 0, 0).AddDate(0, 0, -2), Kind: app.Sick},
+			// Weekends don't count towards the weekly goal, and absences in other weeks don't count at all.
+			app.Absence{Date: at(5, 0, 0), Kind: app.Holiday},
+			app.Absence{Date: at(8, 0, 0), Kind: app.Holiday},
+		))
+
+		got, err := sut.Progress(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 4*time.Hour, got.Day.Excused)
+		assert.Equal(t, 4*time.Hour, got.Day.Target())
+		assert.Equal(t, time.Duration(0), got.Day.Remaining())
+		assert.Equal(t, 12*time.Hour, got.Week.Excused)
+		assert.Equal(t, 28*time.Hour, got.Week.Target())
+		assert.Len(t, got.Absences, 3)
+	})
+
+	t.Run("with absences under a contract", func(t *testing.T) {
+		// Monday to Thursday, so the weekly goal is spread over four days.
+		contract := app.Contract{Start: at(1, 0, 0).AddDate(0, -1, 0)}
+		for _, day := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday} {
+			contract.Hours[day] = 8 * time.Hour
+		}
+		sut := newGoals(contract)
+		assert.NoError(t, NewAbsences(store, store).AddAbsences(ctx, app.Absence{Date: at(3, 0, 0), Kind: app.Vacation}))
+
+		got, err := sut.Progress(ctx, now)
+		assert.NoError(t, err)
+		assert.Equal(t, 15*time.Hour, got.Week.Excused, "a quarter of the goal for Monday, an eighth for half of Wednesday and none for Friday or Sunday")
+		assert.Equal(t, 25*time.Hour, got.Week.Target())
+		assert.Len(t, got.Absences, 4)
+	})
+}
+
+func TestGoals_ProgressCountsOverlappingSessionsOnce(t *testing.T) {
+	ctx := context.Background()
+	at := func(hour, minute int) time.Time {
+		return time.Date(2022, 6, 1, hour, minute, 0, 0, time.UTC)
+	}
+	now := at(14, 0)
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		at        time.Time
+	}{
+		// acme-api runs alongside acme-web, and acme-docs alongside acme-api, until now.
+		{app.EventTypeTaskStarted, "acme-web", at(9, 0)},
+		{app.EventTypeTaskStarted, "acme-api", at(10, 0)},
+		{app.EventTypeTaskFinished, "acme-web", at(11, 0)},
+		{app.EventTypeTaskStarted, "acme-docs", at(11, 30)},
+		{app.EventTypeTaskFinished, "acme-api", at(12, 0)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, CreatedAt: e.at}))
+	}
+	sut := NewGoals(store, store, SessionCollector{eventLister: store, now: func() time.Time { return now }}, NewAbsences(store, store), app.Contract{}, time.Monday)
+	sut.now = func() time.Time { return now }
+	assert.NoError(t, sut.SetGoals(ctx, app.Goals{Daily: 6 * time.Hour}))
+
+	got, err := sut.Progress(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Hour, got.Day.Tracked)
+	assert.Equal(t, 5*time.Hour, got.Week.Tracked)
+	assert.Equal(t, []string{"acme-docs"}, got.Running)
+	assert.Equal(t, at(15, 0), got.ProjectedFinish)
+}
@@ -0,0 +1,44 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+)
+
+var _ app.EventImporter = (*Importer)(nil)
+
+type Importer struct {
+	eventStore  app.EventStore
+	eventLister app.EventLister
+}
+
+func NewImporter(eventStore app.EventStore, eventLister app.EventLister) Importer {
+	return Importer{eventStore: eventStore, eventLister: eventLister}
+}
+
+func (i Importer) Import(ctx context.Context, events []app.Event, dryRun bool) (result app.ImportResult, err error) {
+	existing, err := i.eventLister.FetchAll(ctx)
+	if err != nil {
+		return result, fmt.Errorf("fetching existing events: %w", err)
+	}
+
+	missing := missingEvents(events, existing)
+	result.Duplicates = len(events)
+	for _, e := range missing {
+		if !dryRun {
+			if err = i.eventStore.Store(ctx, e); err != nil {
+				return result, fmt.Errorf("storing event [%s]: %w", e.ID, err)
+			}
+		}
+		result.Imported++
+		result.Duplicates--
+		if result.Earliest.IsZero() {
+			result.Earliest = e.CreatedAt
+		}
+		result.Latest = e.CreatedAt
+	}
+	result.DuplicateInvoiceNumbers = duplicateInvoiceNumbers(existing, missing)
+
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestImporter_Import(t *testing.T) {
+	now := time.Now().Truncate(time.Second).UTC()
+	existing := app.Event{ID: uuid.New(), Type: app.EventTypeTaskStarted, TaskName: "test", CreatedAt: now.Add(-3 * time.Hour)}
+	older := app.Event{ID: uuid.New(), Type: app.EventTypeTaskStarted, TaskName: "other", CreatedAt: now.Add(-2 * time.Hour)}
+	newer := app.Event{ID: uuid.New(), Type: app.EventTypeTaskFinished, TaskName: "other", CreatedAt: now.Add(-1 * time.Hour)}
+
+	type args struct {
+		events []app.Event
+		dryRun bool
+	}
+	tests := []struct {
+		name       string
+		args       args
+		want       app.ImportResult
+		wantStored []app.Event
+	}{
+		{
+			name: "imports new events and skips existing ones",
+			args: args{
+				events: []app.Event{newer, existing, older},
+			},
+			want:       app.ImportResult{Imported: 2, Duplicates: 1, Earliest: older.CreatedAt, Latest: newer.CreatedAt},
+			wantStored: []app.Event{newer, older, existing},
+		},
+		{
+			name: "dry run stores nothing",
+			args: args{
+				events: []app.Event{newer, existing, older},
+				dryRun: true,
+			},
+			want:       app.ImportResult{Imported: 2, Duplicates: 1, Earliest: older.CreatedAt, Latest: newer.CreatedAt},
+			wantStored: []app.Event{existing},
+		},
+		{
+			name: "events repeated in the import are only imported once",
+			args: args{
+				events: []app.Event{older, older},
+			},
+			want:       app.ImportResult{Imported: 1, Duplicates: 1, Earliest: older.CreatedAt, Latest: older.CreatedAt},
+			wantStored: []app.Event{older, existing},
+		},
+		{
+			name:       "nothing to import",
+			args:       args{},
+			want:       app.ImportResult{},
+			wantStored: []app.Event{existing},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := eventstore.NewMemoryEventStore()
+			assert.NoError(t, store.Store(ctx, existing), "preparing stored test data")
+
+			got, err := NewImporter(store, store).Import(ctx, tt.args.events, tt.args.dryRun)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			stored, err := store.FetchAll(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStored, stored)
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_ app.Invoicer      = (*Invoices)(nil)
+	_ app.InvoiceLister = (*Invoices)(nil)
+)
+
+// The keys of the data in invoice created events.
+const (
+	invoiceNumber   = "number"
+	invoiceClient   = "client"
+	invoiceTask     = "task"
+	invoiceTag      = "tag"
+	invoiceFrom     = "from"
+	invoiceTo       = "to"
+	invoiceCurrency = "currency"
+	invoiceTotal    = "total"
+	invoiceSessions = "sessions"
+	invoiceLines    = "lines"
+)
+
+// invoiceLineRecord is how an invoice line is kept in an invoice created event, so that the invoice can be shown
+// again as it was issued, even if rates or rounding rules change.
+type invoiceLineRecord struct {
+	Task              string    `json:"task"`
+	RateTask          string    `json:"rate_task,omitempty"`
+	RateTag           string    `json:"rate_tag,omitempty"`
+	RateAmount        float64   `json:"rate_amount"`
+	RateEffectiveFrom time.Time `json:"rate_effective_from"`
+	Sessions          int       `json:"sessions"`
+	DurationSeconds   float64   `json:"duration_seconds"`
+	RoundedSeconds    float64   `json:"rounded_seconds"`
+	Quantity          float64   `json:"quantity"`
+	Amount            float64   `json:"amount"`
+}
+
+// Invoices invoices clients for the time spent on their tasks, recording each invoice as an event so that time is
+// never invoiced twice.
+type Invoices struct {
+	eventStore    app.EventStore
+	eventLister   app.EventLister
+	sessionLister app.SessionLister
+	rateLister    app.RateLister
+	rounding      app.RoundingRules
+	now           func() time.Time
+	newUUID       func() uuid.UUID
+}
+
+func NewInvoices(eventStore app.EventStore, eventLister app.EventLister, sessionLister app.SessionLister, rateLister app.RateLister, rounding app.RoundingRules) Invoices {
+	return Invoices{
+		eventStore:    eventStore,
+		eventLister:   eventLister,
+		sessionLister: sessionLister,
+		rateLister:    rateLister,
+		rounding:      rounding,
+		now:           time.Now,
+		newUUID:       uuid.New,
+	}
+}
+
+// Invoice invoices the completed sessions which started in the period and haven't been invoiced yet, with a line for
+// each task and rate. The time on each line is rounded by the rounding rules for each day, in the location of the
+// period. Invoices are numbered in sequence within the year they are issued, e.g. 2026-0001, following the highest
+// number issued so far that year.
+func (i Invoices) Invoice(ctx context.Context, request app.InvoiceRequest) (app.Invoice, error) {
+	issued, err := i.Invoices(ctx)
+	if err != nil {
+		return app.Invoice{}, err
+	}
+	sessions, err := i.sessionLister.Sessions(ctx, request.Period)
+	if err != nil {
+		return app.Invoice{}, fmt.Errorf("listing sessions: %w", err)
+	}
+	rates, err := i.rateLister.Rates(ctx)
+	if err != nil {
+		return app.Invoice{}, fmt.Errorf("listing rates: %w", err)
+	}
+
+	now := i.now()
+	invoiced := map[uuid.UUID]bool{}
+	sequence := 0
+	for _, invoice := range issued {
+		for _, id := range invoice.Sessions {
+			invoiced[id] = true
+		}
+		if n, ok := invoiceSequence(invoice.Number, now.Year()); ok && n > sequence {
+			sequence = n
+		}
+	}
+
+	invoice := app.Invoice{
+		Number:   fmt.Sprintf("%d-%04d", now.Year(), sequence+1),
+		Client:   request.Client,
+		Task:     request.Task,
+		Tag:      request.Tag,
+		Period:   request.Period,
+		IssuedAt: now,
+	}
+	type lineKey struct {
+		taskName, rateTask, rateTag, effectiveFrom string
+	}
+	lines := map[lineKey]*app.InvoiceLine{}
+	rounders := map[lineKey]*rounder{}
+	location := request.Period.From.Location()
+	for _, s := range sessions {
+		if s.InProgress || s.Started.Before(request.Period.From) || invoiced[s.ID] || !request.Matches(s.TaskName, s.Tags) {
+			continue
+		}
+		rate, ok := rates.For(s.TaskName, s.Tags, s.Started)
+		switch {
+		case !ok:
+			return app.Invoice{}, fmt.Errorf("%s has no rate: %w", s.TaskName, app.ErrCannotInvoice)
+		case invoice.Currency == "":
+			invoice.Currency = rate.Currency
+		case invoice.Currency != rate.Currency:
+			return app.Invoice{}, fmt.Errorf("rates are in both %s and %s, so each needs a separate invoice: %w", invoice.Currency, rate.Currency, app.ErrCannotInvoice)
+		}
+
+		key := lineKey{taskName: s.TaskName, rateTask: rate.Task, rateTag: rate.Tag, effectiveFrom: rate.EffectiveFrom.UTC().Format(time.RFC3339Nano)}
+		line, ok := lines[key]
+		if !ok {
+			line = &app.InvoiceLine{TaskName: s.TaskName, Rate: rate}
+			lines[key] = line
+			rounders[key] = newRounder(i.rounding)
+		}
+		line.Sessions++
+		line.Duration += s.Duration()
+		for _, part := range splitByDay(s, location) {
+			rounders[key].add(app.DayPeriod(part.Started.In(location), 0).From, part)
+		}
+		invoice.Sessions = append(invoice.Sessions, s.ID)
+	}
+	if len(invoice.Sessions) == 0 {
+		return app.Invoice{}, fmt.Errorf("no uninvoiced time: %w", app.ErrNothingToInvoice)
+	}
+
+	for key, line := range lines {
+		for _, rounded := range rounders[key].totals() {
+			line.Rounded += rounded
+		}
+		line.Quantity = app.RoundCents(line.Rounded.Hours())
+		line.Amount = app.RoundCents(line.Quantity * line.Rate.Amount)
+		invoice.Lines = append(invoice.Lines, *line)
+		invoice.Total += line.Amount
+	}
+	invoice.Total = app.RoundCents(invoice.Total)
+	sort.Slice(invoice.Lines, func(a, b int) bool {
+		if invoice.Lines[a].TaskName != invoice.Lines[b].TaskName {
+			return invoice.Lines[a].TaskName < invoice.Lines[b].TaskName
+		}
+		return invoice.Lines[a].Rate.EffectiveFrom.Before(invoice.Lines[b].Rate.EffectiveFrom)
+	})
+
+	if request.DryRun {
+		return invoice, nil
+	}
+	event, err := i.newEvent(invoice)
+	if err != nil {
+		return app.Invoice{}, err
+	}
+	if err = i.eventStore.Store(ctx, event); err != nil {
+		return app.Invoice{}, fmt.Errorf("storing event: %w", err)
+	}
+
+	return invoice, nil
+}
+
+// invoiceSequence returns the sequence of an invoice number issued in the year, e.g. 12 for 2026-0012.
+func invoiceSequence(number string, year int) (int, bool) {
+	prefix := strconv.Itoa(year) + "-"
+	if !strings.HasPrefix(number, prefix) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(number, prefix))
+	return n, err == nil
+}
+
+// duplicateInvoiceNumbers returns the numbers, sorted, of the added invoices which share their number with another
+// invoice. This happens when invoices are issued in two event stores which are then merged.
+func duplicateInvoiceNumbers(existing, added []app.Event) []string {
+	count := map[string]int{}
+	addedNumbers := map[string]bool{}
+	for n, events := range [][]app.Event{existing, added} {
+		for _, e := range events {
+			if e.Type != app.EventTypeInvoiceCreated {
+				continue
+			}
+			number := e.Data[invoiceNumber]
+			count[number]++
+			if n == 1 {
+				addedNumbers[number] = true
+			}
+		}
+	}
+
+	var duplicates []string
+	for number := range addedNumbers {
+		if count[number] > 1 {
+			duplicates = append(duplicates, number)
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates
+}
+
+func (i Invoices) newEvent(invoice app.Invoice) (app.Event, error) {
+	records := make([]invoiceLineRecord, len(invoice.Lines))
+	for n, l := range invoice.Lines {
+		records[n] = invoiceLineRecord{
+			Task:              l.TaskName,
+			RateTask:          l.Rate.Task,
+			RateTag:           l.Rate.Tag,
+			RateAmount:        l.Rate.Amount,
+			RateEffectiveFrom: l.Rate.EffectiveFrom,
+			Sessions:          l.Sessions,
+			DurationSeconds:   l.Duration.Seconds(),
+			RoundedSeconds:    l.Rounded.Seconds(),
+			Quantity:          l.Quantity,
+			Amount:            l.Amount,
+		}
+	}
+	lines, err := json.Marshal(records)
+	if err != nil {
+		return app.Event{}, fmt.Errorf("encoding invoice lines: %w", err)
+	}
+	sessions := make([]string, len(invoice.Sessions))
+	for n, id := range invoice.Sessions {
+		sessions[n] = id.String()
+	}
+
+	data := map[string]string{
+		invoiceNumber:   invoice.Number,
+		invoiceClient:   invoice.Client,
+		invoiceFrom:     invoice.Period.From.Format(time.RFC3339Nano),
+		invoiceTo:       invoice.Period.To.Format(time.RFC3339Nano),
+		invoiceCurrency: invoice.Currency,
+		invoiceTotal:    strconv.FormatFloat(invoice.Total, 'f', -1, 64),
+		invoiceSessions: strings.Join(sessions, ","),
+		invoiceLines:    string(lines),
+	}
+	if invoice.Task != "" {
+		data[invoiceTask] = invoice.Task
+	}
+	if invoice.Tag != "" {
+		data[invoiceTag] = invoice.Tag
+	}
+
+	return app.Event{ID: i.newUUID(), Type: app.EventTypeInvoiceCreated, CreatedAt: invoice.IssuedAt, Data: data}, nil
+}
+
+// Invoices returns every invoice which has been recorded, oldest first.
+func (i Invoices) Invoices(ctx context.Context) ([]app.Invoice, error) {
+	events, err := i.eventLister.FetchAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching events: %w", err)
+	}
+
+	var invoices []app.Invoice
+	for _, e := range events {
+		if e.Type != app.EventTypeInvoiceCreated {
+			continue
+		}
+		invoice, err := parseInvoice(e)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, invoice)
+	}
+	sort.SliceStable(invoices, func(a, b int) bool {
+		return invoices[a].IssuedAt.Before(invoices[b].IssuedAt)
+	})
+
+	return invoices, nil
+}
+
+func parseInvoice(e app.Event) (app.Invoice, error) {
+	invoice := app.Invoice{
+		Number:   e.Data[invoiceNumber],
+		Client:   e.Data[invoiceClient],
+		Task:     e.Data[invoiceTask],
+		Tag:      e.Data[invoiceTag],
+		IssuedAt: e.CreatedAt,
+		Currency: e.Data[invoiceCurrency],
+	}
+
+	var err error
+	if invoice.Period.From, err = time.Parse(time.RFC3339Nano, e.Data[invoiceFrom]); err != nil {
+		return app.Invoice{}, fmt.Errorf("parsing start of invoice [%s]: %w", e.ID, err)
+	}
+	if invoice.Period.To, err = time.Parse(time.RFC3339Nano, e.Data[invoiceTo]); err != nil {
+		return app.Invoice{}, fmt.Errorf("parsing end of invoice [%s]: %w", e.ID, err)
+	}
+	if invoice.Total, err = strconv.ParseFloat(e.Data[invoiceTotal], 64); err != nil {
+		return app.Invoice{}, fmt.Errorf("parsing total of invoice [%s]: %w", e.ID, err)
+	}
+	for _, id := range strings.Split(e.Data[invoiceSessions], ",") {
+		sessionID, err := uuid.Parse(id)
+		if err != nil {
+			return app.Invoice{}, fmt.Errorf("parsing sessions of invoice [%s]: %w", e.ID, err)
+		}
+		invoice.Sessions = append(invoice.Sessions, sessionID)
+	}
+
+	var records []invoiceLineRecord
+	if err = json.Unmarshal([]byte(e.Data[invoiceLines]), &records); err != nil {
+		return app.Invoice{}, fmt.Errorf("parsing lines of invoice [%s]: %w", e.ID, err)
+	}
+	for _, r := range records {
+		invoice.Lines = append(invoice.Lines, app.InvoiceLine{
+			TaskName: r.Task,
+			Rate: app.Rate{
+				Task:          r.RateTask,
+				Tag:           r.RateTag,
+				Amount:        r.RateAmount,
+				Currency:      invoice.Currency,
+				EffectiveFrom: r.RateEffectiveFrom,
+			},
+			Sessions: r.Sessions,
+			Duration: time.Duration(r.DurationSeconds * float64(time.Second)),
+			Rounded:  time.Duration(r.RoundedSeconds * float64(time.Second)),
+			Quantity: r.Quantity,
+			Amount:   r.Amount,
+		})
+	}
+
+	return invoice, nil
+}
@@ -0,0 +1,167 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestInvoices_Invoice(t *testing.T) {
+	ctx := context.Background()
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+	june := app.MonthPeriod(at(1, 0, 0), 0)
+
+	newStore := func(t *testing.T) eventstore.MemoryEventStore {
+		store := eventstore.NewMemoryEventStore()
+		for _, e := range []struct {
+			eventType app.EventType
+			taskName  string
+			tags      []string
+			at        time.Time
+		}{
+			{app.EventTypeTaskStarted, "acme-web", nil, at(1, 9, 0)},
+			{app.EventTypeTaskFinished, "acme-web", nil, at(1, 10, 5)},
+			{app.EventTypeTaskStarted, "acme-web", nil, at(2, 9, 0)},
+			{app.EventTypeTaskFinished, "acme-web", nil, at(2, 9, 50)},
+			{app.EventTypeTaskStarted, "acme-api", nil, at(3, 14, 0)},
+			{app.EventTypeTaskFinished, "acme-api", nil, at(3, 15, 0)},
+			{app.EventTypeTaskStarted, "globex", []string{"globex"}, at(3, 9, 0)},
+			{app.EventTypeTaskFinished, "globex", nil, at(3, 10, 0)},
+			{app.EventTypeTaskStarted, "acme-web", nil, at(30, 17, 0)},
+		} {
+			assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, Tags: e.tags, CreatedAt: e.at}))
+		}
+		rates := NewRates(store, store)
+		assert.NoError(t, rates.SetRate(ctx, app.Rate{Task: "acme-*", Amount: 100, Currency: "EUR"}))
+		assert.NoError(t, rates.SetRate(ctx, app.Rate{Task: "acme-api", Amount: 150.5, Currency: "EUR"}))
+		return store
+	}
+	newInvoices := func(store eventstore.MemoryEventStore, now time.Time) Invoices {
+		rounding := app.RoundingRules{{Task: "acme-web", Mode: app.RoundUp, Increment: 15 * time.Minute, Per: app.PerSession}}
+		sessions := SessionCollector{eventLister: store, now: func() time.Time { return at(30, 18, 0) }}
+		invoices := NewInvoices(store, store, sessions, NewRates(store, store), rounding)
+		invoices.now = func() time.Time { return now }
+		return invoices
+	}
+
+	t.Run("invoices rounded time at each task's rate", func(t *testing.T) {
+		store := newStore(t)
+		sut := newInvoices(store, at(30, 18, 0))
+
+		got, err := sut.Invoice(ctx, app.InvoiceRequest{Client: "Acme", Task: "acme-*", Period: june})
+		assert.NoError(t, err)
+		assert.Equal(t, "2022-0001", got.Number)
+		assert.Equal(t, "EUR", got.Currency)
+		assert.Len(t, got.Sessions, 3, "only completed sessions for acme should be invoiced")
+		if assert.Len(t, got.Lines, 2) {
+			assert.Equal(t, app.InvoiceLine{
+				TaskName: "acme-api",
+				Rate:     got.Lines[0].Rate,
+				Sessions: 1,
+				Duration: time.Hour,
+				Rounded:  time.Hour,
+				Quantity: 1,
+				Amount:   150.5,
+			}, got.Lines[0])
+			assert.Equal(t, 115*time.Minute, got.Lines[1].Duration)
+			assert.Equal(t, 135*time.Minute, got.Lines[1].Rounded, "each session should be rounded up to 15 minutes")
+			assert.Equal(t, 2.25, got.Lines[1].Quantity)
+			assert.Equal(t, 225.0, got.Lines[1].Amount)
+		}
+		assert.Equal(t, 375.5, got.Total)
+
+		recorded, err := sut.Invoices(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, recorded, 1) {
+			assert.Equal(t, got.Number, recorded[0].Number)
+			if assert.Len(t, recorded[0].Lines, len(got.Lines)) {
+				for i, line := range got.Lines {
+					assert.Equal(t, line.TaskName, recorded[0].Lines[i].TaskName)
+					assert.Equal(t, line.Rate.Amount, recorded[0].Lines[i].Rate.Amount)
+					assert.Equal(t, line.Duration, recorded[0].Lines[i].Duration)
+					assert.Equal(t, line.Rounded, recorded[0].Lines[i].Rounded)
+					assert.Equal(t, line.Amount, recorded[0].Lines[i].Amount)
+				}
+			}
+			assert.Equal(t, got.Sessions, recorded[0].Sessions)
+			assert.Equal(t, got.Total, recorded[0].Total)
+			assert.True(t, got.Period.From.Equal(recorded[0].Period.From))
+		}
+
+		_, err = sut.Invoice(ctx, app.InvoiceRequest{Client: "Acme", Task: "acme-*", Period: june})
+		assert.ErrorIs(t, err, app.ErrNothingToInvoice, "time shouldn't be invoiced twice")
+	})
+
+	t.Run("numbers invoices in sequence each year", func(t *testing.T) {
+		store := newStore(t)
+		assert.NoError(t, NewRates(store, store).SetRate(ctx, app.Rate{Tag: "globex", Amount: 90, Currency: "USD"}))
+		_, err := newInvoices(store, at(30, 18, 0)).Invoice(ctx, app.InvoiceRequest{Task: "acme-api", Period: june})
+		assert.NoError(t, err)
+		got, err := newInvoices(store, at(30, 18, 1)).Invoice(ctx, app.InvoiceRequest{Tag: "globex", Period: june})
+		assert.NoError(t, err)
+		assert.Equal(t, "2022-0002", got.Number)
+		assert.Equal(t, "USD", got.Currency)
+
+		got, err = newInvoices(store, time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)).Invoice(ctx, app.InvoiceRequest{Task: "acme-web", Period: june})
+		assert.NoError(t, err)
+		assert.Equal(t, "2023-0001", got.Number)
+	})
+
+	t.Run("numbers follow the highest number once stores are merged", func(t *testing.T) {
+		local, remote := newStore(t), newStore(t)
+		for _, store := range []eventstore.MemoryEventStore{local, remote} {
+			assert.NoError(t, NewRates(store, store).SetRate(ctx, app.Rate{Tag: "globex", Amount: 90, Currency: "USD"}))
+		}
+		_, err := newInvoices(local, at(30, 18, 0)).Invoice(ctx, app.InvoiceRequest{Task: "acme-web", Period: june})
+		assert.NoError(t, err)
+		_, err = newInvoices(local, at(30, 18, 1)).Invoice(ctx, app.InvoiceRequest{Task: "acme-api", Period: june})
+		assert.NoError(t, err)
+		got, err := newInvoices(remote, at(30, 18, 2)).Invoice(ctx, app.InvoiceRequest{Task: "acme-web", Period: june})
+		assert.NoError(t, err)
+		assert.Equal(t, "2022-0001", got.Number, "stores which haven't been merged can't know each other's numbers")
+
+		merged, err := NewSyncer().Sync(ctx, local, remote)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2022-0001"}, merged.DuplicateInvoiceNumbers)
+		again, err := NewSyncer().Sync(ctx, local, remote)
+		assert.NoError(t, err)
+		assert.Empty(t, again.DuplicateInvoiceNumbers, "duplicates should only be reported by the sync that merged them")
+
+		got, err = newInvoices(local, at(30, 18, 3)).Invoice(ctx, app.InvoiceRequest{Tag: "globex", Period: june})
+		assert.NoError(t, err)
+		assert.Equal(t, "2022-0003", got.Number)
+
+		// Importing is another way of merging stores.
+		other := newStore(t)
+		_, err = newInvoices(other, at(30, 18, 4)).Invoice(ctx, app.InvoiceRequest{Task: "acme-api", Period: june})
+		assert.NoError(t, err)
+		otherEvents, err := other.FetchAll(ctx)
+		assert.NoError(t, err)
+		imported, err := NewImporter(local, local).Import(ctx, otherEvents, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2022-0001"}, imported.DuplicateInvoiceNumbers)
+	})
+
+	t.Run("dry run doesn't record the invoice", func(t *testing.T) {
+		store := newStore(t)
+		sut := newInvoices(store, at(30, 18, 0))
+		_, err := sut.Invoice(ctx, app.InvoiceRequest{Task: "acme-*", Period: june, DryRun: true})
+		assert.NoError(t, err)
+
+		got, err := sut.Invoice(ctx, app.InvoiceRequest{Task: "acme-*", Period: june})
+		assert.NoError(t, err)
+		assert.Equal(t, "2022-0001", got.Number)
+	})
+
+	t.Run("time without a rate can't be invoiced", func(t *testing.T) {
+		store := newStore(t)
+		_, err := newInvoices(store, at(30, 18, 0)).Invoice(ctx, app.InvoiceRequest{Tag: "globex", Period: june})
+		assert.ErrorIs(t, err, app.ErrCannotInvoice)
+	})
+}
@@ -0,0 +1,47 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTaskLifecycle_MemoryEventStore(t *testing.T) {
+	ctx := context.Background()
+	store := eventstore.NewMemoryEventStore()
+	now := time.Now().Truncate(time.Second)
+	clock := func() time.Time { return now }
+
+	starter := Starter{eventStore: store, eventFinder: store, now: clock, newUUID: uuid.New}
+	finisher := Finisher{eventStore: store, eventFinder: store, now: clock, newUUID: uuid.New}
+	durations := NewDurations(store)
+
+	_, err := durations.FetchLastCompleted(ctx, "test")
+	assert.Truef(t, errors.Is(err, app.ErrTaskNeverCompleted), "want err [%s] got [%s]", app.ErrTaskNeverCompleted, err)
+
+	assert.NoError(t, starter.Start(ctx, "test"))
+	err = starter.Start(ctx, "test")
+	assert.Truef(t, errors.Is(err, app.ErrTaskAlreadyStarted), "want err [%s] got [%s]", app.ErrTaskAlreadyStarted, err)
+
+	now = now.Add(90 * time.Minute)
+	assert.NoError(t, finisher.Finish(ctx, "test"))
+	err = finisher.Finish(ctx, "test")
+	assert.Truef(t, errors.Is(err, app.ErrTaskNotStarted), "want err [%s] got [%s]", app.ErrTaskNotStarted, err)
+
+	completed, err := durations.FetchLastCompleted(ctx, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, 90*time.Minute, completed.Duration)
+	assert.Equal(t, "test", completed.Name)
+
+	backdated := now.Add(-30 * time.Minute)
+	err = starter.WithClock(func() time.Time { return backdated }).Start(ctx, "test")
+	assert.Truef(t, errors.Is(err, app.ErrEventOutOfOrder), "want err [%s] got [%s]", app.ErrEventOutOfOrder, err)
+
+	later := now.Add(30 * time.Minute)
+	assert.NoError(t, starter.WithClock(func() time.Time { return later }).Start(ctx, "test"))
+}
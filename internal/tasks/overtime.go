@@ -0,0 +1,99 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"time"
+)
+
+var _ app.OvertimeCalculator = (*OvertimeCalculator)(nil)
+
+type OvertimeCalculator struct {
+	sessionLister app.SessionLister
+	absenceLister app.AbsenceLister
+	contract      app.Contract
+	firstDay      time.Weekday
+}
+
+// NewOvertimeCalculator returns a calculator for the contract, with weeks starting on firstDay.
+func NewOvertimeCalculator(sessionLister app.SessionLister, absenceLister app.AbsenceLister, contract app.Contract, firstDay time.Weekday) OvertimeCalculator {
+	return OvertimeCalculator{sessionLister: sessionLister, absenceLister: absenceLister, contract: contract, firstDay: firstDay}
+}
+
+// Overtime compares the time worked in each week or month since the contract started with the contracted hours,
+// less any absences, keeping a running balance. Time spent on several tasks at once only counts once. Days and
+// periods are in at's location.
+func (c OvertimeCalculator) Overtime(ctx context.Context, at time.Time, by app.Granularity) (app.Overtime, error) {
+	if c.contract.IsZero() {
+		return app.Overtime{}, app.ErrNoContract
+	}
+	next, err := c.periods(by)
+	if err != nil {
+		return app.Overtime{}, err
+	}
+
+	overtime := app.Overtime{Contract: c.contract, At: at}
+	start := app.DayPeriod(c.contract.Start.In(at.Location()), 0).From
+	end := app.DayPeriod(at, 0).To
+	if !start.Before(end) {
+		return overtime, nil
+	}
+
+	sessions, err := c.sessionLister.Sessions(ctx, app.Period{From: start, To: end})
+	if err != nil {
+		return app.Overtime{}, fmt.Errorf("listing sessions: %w", err)
+	}
+	sessions = mergeOverlapping(sessions)
+	absences, err := c.absenceLister.Absences(ctx)
+	if err != nil {
+		return app.Overtime{}, fmt.Errorf("listing absences: %w", err)
+	}
+
+	for p := next(start, 0); p.From.Before(end); p = next(p.From, 1) {
+		counted := app.Period{From: laterOf(p.From, start), To: earlierOf(p.To, end)}
+		row := app.OvertimePeriod{Period: p}
+		for day := counted.From; day.Before(counted.To); day = day.AddDate(0, 0, 1) {
+			hours := c.contract.Expected(day)
+			excused := absences.Excused(day, hours)
+			row.Expected += hours - excused
+			row.Absent += excused
+		}
+		for _, s := range sessions {
+			if within, ok := s.Within(counted); ok {
+				row.Worked += within.Duration()
+			}
+		}
+		overtime.Balance += row.Difference()
+		row.Balance = overtime.Balance
+		overtime.Periods = append(overtime.Periods, row)
+	}
+
+	return overtime, nil
+}
+
+// periods returns a function for the week or month containing a time, moved by an offset.
+func (c OvertimeCalculator) periods(by app.Granularity) (func(t time.Time, offset int) app.Period, error) {
+	switch by {
+	case app.ByWeek:
+		return func(t time.Time, offset int) app.Period { return app.WeekPeriod(t, c.firstDay, offset) }, nil
+	case app.ByMonth:
+		return app.MonthPeriod, nil
+	default:
+		return nil, fmt.Errorf("unknown granularity [%s]", by)
+	}
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlierOf(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
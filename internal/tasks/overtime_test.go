@@ -0,0 +1,88 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestOvertimeCalculator_Overtime(t *testing.T) {
+	ctx := context.Background()
+	// 30 May 2022 is a Monday.
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2022, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		at        time.Time
+	}{
+		{app.EventTypeTaskStarted, at(5, 27, 9)},
+		{app.EventTypeTaskFinished, at(5, 27, 17)},
+		{app.EventTypeTaskStarted, at(5, 31, 9)},
+		{app.EventTypeTaskFinished, at(5, 31, 19)},
+		{app.EventTypeTaskStarted, at(6, 1, 9)},
+		{app.EventTypeTaskFinished, at(6, 1, 17)},
+		{app.EventTypeTaskStarted, at(6, 4, 10)},
+		{app.EventTypeTaskFinished, at(6, 4, 12)},
+		{app.EventTypeTaskStarted, at(6, 6, 9)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: "work", CreatedAt: e.at}))
+	}
+	// A meeting during work on 1 June doesn't add to the time worked.
+	assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: app.EventTypeTaskStarted, TaskName: "meeting", CreatedAt: at(6, 1, 10)}))
+	assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: app.EventTypeTaskFinished, TaskName: "meeting", CreatedAt: at(6, 1, 12)}))
+	absences := NewAbsences(store, store)
+	assert.NoError(t, absences.AddAbsences(ctx,
+		app.Absence{Date: at(6, 2, 0), Kind: app.Holiday},
+		app.Absence{Date: at(6, 3, 0), Kind: app.Vacation, Half: true},
+	))
+	now := at(6, 6, 12)
+	sessions := SessionCollector{eventLister: store, now: func() time.Time { return now }}
+	contract := app.Contract{Start: at(5, 31, 0)}
+	for day := time.Monday; day <= time.Friday; day++ {
+		contract.Hours[day] = 8 * time.Hour
+	}
+
+	t.Run("by week", func(t *testing.T) {
+		got, err := NewOvertimeCalculator(sessions, absences, contract, time.Monday).Overtime(ctx, now, app.ByWeek)
+		assert.NoError(t, err)
+		want := []app.OvertimePeriod{
+			// Time before the contract started doesn't count, and neither do the holiday and the half day of vacation.
+			{Period: app.WeekPeriod(at(5, 30, 0), time.Monday, 0), Expected: 20 * time.Hour, Absent: 12 * time.Hour, Worked: 20 * time.Hour, Balance: 0},
+			// Only today counts towards the current week.
+			{Period: app.WeekPeriod(at(6, 6, 0), time.Monday, 0), Expected: 8 * time.Hour, Worked: 3 * time.Hour, Balance: -5 * time.Hour},
+		}
+		assert.Equal(t, want, got.Periods)
+		assert.Equal(t, -5*time.Hour, got.Balance)
+	})
+
+	t.Run("by month", func(t *testing.T) {
+		got, err := NewOvertimeCalculator(sessions, absences, contract, time.Monday).Overtime(ctx, now, app.ByMonth)
+		assert.NoError(t, err)
+		if assert.Len(t, got.Periods, 2) {
+			assert.Equal(t, 8*time.Hour, got.Periods[0].Expected)
+			assert.Equal(t, 10*time.Hour, got.Periods[0].Worked)
+			assert.Equal(t, 2*time.Hour, got.Periods[0].Balance)
+			assert.Equal(t, 20*time.Hour, got.Periods[1].Expected)
+			assert.Equal(t, 12*time.Hour, got.Periods[1].Absent)
+			assert.Equal(t, -5*time.Hour, got.Periods[1].Balance)
+		}
+	})
+
+	t.Run("before the contract starts", func(t *testing.T) {
+		got, err := NewOvertimeCalculator(sessions, absences, contract, time.Monday).Overtime(ctx, at(5, 27, 12), app.ByWeek)
+		assert.NoError(t, err)
+		assert.Empty(t, got.Periods)
+		assert.Equal(t, time.Duration(0), got.Balance)
+	})
+
+	t.Run("without a contract", func(t *testing.T) {
+		_, err := NewOvertimeCalculator(sessions, absences, app.Contract{}, time.Monday).Overtime(ctx, now, app.ByWeek)
+		assert.ErrorIs(t, err, app.ErrNoContract)
+	})
+}
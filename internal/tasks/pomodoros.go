@@ -0,0 +1,187 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"time"
+)
+
+var _ app.PomodoroTimer = (*Pomodoros)(nil)
+
+// The key and values of the data in task events recorded by pomodoros.
+const (
+	pomodoroKey         = "pomodoro"
+	pomodoroFocus       = "focus"
+	pomodoroCompleted   = "completed"
+	pomodoroInterrupted = "interrupted"
+)
+
+// Pomodoros keeps pomodoros as task events: a focus is a session, marked in the data of its events, and a break is
+// the pause between sessions.
+type Pomodoros struct {
+	eventStore  app.EventStore
+	eventFinder app.EventFinder
+	eventLister app.EventLister
+	settings    app.PomodoroSettings
+	now         func() time.Time
+	newUUID     func() uuid.UUID
+}
+
+func NewPomodoros(eventStore app.EventStore, eventFinder app.EventFinder, eventLister app.EventLister, settings app.PomodoroSettings) Pomodoros {
+	return Pomodoros{
+		eventStore:  eventStore,
+		eventFinder: eventFinder,
+		eventLister: eventLister,
+		settings:    settings,
+		now:         time.Now,
+		newUUID:     uuid.New,
+	}
+}
+
+// WithClock returns a copy which uses the time returned by now, whose location days are counted in.
+func (p Pomodoros) WithClock(now func() time.Time) Pomodoros {
+	p.now = now
+	return p
+}
+
+func (p Pomodoros) Start(ctx context.Context, taskName string, tags ...string) (app.Pomodoro, error) {
+	latest, found, err := p.latest(ctx, taskName)
+	if err != nil {
+		return app.Pomodoro{}, err
+	}
+	if found && latest.Type == app.EventTypeTaskStarted && latest.Data[pomodoroKey] != pomodoroFocus {
+		return app.Pomodoro{}, fmt.Errorf("task started event found: %w", app.ErrTaskAlreadyStarted)
+	}
+
+	pomodoro, err := p.Advance(ctx, taskName, tags...)
+	if err != nil || pomodoro.Phase != app.PomodoroStopped {
+		return pomodoro, err
+	}
+	now := p.now()
+	if found && now.Before(latest.CreatedAt) {
+		return app.Pomodoro{}, fmt.Errorf("task would start before its latest event at %s: %w", latest.CreatedAt, app.ErrEventOutOfOrder)
+	}
+	return p.focus(ctx, taskName, tags, now)
+}
+
+func (p Pomodoros) Advance(ctx context.Context, taskName string, tags ...string) (app.Pomodoro, error) {
+	latest, found, err := p.latest(ctx, taskName)
+	if err != nil {
+		return app.Pomodoro{}, err
+	}
+	now := p.now()
+
+	if found && latest.Type == app.EventTypeTaskStarted && latest.Data[pomodoroKey] == pomodoroFocus {
+		ends := latest.CreatedAt.Add(p.settings.Focus)
+		if now.Before(ends) {
+			completed, err := p.completed(ctx, now)
+			if err != nil {
+				return app.Pomodoro{}, err
+			}
+			return app.Pomodoro{TaskName: taskName, Phase: app.PomodoroFocus, Started: latest.CreatedAt, Ends: ends, Completed: completed}, nil
+		}
+		// The focus is finished when its time was up, even if nothing was running to finish it then.
+		if latest, err = p.store(ctx, app.EventTypeTaskFinished, taskName, nil, ends, pomodoroCompleted); err != nil {
+			return app.Pomodoro{}, err
+		}
+	}
+
+	if !found || latest.Type != app.EventTypeTaskFinished || latest.Data[pomodoroKey] != pomodoroCompleted {
+		return p.stopped(ctx, taskName, now)
+	}
+	completed, err := p.completed(ctx, latest.CreatedAt.In(now.Location()))
+	if err != nil {
+		return app.Pomodoro{}, err
+	}
+	if ends := latest.CreatedAt.Add(p.settings.BreakAfter(completed)); now.Before(ends) {
+		return app.Pomodoro{TaskName: taskName, Phase: app.PomodoroBreak, Started: latest.CreatedAt, Ends: ends, Completed: completed}, nil
+	}
+	return p.focus(ctx, taskName, tags, now)
+}
+
+func (p Pomodoros) Stop(ctx context.Context, taskName string) (app.Pomodoro, error) {
+	latest, found, err := p.latest(ctx, taskName)
+	if err != nil {
+		return app.Pomodoro{}, err
+	}
+	now := p.now()
+
+	if found && latest.Type == app.EventTypeTaskStarted && latest.Data[pomodoroKey] == pomodoroFocus {
+		at, result := now, pomodoroInterrupted
+		if ends := latest.CreatedAt.Add(p.settings.Focus); !now.Before(ends) {
+			at, result = ends, pomodoroCompleted
+		}
+		if _, err := p.store(ctx, app.EventTypeTaskFinished, taskName, nil, at, result); err != nil {
+			return app.Pomodoro{}, err
+		}
+	}
+
+	return p.stopped(ctx, taskName, now)
+}
+
+// focus starts a focus on the task at a time.
+func (p Pomodoros) focus(ctx context.Context, taskName string, tags []string, at time.Time) (app.Pomodoro, error) {
+	if _, err := p.store(ctx, app.EventTypeTaskStarted, taskName, normaliseTags(tags), at, pomodoroFocus); err != nil {
+		return app.Pomodoro{}, err
+	}
+	completed, err := p.completed(ctx, at)
+	if err != nil {
+		return app.Pomodoro{}, err
+	}
+	return app.Pomodoro{TaskName: taskName, Phase: app.PomodoroFocus, Started: at, Ends: at.Add(p.settings.Focus), Completed: completed}, nil
+}
+
+func (p Pomodoros) stopped(ctx context.Context, taskName string, at time.Time) (app.Pomodoro, error) {
+	completed, err := p.completed(ctx, at)
+	if err != nil {
+		return app.Pomodoro{}, err
+	}
+	return app.Pomodoro{TaskName: taskName, Phase: app.PomodoroStopped, Completed: completed}, nil
+}
+
+// latest returns the latest event for the task. The second return value is false if there isn't one.
+func (p Pomodoros) latest(ctx context.Context, taskName string) (app.Event, bool, error) {
+	latest, err := p.eventFinder.LatestByName(ctx, taskName)
+	switch {
+	case errors.Is(err, app.ErrEventNotFound):
+		return app.Event{}, false, nil
+	case err != nil:
+		return app.Event{}, false, fmt.Errorf("finding latest event: %w", err)
+	}
+	return latest, true, nil
+}
+
+// completed counts the pomodoros completed on any task on the day containing at.
+func (p Pomodoros) completed(ctx context.Context, at time.Time) (int, error) {
+	events, err := p.eventLister.FetchAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("fetching events: %w", err)
+	}
+	day := app.DayPeriod(at, 0)
+	var completed int
+	for _, e := range events {
+		if e.Type == app.EventTypeTaskFinished && e.Data[pomodoroKey] == pomodoroCompleted &&
+			!e.CreatedAt.Before(day.From) && e.CreatedAt.Before(day.To) {
+			completed++
+		}
+	}
+	return completed, nil
+}
+
+func (p Pomodoros) store(ctx context.Context, eventType app.EventType, taskName string, tags []string, at time.Time, phase string) (app.Event, error) {
+	e := app.Event{
+		ID:        p.newUUID(),
+		Type:      eventType,
+		TaskName:  taskName,
+		CreatedAt: at,
+		Tags:      tags,
+		Data:      map[string]string{pomodoroKey: phase},
+	}
+	if err := p.eventStore.Store(ctx, e); err != nil {
+		return app.Event{}, fmt.Errorf("storing event: %w", err)
+	}
+	return e, nil
+}
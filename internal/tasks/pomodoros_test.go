@@ -0,0 +1,81 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPomodoros(t *testing.T) {
+	ctx := context.Background()
+	at := func(hour, minute int) time.Time {
+		return time.Date(2022, 6, 6, hour, minute, 0, 0, time.UTC)
+	}
+	settings := app.PomodoroSettings{Focus: 25 * time.Minute, Break: 5 * time.Minute, LongBreak: 15 * time.Minute, LongBreakEvery: 2}
+	store := eventstore.NewMemoryEventStore()
+	var now time.Time
+	sut := NewPomodoros(store, store, store, settings).WithClock(func() time.Time { return now })
+
+	now = at(9, 0)
+	got, err := sut.Start(ctx, "acme-web", "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, app.Pomodoro{TaskName: "acme-web", Phase: app.PomodoroFocus, Started: at(9, 0), Ends: at(9, 25)}, got)
+
+	// Starting again, e.g. after the process was killed, resumes the focus.
+	now = at(9, 10)
+	got, err = sut.Start(ctx, "acme-web", "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, app.Pomodoro{TaskName: "acme-web", Phase: app.PomodoroFocus, Started: at(9, 0), Ends: at(9, 25)}, got)
+
+	// The focus finishes when its time was up, even if it's advanced later.
+	now = at(9, 27)
+	got, err = sut.Advance(ctx, "acme-web", "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, app.Pomodoro{TaskName: "acme-web", Phase: app.PomodoroBreak, Started: at(9, 25), Ends: at(9, 30), Completed: 1}, got)
+
+	now = at(9, 31)
+	got, err = sut.Advance(ctx, "acme-web", "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, app.Pomodoro{TaskName: "acme-web", Phase: app.PomodoroFocus, Started: at(9, 31), Ends: at(9, 56), Completed: 1}, got)
+
+	// Every second pomodoro is followed by a long break.
+	now = at(9, 56)
+	got, err = sut.Advance(ctx, "acme-web", "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, app.Pomodoro{TaskName: "acme-web", Phase: app.PomodoroBreak, Started: at(9, 56), Ends: at(10, 11), Completed: 2}, got)
+
+	now = at(10, 15)
+	_, err = sut.Advance(ctx, "acme-web", "acme")
+	assert.NoError(t, err)
+	now = at(10, 20)
+	got, err = sut.Stop(ctx, "acme-web")
+	assert.NoError(t, err)
+	assert.Equal(t, app.Pomodoro{TaskName: "acme-web", Phase: app.PomodoroStopped, Completed: 2}, got, "an interrupted focus shouldn't count")
+
+	// Focus intervals are sessions, with breaks as the pauses between them.
+	sessions, err := NewSessionCollector(store).Sessions(ctx, app.DayPeriod(now, 0))
+	assert.NoError(t, err)
+	if assert.Len(t, sessions, 3) {
+		assert.Equal(t, []string{"acme"}, sessions[2].Tags)
+		assert.Equal(t, 25*time.Minute, sessions[0].Duration())
+		assert.Equal(t, 5*time.Minute, sessions[2].Duration())
+	}
+
+	// Once stopped, it stays stopped until it's started again.
+	got, err = sut.Advance(ctx, "acme-web", "acme")
+	assert.NoError(t, err)
+	assert.Equal(t, app.PomodoroStopped, got.Phase)
+}
+
+func TestPomodoros_Start_TaskAlreadyStarted(t *testing.T) {
+	ctx := context.Background()
+	store := eventstore.NewMemoryEventStore()
+	assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: app.EventTypeTaskStarted, TaskName: "acme-web", CreatedAt: time.Now().Add(-time.Hour)}))
+
+	_, err := NewPomodoros(store, store, store, app.PomodoroSettings{Focus: 25 * time.Minute, Break: 5 * time.Minute}).Start(ctx, "acme-web")
+	assert.ErrorIs(t, err, app.ErrTaskAlreadyStarted)
+}
@@ -0,0 +1,139 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+var (
+	_ app.RateSetter = (*Rates)(nil)
+	_ app.RateLister = (*Rates)(nil)
+)
+
+// The keys of the data in rate set events.
+const (
+	rateTask          = "task"
+	rateTag           = "tag"
+	rateAmount        = "amount"
+	rateCurrency      = "currency"
+	rateEffectiveFrom = "effective_from"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Rates keeps a registry of rates as events, so that rates are synced, exported and verified along with the time they
+// are charged for.
+type Rates struct {
+	eventStore  app.EventStore
+	eventLister app.EventLister
+	now         func() time.Time
+	newUUID     func() uuid.UUID
+}
+
+func NewRates(eventStore app.EventStore, eventLister app.EventLister) Rates {
+	return Rates{eventStore: eventStore, eventLister: eventLister, now: time.Now, newUUID: uuid.New}
+}
+
+// SetRate records a rate for a task, a task name prefix or a tag. Currencies are ISO 4217 codes, such as EUR.
+func (r Rates) SetRate(ctx context.Context, rate app.Rate) error {
+	switch {
+	case (rate.Task == "") == (rate.Tag == ""):
+		return fmt.Errorf("a rate must be for either a task or a tag: %w", app.ErrInvalidRate)
+	case rate.Amount < 0 || math.IsNaN(rate.Amount) || math.IsInf(rate.Amount, 0):
+		return fmt.Errorf("amount [%v] must not be negative: %w", rate.Amount, app.ErrInvalidRate)
+	case !currencyCode.MatchString(rate.Currency):
+		return fmt.Errorf("currency [%s] must be a three letter code such as EUR: %w", rate.Currency, app.ErrInvalidRate)
+	}
+
+	data := map[string]string{
+		rateAmount:   strconv.FormatFloat(rate.Amount, 'f', -1, 64),
+		rateCurrency: rate.Currency,
+	}
+	if rate.Task != "" {
+		data[rateTask] = rate.Task
+	}
+	if rate.Tag != "" {
+		data[rateTag] = rate.Tag
+	}
+	if !rate.EffectiveFrom.IsZero() {
+		data[rateEffectiveFrom] = rate.EffectiveFrom.Format(time.RFC3339Nano)
+	}
+
+	if err := r.eventStore.Store(ctx, app.Event{
+		ID:        r.newUUID(),
+		Type:      app.EventTypeRateSet,
+		CreatedAt: r.now(),
+		Data:      data,
+	}); err != nil {
+		return fmt.Errorf("storing event: %w", err)
+	}
+
+	return nil
+}
+
+// Rates returns every rate which hasn't been replaced by a later one for the same task or tag from the same time,
+// sorted by task, tag and the time they take effect.
+func (r Rates) Rates(ctx context.Context) (app.Rates, error) {
+	events, err := r.eventLister.FetchAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching events: %w", err)
+	}
+
+	type target struct {
+		task, tag, effectiveFrom string
+	}
+	latest := map[target]app.Rate{}
+	for _, e := range events {
+		if e.Type != app.EventTypeRateSet {
+			continue
+		}
+		rate, err := parseRate(e)
+		if err != nil {
+			return nil, err
+		}
+		key := target{task: rate.Task, tag: rate.Tag, effectiveFrom: rate.EffectiveFrom.UTC().Format(time.RFC3339Nano)}
+		if existing, ok := latest[key]; !ok || rate.SetAt.After(existing.SetAt) {
+			latest[key] = rate
+		}
+	}
+
+	var rates app.Rates
+	for _, rate := range latest {
+		rates = append(rates, rate)
+	}
+	sort.Slice(rates, func(i, j int) bool {
+		switch {
+		case rates[i].Task != rates[j].Task:
+			return rates[i].Task < rates[j].Task
+		case rates[i].Tag != rates[j].Tag:
+			return rates[i].Tag < rates[j].Tag
+		default:
+			return rates[i].EffectiveFrom.Before(rates[j].EffectiveFrom)
+		}
+	})
+
+	return rates, nil
+}
+
+func parseRate(e app.Event) (app.Rate, error) {
+	rate := app.Rate{Task: e.Data[rateTask], Tag: e.Data[rateTag], Currency: e.Data[rateCurrency], SetAt: e.CreatedAt}
+
+	var err error
+	if rate.Amount, err = strconv.ParseFloat(e.Data[rateAmount], 64); err != nil {
+		return app.Rate{}, fmt.Errorf("parsing amount of rate [%s]: %w", e.ID, err)
+	}
+	if from := e.Data[rateEffectiveFrom]; from != "" {
+		if rate.EffectiveFrom, err = time.Parse(time.RFC3339Nano, from); err != nil {
+			return app.Rate{}, fmt.Errorf("parsing effective date of rate [%s]: %w", e.ID, err)
+		}
+	}
+
+	return rate, nil
+}
@@ -0,0 +1,72 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRates_SetRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rate    app.Rate
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{name: "task", rate: app.Rate{Task: "acme-web", Amount: 120, Currency: "EUR"}, wantErr: assert.NoError},
+		{name: "prefix", rate: app.Rate{Task: "acme-*", Amount: 99.5, Currency: "GBP"}, wantErr: assert.NoError},
+		{name: "tag", rate: app.Rate{Tag: "acme", Amount: 0, Currency: "USD"}, wantErr: assert.NoError},
+		{name: "neither task nor tag", rate: app.Rate{Amount: 120, Currency: "EUR"}, wantErr: assert.Error},
+		{name: "both task and tag", rate: app.Rate{Task: "web", Tag: "acme", Amount: 120, Currency: "EUR"}, wantErr: assert.Error},
+		{name: "negative amount", rate: app.Rate{Task: "web", Amount: -1, Currency: "EUR"}, wantErr: assert.Error},
+		{name: "unknown currency", rate: app.Rate{Task: "web", Amount: 120, Currency: "euro"}, wantErr: assert.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := eventstore.NewMemoryEventStore()
+			sut := NewRates(store, store)
+			err := sut.SetRate(context.Background(), tt.rate)
+			if !tt.wantErr(t, err) || err != nil {
+				assert.ErrorIs(t, err, app.ErrInvalidRate)
+				return
+			}
+
+			events, err := store.FetchAll(context.Background())
+			assert.NoError(t, err)
+			if assert.Len(t, events, 1) {
+				assert.Equal(t, app.EventTypeRateSet, events[0].Type)
+				assert.Empty(t, events[0].TaskName, "rates shouldn't be found as tasks")
+			}
+		})
+	}
+}
+
+func TestRates_Rates(t *testing.T) {
+	ctx := context.Background()
+	store := eventstore.NewMemoryEventStore()
+	at := time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC)
+	sut := NewRates(store, store)
+	sut.now = func() time.Time {
+		at = at.Add(time.Minute)
+		return at
+	}
+	july := time.Date(2022, 7, 1, 0, 0, 0, 0, time.FixedZone("", 3600))
+
+	assert.NoError(t, sut.SetRate(ctx, app.Rate{Task: "web", Amount: 100, Currency: "EUR"}))
+	assert.NoError(t, sut.SetRate(ctx, app.Rate{Tag: "acme", Amount: 90, Currency: "USD"}))
+	assert.NoError(t, sut.SetRate(ctx, app.Rate{Task: "web", Amount: 110, Currency: "EUR"}))
+	assert.NoError(t, sut.SetRate(ctx, app.Rate{Task: "web", Amount: 120, Currency: "EUR", EffectiveFrom: july}))
+	assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: app.EventTypeTaskStarted, TaskName: "web", CreatedAt: at}))
+
+	got, err := sut.Rates(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, got, 3) {
+		assert.Equal(t, "acme", got[0].Tag)
+		assert.Equal(t, 110.0, got[1].Amount, "a rate from the same time should replace the earlier one")
+		assert.Equal(t, 120.0, got[2].Amount)
+		assert.True(t, july.Equal(got[2].EffectiveFrom))
+	}
+}
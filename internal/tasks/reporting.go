@@ -0,0 +1,102 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"sort"
+	"time"
+)
+
+var _ app.Reporter = (*Reporter)(nil)
+
+type Reporter struct {
+	sessionLister app.SessionLister
+	rounding      app.RoundingRules
+}
+
+func NewReporter(sessionLister app.SessionLister, rounding app.RoundingRules) Reporter {
+	return Reporter{sessionLister: sessionLister, rounding: rounding}
+}
+
+// Report totals the completed sessions in the period by task and by day, before and after rounding. Days are in the
+// location of the period, and sessions which cross midnight count towards both days.
+func (r Reporter) Report(ctx context.Context, period app.Period) (app.Report, error) {
+	sessions, err := r.sessionLister.Sessions(ctx, period)
+	if err != nil {
+		return app.Report{}, fmt.Errorf("listing sessions: %w", err)
+	}
+
+	report := app.Report{Period: period}
+	tasks := map[string]*app.TaskTotal{}
+	days := map[time.Time]*app.DayTotal{}
+	rounder := newRounder(r.rounding)
+	for _, s := range sessions {
+		if s.InProgress {
+			continue
+		}
+		s, _ = s.Within(period)
+
+		task, ok := tasks[s.TaskName]
+		if !ok {
+			task = &app.TaskTotal{TaskName: s.TaskName}
+			tasks[s.TaskName] = task
+		}
+		task.Sessions++
+		task.Duration += s.Duration()
+
+		for _, part := range splitByDay(s, period.From.Location()) {
+			date := app.DayPeriod(part.Started.In(period.From.Location()), 0).From
+			day, ok := days[date]
+			if !ok {
+				day = &app.DayTotal{Date: date, FirstStarted: part.Started, LastFinished: part.Finished}
+				days[date] = day
+			}
+			day.Sessions++
+			day.Duration += part.Duration()
+			if part.Started.Before(day.FirstStarted) {
+				day.FirstStarted = part.Started
+			}
+			if part.Finished.After(day.LastFinished) {
+				day.LastFinished = part.Finished
+			}
+			rounder.add(date, part)
+		}
+
+		report.Sessions++
+		report.Total += s.Duration()
+	}
+
+	for key, rounded := range rounder.totals() {
+		tasks[key.taskName].Rounded += rounded
+		days[key.date].Rounded += rounded
+		report.RoundedTotal += rounded
+	}
+
+	for _, task := range tasks {
+		report.Tasks = append(report.Tasks, *task)
+	}
+	sort.Slice(report.Tasks, func(i, j int) bool {
+		return report.Tasks[i].TaskName < report.Tasks[j].TaskName
+	})
+	for _, day := range days {
+		report.Days = append(report.Days, *day)
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].Date.Before(report.Days[j].Date)
+	})
+
+	return report, nil
+}
+
+// splitByDay splits a session at each midnight in the location. Days are calendar days, so they are 23 or 25 hours
+// long when daylight saving time starts or ends.
+func splitByDay(s app.Session, location *time.Location) []app.Session {
+	var parts []app.Session
+	for day := app.DayPeriod(s.Started.In(location), 0); day.From.Before(s.Finished); day = app.DayPeriod(day.To, 0) {
+		if part, ok := s.Within(day); ok {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -0,0 +1,138 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestReporter_Report(t *testing.T) {
+	ctx := context.Background()
+	monday := time.Date(2022, 6, 6, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour, minute int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		at        time.Time
+	}{
+		{app.EventTypeTaskStarted, "before", at(-1, 23, 0)},
+		{app.EventTypeTaskFinished, "before", at(0, 1, 0)},
+		{app.EventTypeTaskStarted, "a", at(0, 9, 0)},
+		{app.EventTypeTaskFinished, "a", at(0, 10, 30)},
+		{app.EventTypeTaskStarted, "b", at(0, 11, 0)},
+		{app.EventTypeTaskFinished, "b", at(0, 17, 0)},
+		{app.EventTypeTaskStarted, "a", at(2, 8, 0)},
+		{app.EventTypeTaskFinished, "a", at(2, 9, 0)},
+		{app.EventTypeTaskStarted, "running", at(2, 10, 0)},
+		{app.EventTypeTaskStarted, "late", at(3, 23, 0)},
+		{app.EventTypeTaskFinished, "late", at(4, 1, 30)},
+		{app.EventTypeTaskStarted, "next-week", at(7, 9, 0)},
+		{app.EventTypeTaskFinished, "next-week", at(7, 10, 0)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, CreatedAt: e.at}))
+	}
+
+	week := app.WeekPeriod(at(2, 12, 0), time.Monday, 0)
+	sut := NewReporter(SessionCollector{eventLister: store, now: func() time.Time { return at(2, 12, 0) }}, nil)
+	got, err := sut.Report(ctx, week)
+	assert.NoError(t, err)
+
+	assert.Equal(t, app.Report{
+		Period: week,
+		Tasks: []app.TaskTotal{
+			{TaskName: "a", Sessions: 2, Duration: 150 * time.Minute, Rounded: 150 * time.Minute},
+			{TaskName: "b", Sessions: 1, Duration: 6 * time.Hour, Rounded: 6 * time.Hour},
+			{TaskName: "before", Sessions: 1, Duration: time.Hour, Rounded: time.Hour},
+			{TaskName: "late", Sessions: 1, Duration: 150 * time.Minute, Rounded: 150 * time.Minute},
+		},
+		Days: []app.DayTotal{
+			{Date: at(0, 0, 0), Sessions: 3, FirstStarted: at(0, 0, 0), LastFinished: at(0, 17, 0), Duration: 8*time.Hour + 30*time.Minute, Rounded: 8*time.Hour + 30*time.Minute},
+			{Date: at(2, 0, 0), Sessions: 1, FirstStarted: at(2, 8, 0), LastFinished: at(2, 9, 0), Duration: time.Hour, Rounded: time.Hour},
+			{Date: at(3, 0, 0), Sessions: 1, FirstStarted: at(3, 23, 0), LastFinished: at(4, 0, 0), Duration: time.Hour, Rounded: time.Hour},
+			{Date: at(4, 0, 0), Sessions: 1, FirstStarted: at(4, 0, 0), LastFinished: at(4, 1, 30), Duration: 90 * time.Minute, Rounded: 90 * time.Minute},
+		},
+		Sessions:     5,
+		Total:        12 * time.Hour,
+		RoundedTotal: 12 * time.Hour,
+	}, got)
+}
+
+func TestReporter_Report_Rounding(t *testing.T) {
+	ctx := context.Background()
+	at := func(hour, minute int) time.Time {
+		return time.Date(2022, 6, 6, hour, minute, 0, 0, time.UTC)
+	}
+
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		tags      []string
+		at        time.Time
+	}{
+		{app.EventTypeTaskStarted, "acme-web", nil, at(9, 0)},
+		{app.EventTypeTaskFinished, "acme-web", nil, at(9, 10)},
+		{app.EventTypeTaskStarted, "acme-web", nil, at(10, 0)},
+		{app.EventTypeTaskFinished, "acme-web", nil, at(10, 10)},
+		{app.EventTypeTaskStarted, "support", []string{"billable"}, at(11, 0)},
+		{app.EventTypeTaskFinished, "support", nil, at(11, 10)},
+		{app.EventTypeTaskStarted, "support", []string{"billable"}, at(12, 0)},
+		{app.EventTypeTaskFinished, "support", nil, at(12, 10)},
+		{app.EventTypeTaskStarted, "admin", nil, at(13, 0)},
+		{app.EventTypeTaskFinished, "admin", nil, at(13, 10)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, Tags: e.tags, CreatedAt: e.at}))
+	}
+
+	rules := app.RoundingRules{
+		{Task: "acme-*", Mode: app.RoundUp, Increment: 15 * time.Minute, Per: app.PerDay},
+		{Tag: "billable", Mode: app.RoundUp, Increment: 15 * time.Minute, Per: app.PerSession},
+	}
+	day := app.DayPeriod(at(0, 0), 0)
+	sut := NewReporter(SessionCollector{eventLister: store, now: func() time.Time { return at(18, 0) }}, rules)
+	got, err := sut.Report(ctx, day)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []app.TaskTotal{
+		{TaskName: "acme-web", Sessions: 2, Duration: 20 * time.Minute, Rounded: 30 * time.Minute},
+		{TaskName: "admin", Sessions: 1, Duration: 10 * time.Minute, Rounded: 10 * time.Minute},
+		{TaskName: "support", Sessions: 2, Duration: 20 * time.Minute, Rounded: 30 * time.Minute},
+	}, got.Tasks)
+	if assert.Len(t, got.Days, 1) {
+		assert.Equal(t, 50*time.Minute, got.Days[0].Duration)
+		assert.Equal(t, 70*time.Minute, got.Days[0].Rounded)
+	}
+	assert.Equal(t, 50*time.Minute, got.Total)
+	assert.Equal(t, 70*time.Minute, got.RoundedTotal)
+}
+
+func TestSplitByDay(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %s", err)
+	}
+	at := func(day, hour int) time.Time {
+		return time.Date(2022, 3, day, hour, 0, 0, 0, london)
+	}
+
+	// The clocks went forward at 01:00 on 27 March 2022, so that day was 23 hours long.
+	got := splitByDay(app.Session{TaskName: "a", Started: at(26, 22), Finished: at(28, 2)}, london)
+	assert.Equal(t, []app.Session{
+		{TaskName: "a", Started: at(26, 22), Finished: at(27, 0)},
+		{TaskName: "a", Started: at(27, 0), Finished: at(28, 0)},
+		{TaskName: "a", Started: at(28, 0), Finished: at(28, 2)},
+	}, got)
+	assert.Equal(t, 23*time.Hour, got[1].Duration())
+
+	// Midnight in London is 23:00 the day before in UTC.
+	got = splitByDay(app.Session{TaskName: "a", Started: at(28, 0).UTC().Add(-time.Hour), Finished: at(28, 2).UTC()}, london)
+	assert.Len(t, got, 2)
+}
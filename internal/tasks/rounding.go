@@ -0,0 +1,56 @@
+package tasks
+
+import (
+	"github.com/danmurf/time-tracker/internal/app"
+	"time"
+)
+
+// taskDay identifies the time spent on a task on a day.
+type taskDay struct {
+	taskName string
+	date     time.Time
+}
+
+// rounder applies rounding rules to the time spent on each task each day. Sessions which cross midnight are rounded
+// separately on each day.
+type rounder struct {
+	rules   app.RoundingRules
+	rounded map[taskDay]time.Duration
+	// daily is the time spent on each task each day under a rule which rounds daily totals, rounded by totals.
+	daily map[dailyTotal]time.Duration
+}
+
+type dailyTotal struct {
+	taskDay
+	rule app.RoundingRule
+}
+
+func newRounder(rules app.RoundingRules) *rounder {
+	return &rounder{rules: rules, rounded: map[taskDay]time.Duration{}, daily: map[dailyTotal]time.Duration{}}
+}
+
+// add counts the part of a session on the day starting at date.
+func (r *rounder) add(date time.Time, part app.Session) {
+	key := taskDay{taskName: part.TaskName, date: date}
+	rule, ok := r.rules.For(part.TaskName, part.Tags)
+	switch {
+	case !ok:
+		r.rounded[key] += part.Duration()
+	case rule.Per == app.PerDay:
+		r.daily[dailyTotal{taskDay: key, rule: rule}] += part.Duration()
+	default:
+		r.rounded[key] += rule.Round(part.Duration())
+	}
+}
+
+// totals returns the rounded time spent on each task each day.
+func (r *rounder) totals() map[taskDay]time.Duration {
+	totals := map[taskDay]time.Duration{}
+	for key, d := range r.rounded {
+		totals[key] += d
+	}
+	for key, d := range r.daily {
+		totals[key.taskDay] += key.rule.Round(d)
+	}
+	return totals
+}
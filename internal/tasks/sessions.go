@@ -0,0 +1,107 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"sort"
+	"time"
+)
+
+var _ app.SessionLister = (*SessionCollector)(nil)
+
+// SessionCollector pairs every task started event with the task finished event which follows it, to find each
+// session of work on a task.
+type SessionCollector struct {
+	eventLister app.EventLister
+	now         func() time.Time
+}
+
+func NewSessionCollector(eventLister app.EventLister) SessionCollector {
+	return SessionCollector{eventLister: eventLister, now: time.Now}
+}
+
+func (c SessionCollector) Sessions(ctx context.Context, period app.Period) ([]app.Session, error) {
+	events, err := c.eventLister.FetchAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching events: %w", err)
+	}
+
+	var sessions []app.Session
+	for _, s := range pairSessions(events, c.now()) {
+		if _, ok := s.Within(period); ok {
+			sessions = append(sessions, s)
+		}
+	}
+
+	return sessions, nil
+}
+
+// pairSessions pairs started and finished events for each task, oldest first. A task started again while it is
+// already in progress, which can happen after syncing, continues the session already in progress. A finish without a
+// start is ignored. Sessions still in progress finish now.
+func pairSessions(events []app.Event, now time.Time) []app.Session {
+	events = append([]app.Event(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+
+	var sessions []app.Session
+	inProgress := map[string]app.Event{}
+	for _, e := range events {
+		started, ok := inProgress[e.TaskName]
+		switch {
+		case e.Type == app.EventTypeTaskStarted && !ok:
+			inProgress[e.TaskName] = e
+		case e.Type == app.EventTypeTaskFinished && ok:
+			sessions = append(sessions, app.Session{
+				ID:       started.ID,
+				TaskName: e.TaskName,
+				Tags:     started.Tags,
+				Started:  started.CreatedAt,
+				Finished: e.CreatedAt,
+			})
+			delete(inProgress, e.TaskName)
+		}
+	}
+	for _, started := range inProgress {
+		sessions = append(sessions, app.Session{
+			ID:         started.ID,
+			TaskName:   started.TaskName,
+			Tags:       started.Tags,
+			Started:    started.CreatedAt,
+			Finished:   now,
+			InProgress: true,
+		})
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		if sessions[i].Started.Equal(sessions[j].Started) {
+			return sessions[i].TaskName < sessions[j].TaskName
+		}
+		return sessions[i].Started.Before(sessions[j].Started)
+	})
+
+	return sessions
+}
+
+// mergeOverlapping merges sessions which overlap, such as two tasks being worked on at once, so that the time they
+// share is only counted once. The merged sessions are oldest first, and only keep their start and finish.
+func mergeOverlapping(sessions []app.Session) []app.Session {
+	sorted := append([]app.Session(nil), sessions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Started.Before(sorted[j].Started)
+	})
+
+	var merged []app.Session
+	for _, s := range sorted {
+		if last := len(merged) - 1; last >= 0 && !s.Started.After(merged[last].Finished) {
+			if s.Finished.After(merged[last].Finished) {
+				merged[last].Finished = s.Finished
+			}
+			continue
+		}
+		merged = append(merged, app.Session{Started: s.Started, Finished: s.Finished})
+	}
+	return merged
+}
@@ -0,0 +1,169 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSessionCollector_Sessions(t *testing.T) {
+	day := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+	// id is the ID of the event for the task at the time, so that sessions can be matched to their started events.
+	id := func(taskName string, createdAt time.Time) uuid.UUID {
+		return uuid.NewSHA1(uuid.NameSpaceOID, []byte(taskName+createdAt.String()))
+	}
+	event := func(eventType app.EventType, taskName string, createdAt time.Time) app.Event {
+		return app.Event{ID: id(taskName, createdAt), Type: eventType, TaskName: taskName, CreatedAt: createdAt}
+	}
+	now := at(18, 0)
+	wholeDay := app.DayPeriod(day, 0)
+
+	tests := []struct {
+		name   string
+		events []app.Event
+		period app.Period
+		want   []app.Session
+	}{
+		{
+			name:   "no events",
+			period: wholeDay,
+			want:   nil,
+		},
+		{
+			name: "sessions are paired by task, oldest first",
+			events: []app.Event{
+				event(app.EventTypeTaskStarted, "a", at(9, 0)),
+				event(app.EventTypeTaskStarted, "b", at(9, 30)),
+				event(app.EventTypeTaskFinished, "a", at(10, 0)),
+				event(app.EventTypeTaskStarted, "a", at(11, 0)),
+				event(app.EventTypeTaskFinished, "b", at(12, 0)),
+				event(app.EventTypeTaskFinished, "a", at(12, 30)),
+			},
+			period: wholeDay,
+			want: []app.Session{
+				{ID: id("a", at(9, 0)), TaskName: "a", Started: at(9, 0), Finished: at(10, 0)},
+				{ID: id("b", at(9, 30)), TaskName: "b", Started: at(9, 30), Finished: at(12, 0)},
+				{ID: id("a", at(11, 0)), TaskName: "a", Started: at(11, 0), Finished: at(12, 30)},
+			},
+		},
+		{
+			name: "task in progress finishes now",
+			events: []app.Event{
+				event(app.EventTypeTaskStarted, "a", at(17, 0)),
+			},
+			period: wholeDay,
+			want: []app.Session{
+				{ID: id("a", at(17, 0)), TaskName: "a", Started: at(17, 0), Finished: now, InProgress: true},
+			},
+		},
+		{
+			name: "repeated start continues the session, and a finish without a start is ignored",
+			events: []app.Event{
+				event(app.EventTypeTaskFinished, "a", at(8, 0)),
+				event(app.EventTypeTaskStarted, "a", at(9, 0)),
+				event(app.EventTypeTaskStarted, "a", at(9, 15)),
+				event(app.EventTypeTaskFinished, "a", at(10, 0)),
+			},
+			period: wholeDay,
+			want: []app.Session{
+				{ID: id("a", at(9, 0)), TaskName: "a", Started: at(9, 0), Finished: at(10, 0)},
+			},
+		},
+		{
+			name: "only sessions overlapping the period",
+			events: []app.Event{
+				event(app.EventTypeTaskStarted, "before", at(7, 0)),
+				event(app.EventTypeTaskFinished, "before", at(8, 0)),
+				event(app.EventTypeTaskStarted, "overlapping", at(8, 30)),
+				event(app.EventTypeTaskFinished, "overlapping", at(9, 30)),
+				event(app.EventTypeTaskStarted, "after", at(10, 0)),
+				event(app.EventTypeTaskFinished, "after", at(11, 0)),
+			},
+			period: app.Period{From: at(9, 0), To: at(10, 0)},
+			want: []app.Session{
+				{ID: id("overlapping", at(8, 30)), TaskName: "overlapping", Started: at(8, 30), Finished: at(9, 30)},
+			},
+		},
+		{
+			name: "tags and ID come from the started event",
+			events: []app.Event{
+				{ID: id("a", at(9, 0)), Type: app.EventTypeTaskStarted, TaskName: "a", CreatedAt: at(9, 0), Tags: []string{"acme"}},
+				event(app.EventTypeTaskFinished, "a", at(10, 0)),
+			},
+			period: wholeDay,
+			want: []app.Session{
+				{ID: id("a", at(9, 0)), TaskName: "a", Tags: []string{"acme"}, Started: at(9, 0), Finished: at(10, 0)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store := eventstore.NewMemoryEventStore()
+			for _, e := range tt.events {
+				assert.NoError(t, store.Store(ctx, e))
+			}
+
+			sut := SessionCollector{eventLister: store, now: func() time.Time { return now }}
+			got, err := sut.Sessions(ctx, tt.period)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeOverlapping(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2022, 6, 1, hour, minute, 0, 0, time.UTC)
+	}
+	session := func(taskName string, started, finished time.Time) app.Session {
+		return app.Session{ID: uuid.New(), TaskName: taskName, Started: started, Finished: finished}
+	}
+	tests := []struct {
+		name     string
+		sessions []app.Session
+		want     []app.Session
+	}{
+		{
+			name: "no sessions",
+			want: nil,
+		},
+		{
+			name: "separate sessions are kept",
+			sessions: []app.Session{
+				session("b", at(11, 0), at(12, 0)),
+				session("a", at(9, 0), at(10, 0)),
+			},
+			want: []app.Session{
+				{Started: at(9, 0), Finished: at(10, 0)},
+				{Started: at(11, 0), Finished: at(12, 0)},
+			},
+		},
+		{
+			name: "overlapping and adjoining sessions are merged",
+			sessions: []app.Session{
+				session("a", at(9, 0), at(11, 0)),
+				session("b", at(10, 0), at(12, 0)),
+				session("c", at(10, 30), at(11, 30)),
+				session("d", at(12, 0), at(13, 0)),
+				session("e", at(14, 0), at(15, 0)),
+			},
+			want: []app.Session{
+				{Started: at(9, 0), Finished: at(13, 0)},
+				{Started: at(14, 0), Finished: at(15, 0)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, mergeOverlapping(tt.sessions))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/danmurf/time-tracker/internal/app"
 	"github.com/google/uuid"
+	"strings"
 	"time"
 )
 
@@ -22,7 +23,13 @@ func NewStarter(eventStore app.EventStore, eventFinder app.EventFinder) Starter
 	return Starter{eventStore: eventStore, eventFinder: eventFinder, now: time.Now, newUUID: uuid.New}
 }
 
-func (s Starter) Start(ctx context.Context, taskName string) error {
+// WithClock returns a copy which records events at the time returned by now, e.g. to backdate them.
+func (s Starter) WithClock(now func() time.Time) Starter {
+	s.now = now
+	return s
+}
+
+func (s Starter) Start(ctx context.Context, taskName string, tags ...string) error {
 	latest, err := s.eventFinder.LatestByName(ctx, taskName)
 	switch {
 	case err != nil && !errors.Is(err, app.ErrEventNotFound):
@@ -31,14 +38,35 @@ func (s Starter) Start(ctx context.Context, taskName string) error {
 		return fmt.Errorf("task started event found: %w", app.ErrTaskAlreadyStarted)
 	}
 
+	now := s.now()
+	if err == nil && now.Before(latest.CreatedAt) {
+		return fmt.Errorf("task would start before its latest event at %s: %w", latest.CreatedAt, app.ErrEventOutOfOrder)
+	}
+
 	if err := s.eventStore.Store(ctx, app.Event{
 		ID:        s.newUUID(),
 		Type:      app.EventTypeTaskStarted,
 		TaskName:  taskName,
-		CreatedAt: s.now(),
+		CreatedAt: now,
+		Tags:      normaliseTags(tags),
 	}); err != nil {
 		return fmt.Errorf("storing event: %w", err)
 	}
 
 	return nil
 }
+
+// normaliseTags trims the tags, dropping any which are blank or repeated.
+func normaliseTags(tags []string) []string {
+	var normalised []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalised = append(normalised, tag)
+	}
+	return normalised
+}
@@ -30,6 +30,7 @@ func TestStarter_Start(t *testing.T) {
 	type args struct {
 		ctx      context.Context
 		taskName string
+		tags     []string
 	}
 	tests := []struct {
 		name    string
@@ -68,6 +69,39 @@ func TestStarter_Start(t *testing.T) {
 			},
 			wantErr: assert.NoError,
 		},
+		{
+			name: "successfully starts task with tags, ignoring blank and repeated tags",
+			fields: fields{
+				eventFinder: func() *app_mocks.EventFinder {
+					m := &app_mocks.EventFinder{}
+					m.
+						On("LatestByName", mock.Anything, "test").
+						Once().
+						Return(app.Event{}, app.ErrEventNotFound)
+					return m
+				}(),
+				eventStore: func() *app_mocks.EventStore {
+					m := &app_mocks.EventStore{}
+					m.
+						On("Store", mock.Anything, app.Event{
+							ID:        id,
+							Type:      app.EventTypeTaskStarted,
+							TaskName:  "test",
+							CreatedAt: now,
+							Tags:      []string{"acme", "billable"},
+						}).
+						Once().
+						Return(nil)
+					return m
+				}(),
+			},
+			args: args{
+				ctx:      context.Background(),
+				taskName: "test",
+				tags:     []string{" acme", "", "billable", "acme"},
+			},
+			wantErr: assert.NoError,
+		},
 		{
 			name: "successfully starts task which was previously finished",
 			fields: fields{
@@ -178,6 +212,35 @@ func TestStarter_Start(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "it stops you starting a task before it was last finished",
+			fields: fields{
+				eventStore: &app_mocks.EventStore{},
+				eventFinder: func() *app_mocks.EventFinder {
+					m := &app_mocks.EventFinder{}
+					m.
+						On("LatestByName", mock.Anything, "test").
+						Once().
+						Return(app.Event{
+							ID:        uuid.UUID{},
+							Type:      app.EventTypeTaskFinished,
+							TaskName:  "test",
+							CreatedAt: now.Add(1 * time.Minute),
+						}, nil)
+					return m
+				}(),
+			},
+			args: args{
+				ctx:      context.Background(),
+				taskName: "test",
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.True(t,
+					errors.Is(err, app.ErrEventOutOfOrder),
+					fmt.Sprintf("want err [%s]; got [%s]", app.ErrEventOutOfOrder, err),
+				)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -187,7 +250,7 @@ func TestStarter_Start(t *testing.T) {
 				now:         nowFunc,
 				newUUID:     uuidFunc,
 			}
-			tt.wantErr(t, sut.Start(tt.args.ctx, tt.args.taskName))
+			tt.wantErr(t, sut.Start(tt.args.ctx, tt.args.taskName, tt.args.tags...))
 			tt.fields.eventStore.AssertExpectations(t)
 			tt.fields.eventFinder.AssertExpectations(t)
 		})
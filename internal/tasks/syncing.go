@@ -0,0 +1,100 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/google/uuid"
+	"sort"
+)
+
+var _ app.EventSyncer = (*Syncer)(nil)
+
+type Syncer struct{}
+
+func NewSyncer() Syncer {
+	return Syncer{}
+}
+
+func (s Syncer) Sync(ctx context.Context, local, remote app.SyncableEventStore) (result app.SyncResult, err error) {
+	localEvents, err := local.FetchAll(ctx)
+	if err != nil {
+		return result, fmt.Errorf("fetching local events: %w", err)
+	}
+	remoteEvents, err := remote.FetchAll(ctx)
+	if err != nil {
+		return result, fmt.Errorf("fetching remote events: %w", err)
+	}
+
+	toLocal := missingEvents(remoteEvents, localEvents)
+	toRemote := missingEvents(localEvents, remoteEvents)
+
+	for _, e := range toLocal {
+		if err = local.Store(ctx, e); err != nil {
+			return result, fmt.Errorf("copying event [%s] to local: %w", e.ID, err)
+		}
+		result.CopiedToLocal++
+	}
+	for _, e := range toRemote {
+		if err = remote.Store(ctx, e); err != nil {
+			return result, fmt.Errorf("copying event [%s] to remote: %w", e.ID, err)
+		}
+		result.CopiedToRemote++
+	}
+
+	result.Conflicts = conflicts(toLocal, toRemote)
+	result.DuplicateInvoiceNumbers = duplicateInvoiceNumbers(localEvents, toLocal)
+
+	return result, nil
+}
+
+// missingEvents returns the events in from which are not in to, oldest first. Each event is only returned once.
+func missingEvents(from, to []app.Event) []app.Event {
+	existing := make(map[uuid.UUID]bool, len(to))
+	for _, e := range to {
+		existing[e.ID] = true
+	}
+
+	var missing []app.Event
+	for _, e := range from {
+		if !existing[e.ID] {
+			existing[e.ID] = true
+			missing = append(missing, e)
+		}
+	}
+	sort.SliceStable(missing, func(i, j int) bool {
+		return missing[i].CreatedAt.Before(missing[j].CreatedAt)
+	})
+
+	return missing
+}
+
+// conflicts finds tasks which were started (or finished) twice in a row once the events only the remote had are merged
+// with the events only the local had. Events both sides already shared can't conflict as a result of the sync.
+func conflicts(remoteOnly, localOnly []app.Event) []app.SyncConflict {
+	remote := map[uuid.UUID]bool{}
+	var merged []app.Event
+	for _, e := range remoteOnly {
+		remote[e.ID] = true
+		merged = append(merged, e)
+	}
+	merged = append(merged, localOnly...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].CreatedAt.Before(merged[j].CreatedAt)
+	})
+
+	var found []app.SyncConflict
+	previous := map[string]app.Event{}
+	for _, e := range merged {
+		if e.Type != app.EventTypeTaskStarted && e.Type != app.EventTypeTaskFinished {
+			continue
+		}
+		prev, ok := previous[e.TaskName]
+		previous[e.TaskName] = e
+		if ok && prev.Type == e.Type && remote[prev.ID] != remote[e.ID] {
+			found = append(found, app.SyncConflict{TaskName: e.TaskName, First: prev, Second: e})
+		}
+	}
+
+	return found
+}
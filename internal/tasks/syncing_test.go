@@ -0,0 +1,101 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSyncer_Sync(t *testing.T) {
+	now := time.Now().Truncate(time.Second).UTC()
+	event := func(eventType app.EventType, taskName string, ago time.Duration) app.Event {
+		return app.Event{ID: uuid.New(), Type: eventType, TaskName: taskName, CreatedAt: now.Add(-ago)}
+	}
+	shared := event(app.EventTypeTaskStarted, "shared", 3*time.Hour)
+	localStart := event(app.EventTypeTaskStarted, "laptop-task", 2*time.Hour)
+	localFinish := event(app.EventTypeTaskFinished, "laptop-task", 1*time.Hour)
+	remoteStart := event(app.EventTypeTaskStarted, "desktop-task", 90*time.Minute)
+	conflictLocal := event(app.EventTypeTaskStarted, "both", 50*time.Minute)
+	conflictRemote := event(app.EventTypeTaskStarted, "both", 40*time.Minute)
+
+	type args struct {
+		local  []app.Event
+		remote []app.Event
+	}
+	tests := []struct {
+		name          string
+		args          args
+		want          app.SyncResult
+		wantMerged    []app.Event
+		wantConflicts []app.SyncConflict
+	}{
+		{
+			name: "both empty",
+			args: args{},
+			want: app.SyncResult{},
+		},
+		{
+			name: "events copied both ways",
+			args: args{
+				local:  []app.Event{shared, localStart, localFinish},
+				remote: []app.Event{shared, remoteStart},
+			},
+			want:       app.SyncResult{CopiedToLocal: 1, CopiedToRemote: 2},
+			wantMerged: []app.Event{shared, localStart, localFinish, remoteStart},
+		},
+		{
+			name: "already in sync",
+			args: args{
+				local:  []app.Event{shared, localStart},
+				remote: []app.Event{shared, localStart},
+			},
+			want:       app.SyncResult{},
+			wantMerged: []app.Event{shared, localStart},
+		},
+		{
+			name: "same task started on both machines",
+			args: args{
+				local:  []app.Event{shared, conflictLocal},
+				remote: []app.Event{shared, conflictRemote},
+			},
+			want: app.SyncResult{
+				CopiedToLocal:  1,
+				CopiedToRemote: 1,
+				Conflicts:      []app.SyncConflict{{TaskName: "both", First: conflictLocal, Second: conflictRemote}},
+			},
+			wantMerged: []app.Event{shared, conflictLocal, conflictRemote},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			local := eventstore.NewMemoryEventStore()
+			remote := eventstore.NewMemoryEventStore()
+			for _, e := range tt.args.local {
+				assert.NoError(t, local.Store(ctx, e), "preparing local events")
+			}
+			for _, e := range tt.args.remote {
+				assert.NoError(t, remote.Store(ctx, e), "preparing remote events")
+			}
+
+			got, err := NewSyncer().Sync(ctx, local, remote)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			localEvents, err := local.FetchAll(ctx)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.wantMerged, localEvents)
+			remoteEvents, err := remote.FetchAll(ctx)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, tt.wantMerged, remoteEvents)
+
+			again, err := NewSyncer().Sync(ctx, local, remote)
+			assert.NoError(t, err)
+			assert.Equal(t, app.SyncResult{}, again, "syncing again should copy nothing")
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"github.com/danmurf/time-tracker/internal/app"
+	"sort"
+	"time"
+)
+
+var _ app.TimesheetBuilder = (*Timesheets)(nil)
+
+type Timesheets struct {
+	sessionLister app.SessionLister
+	absenceLister app.AbsenceLister
+	rounding      app.RoundingRules
+}
+
+func NewTimesheets(sessionLister app.SessionLister, absenceLister app.AbsenceLister, rounding app.RoundingRules) Timesheets {
+	return Timesheets{sessionLister: sessionLister, absenceLister: absenceLister, rounding: rounding}
+}
+
+// Timesheet builds a timesheet of the completed sessions in the period, with a column for each day, before and after
+// rounding, and any absence on each day. Days are in the location of the period.
+func (t Timesheets) Timesheet(ctx context.Context, period app.Period) (app.Timesheet, error) {
+	sessions, err := t.sessionLister.Sessions(ctx, period)
+	if err != nil {
+		return app.Timesheet{}, fmt.Errorf("listing sessions: %w", err)
+	}
+	absences, err := t.absenceLister.Absences(ctx)
+	if err != nil {
+		return app.Timesheet{}, fmt.Errorf("listing absences: %w", err)
+	}
+
+	timesheet := app.Timesheet{Period: period}
+	for day := app.DayPeriod(period.From, 0); day.From.Before(period.To); day = app.DayPeriod(day.To, 0) {
+		timesheet.Days = append(timesheet.Days, day)
+		absence, _ := absences.On(day.From)
+		timesheet.Absences = append(timesheet.Absences, absence)
+	}
+	timesheet.DayTotals = make([]time.Duration, len(timesheet.Days))
+	timesheet.RoundedDayTotals = make([]time.Duration, len(timesheet.Days))
+
+	rows := map[string]*app.TimesheetRow{}
+	rounder := newRounder(t.rounding)
+	for _, s := range sessions {
+		if s.InProgress {
+			continue
+		}
+		for i, day := range timesheet.Days {
+			within, ok := s.Within(day)
+			if !ok {
+				continue
+			}
+			row, ok := rows[s.TaskName]
+			if !ok {
+				row = &app.TimesheetRow{
+					TaskName:    s.TaskName,
+					Days:        make([]time.Duration, len(timesheet.Days)),
+					RoundedDays: make([]time.Duration, len(timesheet.Days)),
+				}
+				rows[s.TaskName] = row
+			}
+			row.Days[i] += within.Duration()
+			row.Total += within.Duration()
+			timesheet.DayTotals[i] += within.Duration()
+			timesheet.Total += within.Duration()
+			rounder.add(day.From, within)
+		}
+	}
+
+	dayIndex := map[time.Time]int{}
+	for i, day := range timesheet.Days {
+		dayIndex[day.From] = i
+	}
+	for key, rounded := range rounder.totals() {
+		row, i := rows[key.taskName], dayIndex[key.date]
+		row.RoundedDays[i] += rounded
+		row.RoundedTotal += rounded
+		timesheet.RoundedDayTotals[i] += rounded
+		timesheet.RoundedTotal += rounded
+	}
+
+	for _, row := range rows {
+		timesheet.Rows = append(timesheet.Rows, *row)
+	}
+	sort.Slice(timesheet.Rows, func(i, j int) bool {
+		return timesheet.Rows[i].TaskName < timesheet.Rows[j].TaskName
+	})
+
+	return timesheet, nil
+}
@@ -0,0 +1,79 @@
+package tasks
+
+import (
+	"context"
+	"github.com/danmurf/time-tracker/internal/app"
+	"github.com/danmurf/time-tracker/internal/pkg/eventstore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTimesheets_Timesheet(t *testing.T) {
+	ctx := context.Background()
+	monday := time.Date(2022, 6, 6, 0, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)
+	}
+
+	store := eventstore.NewMemoryEventStore()
+	for _, e := range []struct {
+		eventType app.EventType
+		taskName  string
+		at        time.Time
+	}{
+		{app.EventTypeTaskStarted, "a", at(0, 9)},
+		{app.EventTypeTaskFinished, "a", at(0, 12)},
+		{app.EventTypeTaskStarted, "b", at(0, 13)},
+		{app.EventTypeTaskFinished, "b", at(0, 17)},
+		{app.EventTypeTaskStarted, "a", at(2, 22)},
+		{app.EventTypeTaskFinished, "a", at(3, 1)},
+		{app.EventTypeTaskStarted, "b", at(6, 23)},
+		{app.EventTypeTaskFinished, "b", at(7, 2)},
+		{app.EventTypeTaskStarted, "running", at(4, 9)},
+	} {
+		assert.NoError(t, store.Store(ctx, app.Event{ID: uuid.New(), Type: e.eventType, TaskName: e.taskName, CreatedAt: e.at}))
+	}
+
+	absences := NewAbsences(store, store)
+	assert.NoError(t, absences.AddAbsences(ctx, app.Absence{Date: at(1, 0), Kind: app.Vacation}, app.Absence{Date: at(14, 0), Kind: app.Sick}))
+
+	week := app.WeekPeriod(monday, time.Monday, 0)
+	rounding := app.RoundingRules{{Task: "b", Mode: app.RoundUp, Increment: 2 * time.Hour, Per: app.PerDay}}
+	sut := NewTimesheets(SessionCollector{eventLister: store, now: func() time.Time { return at(4, 12) }}, absences, rounding)
+	got, err := sut.Timesheet(ctx, week)
+	assert.NoError(t, err)
+
+	h := time.Hour
+	if assert.Len(t, got.Days, 7) {
+		assert.Equal(t, app.DayPeriod(monday, 0), got.Days[0])
+		assert.Equal(t, app.DayPeriod(monday, 6), got.Days[6])
+	}
+	if assert.Len(t, got.Absences, 7) {
+		assert.Equal(t, app.Vacation, got.Absences[1].Kind)
+		for _, i := range []int{0, 2, 3, 4, 5, 6} {
+			assert.Empty(t, got.Absences[i].Kind)
+		}
+	}
+	assert.Equal(t, []app.TimesheetRow{
+		{
+			TaskName:     "a",
+			Days:         []time.Duration{3 * h, 0, 2 * h, 1 * h, 0, 0, 0},
+			Total:        6 * h,
+			RoundedDays:  []time.Duration{3 * h, 0, 2 * h, 1 * h, 0, 0, 0},
+			RoundedTotal: 6 * h,
+		},
+		{
+			TaskName:     "b",
+			Days:         []time.Duration{4 * h, 0, 0, 0, 0, 0, 1 * h},
+			Total:        5 * h,
+			RoundedDays:  []time.Duration{4 * h, 0, 0, 0, 0, 0, 2 * h},
+			RoundedTotal: 6 * h,
+		},
+	}, got.Rows)
+	assert.Equal(t, []time.Duration{7 * h, 0, 2 * h, 1 * h, 0, 0, 1 * h}, got.DayTotals)
+	assert.Equal(t, 11*h, got.Total)
+	assert.Equal(t, []time.Duration{7 * h, 0, 2 * h, 1 * h, 0, 0, 2 * h}, got.RoundedDayTotals)
+	assert.Equal(t, 12*h, got.RoundedTotal)
+}